@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"fmt"
+	"hash/maphash"
+)
+
+// AdmissionPolicy decides whether a new entry is worth evicting another one, when the cache is full.
+type AdmissionPolicy interface {
+	// Record indicates an entry has been accessed.
+	Record(key interface{})
+
+	// Admit records an access to the candidate, and returns whether it should replace the victim.
+	Admit(candidate, victim interface{}) bool
+
+	fmt.Stringer
+}
+
+// WithAdmission wraps an eviction strategy factory, so the evicting cache only stores new entries admitted
+// by the policy. Rejected entries are silently dropped, and the victim is kept. The policy is shared by the
+// strategy instances, e.g. after Clear.
+//
+//	c := NewMemoryStorage(Eviction(1000, WithAdmission(NewTinyLFU(1000), NewLRUEviction)))
+func WithAdmission(policy AdmissionPolicy, f EvictionFactory) EvictionFactory {
+	return func() EvictionStrategy {
+		return &admittingEviction{f(), policy}
+	}
+}
+
+// admitter is implemented by the strategies that filter the new entries.
+type admitter interface {
+	admit(candidate, victim interface{}) bool
+}
+
+type admittingEviction struct {
+	EvictionStrategy
+	policy AdmissionPolicy
+}
+
+func (e *admittingEviction) Hit(key interface{}) {
+	e.policy.Record(key)
+	e.EvictionStrategy.Hit(key)
+}
+
+func (e *admittingEviction) admit(candidate, victim interface{}) bool {
+	return e.policy.Admit(candidate, victim)
+}
+
+func (e *admittingEviction) String() string {
+	return fmt.Sprintf("Admission(%s,%s)", e.EvictionStrategy, e.policy)
+}
+
+// TinyLFU admission policy
+
+const (
+	sketchDepth    = 4
+	sketchMaxCount = 15
+)
+
+type tinyLFU struct {
+	rows      [sketchDepth][]uint8
+	seeds     [sketchDepth]maphash.Seed
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+// NewTinyLFU creates a TinyLFU admission policy for a cache of the given capacity. It estimates the access
+// frequencies using a count-min sketch, which is halved periodically so the estimations favor recent accesses.
+// A candidate is admitted if it is accessed more frequently than the victim.
+func NewTinyLFU(capacity int) AdmissionPolicy {
+	width := 16
+	for width < capacity {
+		width <<= 1
+	}
+	p := &tinyLFU{mask: uint64(width - 1), resetAt: 10 * capacity}
+	for i := range p.rows {
+		p.rows[i] = make([]uint8, width)
+		p.seeds[i] = maphash.MakeSeed()
+	}
+	return p
+}
+
+func (p *tinyLFU) index(i int, key interface{}) uint64 {
+	return maphash.Comparable(p.seeds[i], key) & p.mask
+}
+
+func (p *tinyLFU) Record(key interface{}) {
+	for i := range p.rows {
+		if j := p.index(i, key); p.rows[i][j] < sketchMaxCount {
+			p.rows[i][j]++
+		}
+	}
+	if p.additions++; p.additions >= p.resetAt {
+		p.reset()
+	}
+}
+
+func (p *tinyLFU) estimate(key interface{}) (min uint8) {
+	min = sketchMaxCount
+	for i := range p.rows {
+		if c := p.rows[i][p.index(i, key)]; c < min {
+			min = c
+		}
+	}
+	return
+}
+
+func (p *tinyLFU) reset() {
+	for i := range p.rows {
+		for j := range p.rows[i] {
+			p.rows[i][j] >>= 1
+		}
+	}
+	p.additions = 0
+}
+
+func (p *tinyLFU) Admit(candidate, victim interface{}) bool {
+	p.Record(candidate)
+	return p.estimate(candidate) > p.estimate(victim)
+}
+
+func (p *tinyLFU) String() string {
+	return fmt.Sprintf("TinyLFU(%d)", len(p.rows[0]))
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestTinyLFU(t *testing.T) {
+
+	p := NewTinyLFU(10)
+
+	for i := 0; i < 5; i++ {
+		p.Record(1)
+	}
+
+	if p.Admit(2, 1) {
+		t.Error("Admit: expected 2 to be rejected")
+	}
+
+	for i := 0; i < 5; i++ {
+		p.Record(2)
+	}
+
+	if !p.Admit(2, 1) {
+		t.Error("Admit: expected 2 to be admitted")
+	}
+}
+
+func TestAdmission(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(t.Logf), Eviction(2, WithAdmission(NewTinyLFU(2), NewLRUEviction)))
+
+	c.Put(1, 1)
+	c.Put(2, 2)
+	for i := 0; i < 3; i++ {
+		c.Get(1)
+		c.Get(2)
+	}
+
+	c.Put(3, 3)
+
+	if _, err := c.Get(3); err != ErrKeyNotFound {
+		t.Error("Get: expected 3 to be rejected")
+	}
+
+	c.Put(2, 20)
+
+	if v, err := c.Get(2); v != 20 || err != nil {
+		t.Error("Get: expected the update of 2 to be admitted")
+	}
+
+	for i := 0; i < 10; i++ {
+		c.Put(3, 3)
+	}
+
+	if v, err := c.Get(3); v != 3 || err != nil {
+		t.Error("Get: expected 3 to be admitted eventually")
+	}
+
+	if v := c.Len(); v != 2 {
+		t.Errorf("Len: expected 2, got %d", v)
+	}
+}
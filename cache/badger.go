@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// GCDiscardRatio is the discard ratio of the value log garbage collection run by the Flush method of Badger storages.
+var GCDiscardRatio = 0.5
+
+// NewBadgerStorage creates a cache storing its entries in a Badger database. The database should not be used
+// for anything else, as Clear drops all its data.
+//
+// Keys and values are gob-encoded: their concrete types must be registered using gob.Register,
+// unless they are basic types. The storage natively supports per-entry TTLs, with a resolution of one second,
+// see PutWithTTL, and implements Iterable and Clearer. Flush syncs the database and collects the garbage
+// of the value log.
+func NewBadgerStorage(db *badger.DB, opts ...Option) Cache {
+	return options(opts).applyTo(&badgerStorage{db})
+}
+
+type badgerStorage struct {
+	db *badger.DB
+}
+
+func (s *badgerStorage) Put(key, value interface{}) error {
+	return s.put(key, value, 0)
+}
+
+func (s *badgerStorage) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	if ttl < time.Second {
+		// Badger TTLs are rounded down to the second.
+		ttl = time.Second
+	}
+	return s.put(key, value, ttl)
+}
+
+func (s *badgerStorage) put(key, value interface{}, ttl time.Duration) error {
+	k, err := gobEncode(key)
+	if err != nil {
+		return err
+	}
+	v, err := gobEncode(value)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		e := badger.NewEntry(k, v)
+		if ttl > 0 {
+			e = e.WithTTL(ttl)
+		}
+		return txn.SetEntry(e)
+	})
+}
+
+func (s *badgerStorage) Get(key interface{}) (value interface{}, err error) {
+	value, _, err = s.GetWithExpiry(key)
+	return
+}
+
+func (s *badgerStorage) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	k, err := gobEncode(key)
+	if err != nil {
+		return
+	}
+	err = s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(k)
+		if err != nil {
+			return err
+		}
+		if exp := item.ExpiresAt(); exp > 0 {
+			expiresAt = time.Unix(int64(exp), 0)
+		}
+		return item.Value(func(data []byte) (err error) {
+			value, err = gobDecode(data)
+			return
+		})
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		err = ErrKeyNotFound
+	}
+	return
+}
+
+func (s *badgerStorage) TTL(key interface{}) (time.Duration, error) {
+	_, expiresAt, err := s.GetWithExpiry(key)
+	if err != nil || expiresAt.IsZero() {
+		return 0, err
+	}
+	return time.Until(expiresAt), nil
+}
+
+func (s *badgerStorage) Remove(key interface{}) (removed bool) {
+	k, err := gobEncode(key)
+	if err != nil {
+		return
+	}
+	s.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get(k); err != nil {
+			return err
+		}
+		if err := txn.Delete(k); err != nil {
+			return err
+		}
+		removed = true
+		return nil
+	})
+	return
+}
+
+func (s *badgerStorage) Flush() error {
+	if s.db.Opts().InMemory {
+		return nil
+	}
+	if err := s.db.Sync(); err != nil {
+		return err
+	}
+	for {
+		err := s.db.RunValueLogGC(GCDiscardRatio)
+		switch {
+		case err == nil:
+		case errors.Is(err, badger.ErrNoRewrite):
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+func (s *badgerStorage) Len() (n int) {
+	s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			n++
+		}
+		return nil
+	})
+	return
+}
+
+func (s *badgerStorage) Clear() error {
+	return s.db.DropAll()
+}
+
+func (s *badgerStorage) Range(f func(key, value interface{}) bool) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			key, err := gobDecode(item.Key())
+			if err != nil {
+				return err
+			}
+			var value interface{}
+			if err := item.Value(func(data []byte) (err error) {
+				value, err = gobDecode(data)
+				return
+			}); err != nil {
+				return err
+			}
+			if !f(key, value) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (s *badgerStorage) String() string {
+	return fmt.Sprintf("Badger(%p)", s.db)
+}
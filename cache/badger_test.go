@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+func openTestBadger(t *testing.T) *badger.DB {
+	db, err := badger.Open(badger.DefaultOptions(t.TempDir()).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestBadgerStorage(t *testing.T) {
+
+	c := NewBadgerStorage(openTestBadger(t), Spy(t.Logf))
+
+	if c.Put(5, "six") != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if v, err := c.Get(5); v != "six" || err != nil {
+		t.Errorf("Get: expected six, <nil>, got %v, %v", v, err)
+	}
+
+	if v := c.Len(); v != 1 {
+		t.Errorf("Len: expected 1, got %d", v)
+	}
+
+	if keys, err := Keys(c); len(keys) != 1 || keys[0] != 5 || err != nil {
+		t.Errorf("Keys: expected [5], <nil>, got %v, %v", keys, err)
+	}
+
+	if err := c.Flush(); err != nil {
+		t.Errorf("Flush: unexpected error %v", err)
+	}
+
+	if !c.Remove(5) {
+		t.Error("Remove: expected true")
+	}
+
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	if c.Remove(5) {
+		t.Error("Remove: expected false")
+	}
+
+	c.Put("a", 1)
+	if err := Clear(c); err != nil || c.Len() != 0 {
+		t.Errorf("Clear: expected an empty cache, got %v", err)
+	}
+}
+
+func TestBadgerStorageTTL(t *testing.T) {
+
+	c := NewBadgerStorage(openTestBadger(t), Spy(t.Logf))
+
+	PutWithTTL(c, 5, 6, time.Hour)
+
+	if ttl, err := TTL(c, 5); ttl <= 59*time.Minute || ttl > time.Hour || err != nil {
+		t.Errorf("TTL: expected about 1h, <nil>, got %s, %v", ttl, err)
+	}
+
+	PutWithTTL(c, 6, 7, time.Second)
+	time.Sleep(2 * time.Second)
+
+	if v, err := c.Get(6); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+}
@@ -0,0 +1,380 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrReadOnly is returned by the write operations of read-only storages.
+var ErrReadOnly = errors.New("read-only cache")
+
+// BoltOptions configures a storage using a bbolt database.
+type BoltOptions struct {
+	// Batch uses DB.Batch for Put and Remove, which coalesces concurrent writes into fewer transactions.
+	// Note that a batched write may be retried if another write of the batch fails.
+	Batch bool
+
+	// FillPercent is the fill percent of the bucket pages. It defaults to bolt.DefaultFillPercent.
+	// Higher values use less space when the keys are mostly appended in order.
+	FillPercent float64
+
+	// ReadOnly forbids Put, Remove and Clear. The bucket must exist.
+	ReadOnly bool
+
+	// BufferSize enables write buffering: Put and Remove are kept in memory and committed in a single transaction
+	// once BufferSize distinct keys have been written.
+	BufferSize int
+
+	// BufferDelay enables write buffering: the buffered writes are committed at most BufferDelay after the first one.
+	// Errors of these commits are returned by the next Flush.
+	BufferDelay time.Duration
+}
+
+// NewBoltStorage creates a cache storing its entries in a bucket of a bbolt database, creating the bucket if needed.
+//
+// Keys and values are gob-encoded: their concrete types must be registered using gob.Register,
+// unless they are basic types. The storage implements Iterable, Clearer and Partitioner.
+// Flush commits the buffered writes, if any, and syncs the database.
+func NewBoltStorage(db *bolt.DB, bucket string, bopts BoltOptions, opts ...Option) (Cache, error) {
+	if bopts.FillPercent == 0 {
+		bopts.FillPercent = bolt.DefaultFillPercent
+	}
+	s := &boltStorage{db: db, path: [][]byte{[]byte(bucket)}, BoltOptions: bopts}
+	var err error
+	if bopts.ReadOnly {
+		err = db.View(func(tx *bolt.Tx) error {
+			if tx.Bucket(s.path[0]) == nil {
+				return bolt.ErrBucketNotFound
+			}
+			return nil
+		})
+	} else {
+		err = db.Update(func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(s.path[0])
+			return err
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open bucket %q: %w", bucket, err)
+	}
+	return options(opts).applyTo(s), nil
+}
+
+type boltStorage struct {
+	BoltOptions
+	db *bolt.DB
+	// path holds the names of the bucket and of its parents, for partitions.
+	path [][]byte
+
+	// buffer holds the buffered writes by encoded keys; nil values are deletions.
+	buffer map[string][]byte
+	timer  *time.Timer
+	err    error
+	mu     sync.Mutex
+}
+
+// bucket returns the bucket of the storage, or nil if it does not exist.
+// If create is true, the bucket and its parents are created as needed.
+func (s *boltStorage) bucket(tx *bolt.Tx, create bool) (*bolt.Bucket, error) {
+	return bucketAt(tx, s.path, create)
+}
+
+func bucketAt(tx *bolt.Tx, path [][]byte, create bool) (b *bolt.Bucket, err error) {
+	for i, name := range path {
+		switch {
+		case i == 0:
+			b = tx.Bucket(name)
+		case create:
+			b, err = b.CreateBucketIfNotExists(name)
+		default:
+			b = b.Bucket(name)
+		}
+		if b == nil || err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *boltStorage) buffered() bool {
+	return !s.ReadOnly && (s.BufferSize > 0 || s.BufferDelay > 0)
+}
+
+func (s *boltStorage) bufferWrite(k, v []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.buffer == nil {
+		s.buffer = make(map[string][]byte)
+	}
+	s.buffer[string(k)] = v
+	if s.BufferSize > 0 && len(s.buffer) >= s.BufferSize {
+		return s.commit()
+	}
+	if s.timer == nil && s.BufferDelay > 0 {
+		s.timer = time.AfterFunc(s.BufferDelay, s.commitLater)
+	}
+	return nil
+}
+
+// lookup returns the buffered write of an encoded key. It must be called with the lock held.
+func (s *boltStorage) lookup(k []byte) (v []byte, found bool) {
+	v, found = s.buffer[string(k)]
+	return
+}
+
+// commit writes the buffered writes in a single transaction. It must be called with the lock held.
+func (s *boltStorage) commit() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if len(s.buffer) == 0 {
+		return nil
+	}
+	err := s.update(func(b *bolt.Bucket) error {
+		for k, v := range s.buffer {
+			var err error
+			if v == nil {
+				err = b.Delete([]byte(k))
+			} else {
+				err = b.Put([]byte(k), v)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		// The buffer is kept on failure, so the writes are retried by the next commit.
+		s.buffer = nil
+	}
+	return err
+}
+
+func (s *boltStorage) commitLater() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.commit(); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *boltStorage) commitNow() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commit()
+}
+
+func (s *boltStorage) update(f func(*bolt.Bucket) error) error {
+	if s.ReadOnly {
+		return ErrReadOnly
+	}
+	fn := func(tx *bolt.Tx) error {
+		b, err := s.bucket(tx, true)
+		if err != nil {
+			return err
+		}
+		b.FillPercent = s.FillPercent
+		return f(b)
+	}
+	if s.Batch {
+		return s.db.Batch(fn)
+	}
+	return s.db.Update(fn)
+}
+
+func (s *boltStorage) Put(key, value interface{}) error {
+	k, err := gobEncode(key)
+	if err != nil {
+		return err
+	}
+	v, err := gobEncode(value)
+	if err != nil {
+		return err
+	}
+	if s.buffered() {
+		return s.bufferWrite(k, v)
+	}
+	return s.update(func(b *bolt.Bucket) error {
+		return b.Put(k, v)
+	})
+}
+
+func (s *boltStorage) Get(key interface{}) (value interface{}, err error) {
+	k, err := gobEncode(key)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	data, found := s.lookup(k)
+	s.mu.Unlock()
+	if found {
+		if data == nil {
+			return nil, ErrKeyNotFound
+		}
+		return gobDecode(data)
+	}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b, _ := s.bucket(tx, false)
+		if b == nil {
+			return ErrKeyNotFound
+		}
+		data := b.Get(k)
+		if data == nil {
+			return ErrKeyNotFound
+		}
+		value, err = gobDecode(data)
+		return err
+	})
+	return
+}
+
+func (s *boltStorage) Remove(key interface{}) (removed bool) {
+	k, err := gobEncode(key)
+	if err != nil {
+		return
+	}
+	if s.buffered() {
+		s.mu.Lock()
+		data, found := s.lookup(k)
+		s.mu.Unlock()
+		if found {
+			removed = data != nil
+		} else {
+			s.db.View(func(tx *bolt.Tx) error {
+				b, _ := s.bucket(tx, false)
+				removed = b != nil && b.Get(k) != nil
+				return nil
+			})
+		}
+		s.bufferWrite(k, nil)
+		return
+	}
+	s.update(func(b *bolt.Bucket) error {
+		removed = b.Get(k) != nil
+		return b.Delete(k)
+	})
+	return
+}
+
+func (s *boltStorage) Flush() error {
+	if s.ReadOnly {
+		return nil
+	}
+	s.mu.Lock()
+	err := s.commit()
+	if err == nil {
+		err = s.err
+	}
+	s.err = nil
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.db.Sync()
+}
+
+func (s *boltStorage) Len() (n int) {
+	s.commitNow()
+	s.db.View(func(tx *bolt.Tx) error {
+		b, _ := s.bucket(tx, false)
+		if b == nil {
+			return nil
+		}
+		// Stats would count the entries of the partitions too.
+		return b.ForEach(func(_, v []byte) error {
+			if v != nil {
+				n++
+			}
+			return nil
+		})
+	})
+	return
+}
+
+func (s *boltStorage) Clear() error {
+	if s.ReadOnly {
+		return ErrReadOnly
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.buffer = nil
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if len(s.path) == 1 {
+			if err := tx.DeleteBucket(s.path[0]); err != nil {
+				return err
+			}
+			_, err := tx.CreateBucket(s.path[0])
+			return err
+		}
+		// The bucket of a partition is created again on the next write.
+		parent, _ := bucketAt(tx, s.path[:len(s.path)-1], false)
+		if parent == nil {
+			return nil
+		}
+		if err := parent.DeleteBucket(s.path[len(s.path)-1]); !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (s *boltStorage) Range(f func(key, value interface{}) bool) error {
+	if err := s.commitNow(); err != nil {
+		return err
+	}
+	// Copy the entries first, so f can use the storage.
+	var keys, values [][]byte
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		b, _ := s.bucket(tx, false)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil {
+				// Skip the buckets of the partitions.
+				return nil
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			values = append(values, append([]byte(nil), v...))
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	for i, k := range keys {
+		key, err := gobDecode(k)
+		if err != nil {
+			return err
+		}
+		value, err := gobDecode(values[i])
+		if err != nil {
+			return err
+		}
+		if !f(key, value) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *boltStorage) String() string {
+	return fmt.Sprintf("Bolt(%s,%s)", s.db.Path(), bytes.Join(s.path, []byte("/")))
+}
+
+// Partition returns a storage using a nested bucket, which is created on the first write.
+// Clearing the storage also clears its partitions.
+func (s *boltStorage) Partition(name string) Cache {
+	path := append(append([][]byte(nil), s.path...), []byte(name))
+	return &boltStorage{db: s.db, path: path, BoltOptions: s.BoltOptions}
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func openTestBolt(t *testing.T) *bolt.DB {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "cache.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestBoltStorage(t *testing.T) {
+
+	c, err := NewBoltStorage(openTestBolt(t), "cache", BoltOptions{}, Spy(t.Logf))
+	if err != nil {
+		t.Fatalf("NewBoltStorage: unexpected error %v", err)
+	}
+
+	if c.Put(5, "six") != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if v, err := c.Get(5); v != "six" || err != nil {
+		t.Errorf("Get: expected six, <nil>, got %v, %v", v, err)
+	}
+
+	if v := c.Len(); v != 1 {
+		t.Errorf("Len: expected 1, got %d", v)
+	}
+
+	if keys, err := Keys(c); len(keys) != 1 || keys[0] != 5 || err != nil {
+		t.Errorf("Keys: expected [5], <nil>, got %v, %v", keys, err)
+	}
+
+	if err := c.Flush(); err != nil {
+		t.Errorf("Flush: unexpected error %v", err)
+	}
+
+	if !c.Remove(5) {
+		t.Error("Remove: expected true")
+	}
+
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	if c.Remove(5) {
+		t.Error("Remove: expected false")
+	}
+
+	c.Put("a", 1)
+	if err := Clear(c); err != nil || c.Len() != 0 {
+		t.Errorf("Clear: expected an empty cache, got %v", err)
+	}
+}
+
+func TestBoltStorageBatch(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c, err := NewBoltStorage(openTestBolt(t), "cache", BoltOptions{Batch: true, FillPercent: 0.9}, ExpirationUsingClock(time.Second, &cl))
+	if err != nil {
+		t.Fatalf("NewBoltStorage: unexpected error %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := c.Put(i, i); err != nil {
+				t.Errorf("Put: unexpected error %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if v := c.Len(); v != 20 {
+		t.Errorf("Len: expected 20, got %d", v)
+	}
+
+	if v, err := c.Get(7); v != 7 || err != nil {
+		t.Errorf("Get: expected 7, <nil>, got %v, %v", v, err)
+	}
+}
+
+func TestBoltStorageBuffer(t *testing.T) {
+
+	db := openTestBolt(t)
+	stored := func() (n int) {
+		db.View(func(tx *bolt.Tx) error {
+			n = tx.Bucket([]byte("cache")).Stats().KeyN
+			return nil
+		})
+		return
+	}
+
+	c, err := NewBoltStorage(db, "cache", BoltOptions{BufferSize: 3}, Spy(t.Logf))
+	if err != nil {
+		t.Fatalf("NewBoltStorage: unexpected error %v", err)
+	}
+
+	c.Put(1, 1)
+	c.Put(2, 2)
+	if v := stored(); v != 0 {
+		t.Errorf("expected 0 stored entries, got %d", v)
+	}
+	if v, err := c.Get(1); v != 1 || err != nil {
+		t.Errorf("Get: expected 1, <nil>, got %v, %v", v, err)
+	}
+
+	c.Put(3, 3)
+	if v := stored(); v != 3 {
+		t.Errorf("expected 3 stored entries, got %d", v)
+	}
+
+	if !c.Remove(1) {
+		t.Error("Remove: expected true")
+	}
+	if v, err := c.Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+	if err := c.Flush(); err != nil {
+		t.Errorf("Flush: unexpected error %v", err)
+	}
+	if v := stored(); v != 2 {
+		t.Errorf("expected 2 stored entries, got %d", v)
+	}
+}
+
+func TestBoltStorageBufferDelay(t *testing.T) {
+
+	db := openTestBolt(t)
+	c, err := NewBoltStorage(db, "cache", BoltOptions{BufferDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewBoltStorage: unexpected error %v", err)
+	}
+
+	c.Put(1, 1)
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		var found bool
+		db.View(func(tx *bolt.Tx) error {
+			found = tx.Bucket([]byte("cache")).Stats().KeyN == 1
+			return nil
+		})
+		if found {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the buffered write has not been committed")
+		}
+	}
+}
+
+func TestBoltStorageReadOnly(t *testing.T) {
+
+	db := openTestBolt(t)
+
+	if _, err := NewBoltStorage(db, "cache", BoltOptions{ReadOnly: true}); err == nil {
+		t.Error("NewBoltStorage: expected an error for a missing bucket")
+	}
+
+	NewBoltStorage(db, "cache", BoltOptions{})
+	c, err := NewBoltStorage(db, "cache", BoltOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("NewBoltStorage: unexpected error %v", err)
+	}
+
+	if err := c.Put(5, 6); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Put: expected %v, got %v", ErrReadOnly, err)
+	}
+}
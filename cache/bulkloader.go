@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BulkLoaderFunc loads several entries at once. The keys missing from the returned map are not found.
+type BulkLoaderFunc func(keys []interface{}) (map[interface{}]interface{}, error)
+
+// errBatchPanicked is returned to the callers waiting for a batch which panicked.
+var errBatchPanicked = errors.New("bulk loader panicked")
+
+// BulkLoader adds a layer to generate values on demand, like Loader, but which collects the misses during the given
+// window, then loads them using a single call to f, e.g. to avoid N+1 queries.
+//
+// A batch is loaded as soon as it has maxBatch keys, if maxBatch is positive. The loaded entries are put into the
+// underlying cache. An error of f is returned to all the callers of the batch, while an error of the underlying
+// cache is returned, along with the loaded value, to the callers of the key, like Loader does.
+func BulkLoader(f BulkLoaderFunc, window time.Duration, maxBatch int) Option {
+	return func(c Cache) Cache {
+		return &bulkLoader{Cache: c, f: f, window: window, maxBatch: maxBatch}
+	}
+}
+
+type bulkLoader struct {
+	Cache
+	f        BulkLoaderFunc
+	window   time.Duration
+	maxBatch int
+	// batch is collecting the misses, if any.
+	batch *loadBatch
+	mu    sync.Mutex
+}
+
+type loadBatch struct {
+	keys    []interface{}
+	pending map[interface{}]struct{}
+	timer   *time.Timer
+	taken   bool
+	// values, err and putErrs can be read once done is closed.
+	values map[interface{}]interface{}
+	err    error
+	// putErrs holds the errors of the underlying cache when storing the loaded entries.
+	putErrs map[interface{}]error
+	done    chan struct{}
+}
+
+func (l *bulkLoader) Get(key interface{}) (interface{}, error) {
+	value, err := l.Cache.Get(key)
+	if err != ErrKeyNotFound {
+		return value, err
+	}
+
+	l.mu.Lock()
+	b := l.batch
+	if b == nil {
+		b = &loadBatch{pending: make(map[interface{}]struct{}), done: make(chan struct{})}
+		b.timer = time.AfterFunc(l.window, func() {
+			l.mu.Lock()
+			taken := l.take(b)
+			l.mu.Unlock()
+			if taken {
+				l.load(b)
+			}
+		})
+		l.batch = b
+	}
+	if _, found := b.pending[key]; !found {
+		b.pending[key] = struct{}{}
+		b.keys = append(b.keys, key)
+	}
+	full := l.maxBatch > 0 && len(b.keys) >= l.maxBatch && l.take(b)
+	l.mu.Unlock()
+	if full {
+		l.load(b)
+	}
+
+	<-b.done
+	if b.err != nil {
+		return nil, b.err
+	}
+	if value, found := b.values[key]; found {
+		return value, b.putErrs[key]
+	}
+	return nil, ErrKeyNotFound
+}
+
+// take detaches the batch, and tells whether the caller must load it. It must be called with the lock held.
+func (l *bulkLoader) take(b *loadBatch) bool {
+	if b.taken {
+		return false
+	}
+	b.taken = true
+	b.timer.Stop()
+	if l.batch == b {
+		l.batch = nil
+	}
+	return true
+}
+
+// load calls the loader function for the keys of the batch, and stores the loaded entries. As it usually runs on
+// the goroutine of the timer, a panic of the loader function is recovered and returned to the callers.
+func (l *bulkLoader) load(b *loadBatch) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.values, b.err = nil, fmt.Errorf("cannot load %d keys in %s: %w: %v", len(b.keys), l, errBatchPanicked, r)
+		}
+		close(b.done)
+	}()
+	values, err := l.f(b.keys)
+	if err != nil {
+		b.err = fmt.Errorf("cannot load %d keys in %s: %w", len(b.keys), l, err)
+		return
+	}
+	for key, value := range values {
+		if err := l.Cache.Put(key, value); err != nil {
+			if b.putErrs == nil {
+				b.putErrs = make(map[interface{}]error)
+			}
+			b.putErrs[key] = err
+		}
+	}
+	b.values = values
+}
+
+func (l *bulkLoader) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	return PutWithTTL(l.Cache, key, value, ttl)
+}
+
+func (l *bulkLoader) PutWithCost(key, value interface{}, cost int64) error {
+	return PutWithCost(l.Cache, key, value, cost)
+}
+
+func (l *bulkLoader) Range(f func(key, value interface{}) bool) error {
+	return Range(l.Cache, f)
+}
+
+func (l *bulkLoader) Clear() error {
+	return Clear(l.Cache)
+}
+
+func (l *bulkLoader) notifyDrops(f dropFunc) {
+	notifyDrops(l.Cache, f)
+}
+
+func (l *bulkLoader) String() string {
+	return fmt.Sprintf("BulkLoader(%s,%v)", l.Cache, l.window)
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkLoader(t *testing.T) {
+
+	var (
+		mu      sync.Mutex
+		batches [][]interface{}
+		fail    bool
+	)
+	load := func(keys []interface{}) (map[interface{}]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, keys)
+		if fail {
+			return nil, errDown
+		}
+		values := make(map[interface{}]interface{})
+		for _, key := range keys {
+			if key.(int) > 0 {
+				values[key] = key.(int) * 10
+			}
+		}
+		return values, nil
+	}
+
+	getAll := func(c Cache, keys ...int) {
+		var wg sync.WaitGroup
+		for _, key := range keys {
+			wg.Add(1)
+			go func(key int) {
+				defer wg.Done()
+				v, err := c.Get(key)
+				switch {
+				case fail:
+					if !errors.Is(err, errDown) {
+						t.Errorf("Get(%d): expected %v, got %v", key, errDown, err)
+					}
+				case key > 0 && (v != key*10 || err != nil):
+					t.Errorf("Get(%d): expected %d, <nil>, got %v, %v", key, key*10, v, err)
+				case key <= 0 && (v != nil || err != ErrKeyNotFound):
+					t.Errorf("Get(%d): expected <nil>, %v, got %v, %v", key, ErrKeyNotFound, v, err)
+				}
+			}(key)
+		}
+		wg.Wait()
+	}
+
+	c := NewMemoryStorage(BulkLoader(load, 20*time.Millisecond, 0))
+	getAll(c, 1, 2, 3, 2, 0)
+	if len(batches) != 1 || len(batches[0]) != 4 {
+		t.Errorf("batches: expected one batch of 4 keys, got %v", batches)
+	}
+	getAll(c, 1, 2, 3)
+	if len(batches) != 1 {
+		t.Errorf("batches: expected no more batches, got %v", batches)
+	}
+
+	batches = nil
+	c = NewMemoryStorage(BulkLoader(load, time.Hour, 2))
+	getAll(c, 1, 2, 3, 4)
+	if len(batches) != 2 {
+		t.Errorf("batches: expected 2 batches, got %v", batches)
+	}
+
+	fail = true
+	c = NewMemoryStorage(BulkLoader(load, time.Millisecond, 0))
+	getAll(c, 1, 2)
+}
+
+// readOnlyCache is a cache whose Put always fails.
+type readOnlyCache struct{ Cache }
+
+func (readOnlyCache) Put(key, value interface{}) error { return ErrReadOnly }
+
+func TestBulkLoaderFailures(t *testing.T) {
+
+	c := BulkLoader(func(keys []interface{}) (map[interface{}]interface{}, error) {
+		panic("boom")
+	}, time.Millisecond, 0)(NewMemoryStorage())
+	if _, err := c.Get(1); !errors.Is(err, errBatchPanicked) {
+		t.Errorf("Get: expected %v, got %v", errBatchPanicked, err)
+	}
+
+	c = BulkLoader(func(keys []interface{}) (map[interface{}]interface{}, error) {
+		return map[interface{}]interface{}{1: 10}, nil
+	}, time.Millisecond, 0)(readOnlyCache{NewMemoryStorage()})
+	if v, err := c.Get(1); v != 10 || err != ErrReadOnly {
+		t.Errorf("Get: expected 10, %v, got %v, %v", ErrReadOnly, v, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // ErrKeyNotFound is returned by Cache.Get*() whenever the key is not present in the cache.
@@ -60,6 +61,9 @@ func (voidStorage) Flush() error                         { return nil }
 func (voidStorage) Len() int                             { return 0 }
 func (voidStorage) String() string                       { return "Void()" }
 
+func (voidStorage) Range(func(interface{}, interface{}) bool) error { return nil }
+func (voidStorage) Clear() error                                    { return nil }
+
 type namedCache struct {
 	Cache
 	name string
@@ -76,6 +80,38 @@ func (n *namedCache) String() string {
 	return n.name
 }
 
+func (n *namedCache) Range(f func(key, value interface{}) bool) error {
+	return Range(n.Cache, f)
+}
+
+func (n *namedCache) notifyDrops(f dropFunc) {
+	notifyDrops(n.Cache, f)
+}
+
+func (n *namedCache) Clear() error {
+	return Clear(n.Cache)
+}
+
+func (n *namedCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	return PutWithTTL(n.Cache, key, value, ttl)
+}
+
+func (n *namedCache) PutWithCost(key, value interface{}, cost int64) error {
+	return PutWithCost(n.Cache, key, value, cost)
+}
+
+func (n *namedCache) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	return GetWithExpiry(n.Cache, key)
+}
+
+func (n *namedCache) TTL(key interface{}) (time.Duration, error) {
+	return TTL(n.Cache, key)
+}
+
+func (n *namedCache) GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error) {
+	return GetOrCompute(n.Cache, key, f)
+}
+
 // NewMemoryStorage creates an empty cache using a map and a sync.RWMutex.
 func NewMemoryStorage(opts ...Option) Cache {
 	return options(opts).applyTo(&memoryStorage{items: make(map[interface{}]interface{})})
@@ -125,6 +161,30 @@ func (s *memoryStorage) String() string {
 	return fmt.Sprintf("Memory(%p)", s.items)
 }
 
+func (s *memoryStorage) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[interface{}]interface{})
+	return nil
+}
+
+func (s *memoryStorage) Range(f func(key, value interface{}) bool) error {
+	s.mu.RLock()
+	keys := make([]interface{}, 0, len(s.items))
+	values := make([]interface{}, 0, len(s.items))
+	for key, value := range s.items {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	s.mu.RUnlock()
+	for i, key := range keys {
+		if !f(key, values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
 type writeThrough struct {
 	outer Cache
 	inner Cache
@@ -151,6 +211,26 @@ func (c *writeThrough) Put(key, value interface{}) (err error) {
 	return
 }
 
+func (c *writeThrough) PutWithTTL(key, value interface{}, ttl time.Duration) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err = PutWithTTL(c.inner, key, value, ttl)
+	if err == nil {
+		err = PutWithTTL(c.outer, key, value, ttl)
+	}
+	return
+}
+
+func (c *writeThrough) PutWithCost(key, value interface{}, cost int64) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err = PutWithCost(c.inner, key, value, cost)
+	if err == nil {
+		err = PutWithCost(c.outer, key, value, cost)
+	}
+	return
+}
+
 func (c *writeThrough) Get(key interface{}) (value interface{}, err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -193,23 +273,63 @@ func (c *writeThrough) String() string {
 	return fmt.Sprintf("WriteThrough(%s,%s)", c.outer, c.inner)
 }
 
+func (c *writeThrough) Clear() (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err = Clear(c.inner)
+	if err == nil {
+		err = Clear(c.outer)
+	}
+	return
+}
+
+func (c *writeThrough) Range(f func(key, value interface{}) bool) error {
+	// Outer only contains a subset of entries of the inner cache.
+	return Range(c.inner, f)
+}
+
+func (c *writeThrough) notifyDrops(f dropFunc) {
+	notifyDrops(c.inner, f)
+	notifyDrops(c.outer, f)
+}
+
 // LoaderFunc simulates a cache by calling the functions on call to Get.
 type LoaderFunc func(interface{}) (interface{}, error)
 
 type loader struct {
 	Cache
 	f LoaderFunc
+	// errs holds the recent errors of f by key, if errTTL is positive.
+	errTTL  time.Duration
+	errs    map[interface{}]loaderError
+	sweepAt int
+	clock   Clock
+	mu      sync.Mutex
+}
+
+type loaderError struct {
+	err   error
+	until time.Time
 }
 
 // NewLoader creates a pseudo-cache from a LoaderFunc.
 func NewLoader(f LoaderFunc, opts ...Option) Cache {
-	return options(opts).applyTo(&loader{voidStorage{}, f})
+	return options(opts).applyTo(&loader{Cache: voidStorage{}, f: f})
 }
 
 // Loader adds a layer to generate values on demand.
 func Loader(f LoaderFunc) Option {
 	return func(c Cache) Cache {
-		return &loader{c, f}
+		return &loader{Cache: c, f: f}
+	}
+}
+
+// LoaderWithErrorTTL adds a layer to generate values on demand, which remembers the errors of f, including
+// ErrKeyNotFound, during errTTL. Until then, Get returns the same error without calling f, unless the entry
+// is put or removed in the meantime. This prevents a failing backend from being queried on every Get.
+func LoaderWithErrorTTL(f LoaderFunc, errTTL time.Duration) Option {
+	return func(c Cache) Cache {
+		return &loader{Cache: c, f: f, errTTL: errTTL, errs: make(map[interface{}]loaderError), clock: RealClock}
 	}
 }
 
@@ -218,9 +338,85 @@ func (l *loader) Get(key interface{}) (value interface{}, err error) {
 	if err != ErrKeyNotFound {
 		return
 	}
-	value, err = l.f(key)
-	if err == nil {
-		err = l.Cache.Put(key, value)
+	return l.load(key)
+}
+
+func (l *loader) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	value, expiresAt, err = GetWithExpiry(l.Cache, key)
+	if err != ErrKeyNotFound {
+		return
+	}
+	if value, err = l.load(key); err != nil {
+		return
+	}
+	return GetWithExpiry(l.Cache, key)
+}
+
+// load calls f, unless it has failed recently for the key, then puts the value into the underlying cache.
+func (l *loader) load(key interface{}) (value interface{}, err error) {
+	if l.errTTL <= 0 {
+		if value, err = l.f(key); err == nil {
+			err = l.Cache.Put(key, value)
+		}
+		return
+	}
+	now := l.clock.Now()
+	l.mu.Lock()
+	if e, found := l.errs[key]; found {
+		if now.Before(e.until) {
+			l.mu.Unlock()
+			return nil, e.err
+		}
+		delete(l.errs, key)
+	}
+	l.mu.Unlock()
+	if value, err = l.f(key); err != nil {
+		l.remember(key, err, now)
+		return
+	}
+	return value, l.Cache.Put(key, value)
+}
+
+// remember stores the error of f, and drops the expired ones once in a while.
+func (l *loader) remember(key interface{}, err error, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errs[key] = loaderError{err, now.Add(l.errTTL)}
+	if len(l.errs) < l.sweepAt {
+		return
+	}
+	for k, e := range l.errs {
+		if !now.Before(e.until) {
+			delete(l.errs, k)
+		}
+	}
+	if l.sweepAt = 2 * len(l.errs); l.sweepAt < 64 {
+		l.sweepAt = 64
+	}
+}
+
+// forget drops the error of f for the key, if any.
+func (l *loader) forget(key interface{}) {
+	if l.errs != nil {
+		l.mu.Lock()
+		delete(l.errs, key)
+		l.mu.Unlock()
+	}
+}
+
+func (l *loader) Put(key, value interface{}) error {
+	l.forget(key)
+	return l.Cache.Put(key, value)
+}
+
+func (l *loader) Remove(key interface{}) bool {
+	l.forget(key)
+	return l.Cache.Remove(key)
+}
+
+func (l *loader) TTL(key interface{}) (ttl time.Duration, err error) {
+	if _, _, err = l.GetWithExpiry(key); err == nil {
+		ttl, err = TTL(l.Cache, key)
 	}
 	return
 }
@@ -229,6 +425,33 @@ func (l *loader) String() string {
 	return fmt.Sprintf("Loader(%s,%v)", l.Cache, l.f)
 }
 
+func (l *loader) Range(f func(key, value interface{}) bool) error {
+	return Range(l.Cache, f)
+}
+
+func (l *loader) notifyDrops(f dropFunc) {
+	notifyDrops(l.Cache, f)
+}
+
+func (l *loader) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	l.forget(key)
+	return PutWithTTL(l.Cache, key, value, ttl)
+}
+
+func (l *loader) PutWithCost(key, value interface{}, cost int64) error {
+	l.forget(key)
+	return PutWithCost(l.Cache, key, value, cost)
+}
+
+func (l *loader) Clear() error {
+	if l.errs != nil {
+		l.mu.Lock()
+		l.errs = make(map[interface{}]loaderError)
+		l.mu.Unlock()
+	}
+	return Clear(l.Cache)
+}
+
 // ValidatorFunc is used to validate cache entries.
 type ValidatorFunc func(key, value interface{}) (bool, error)
 
@@ -238,6 +461,7 @@ type validator struct {
 }
 
 // Validate validates every entry using the given function.
+// It also removes the entries for which the underlying cache returns ErrCorrupted.
 func Validate(f ValidatorFunc) Option {
 	return func(c Cache) Cache {
 		return &validator{c, f}
@@ -250,6 +474,10 @@ func (c *validator) String() string {
 
 func (c *validator) Get(key interface{}) (value interface{}, err error) {
 	value, err = c.Cache.Get(key)
+	if errors.Is(err, ErrCorrupted) {
+		c.Cache.Remove(key)
+		return nil, ErrKeyNotFound
+	}
 	if err != nil {
 		return
 	}
@@ -264,6 +492,46 @@ func (c *validator) Get(key interface{}) (value interface{}, err error) {
 	return
 }
 
+func (c *validator) Range(f func(key, value interface{}) bool) error {
+	return Range(c.Cache, func(key, value interface{}) bool {
+		if ok, err := c.f(key, value); err != nil || !ok {
+			c.Cache.Remove(key)
+			return true
+		}
+		return f(key, value)
+	})
+}
+
+func (c *validator) notifyDrops(f dropFunc) {
+	notifyDrops(c.Cache, f)
+}
+
+func (c *validator) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	if value, err = c.Get(key); err == nil {
+		value, expiresAt, err = GetWithExpiry(c.Cache, key)
+	}
+	return
+}
+
+func (c *validator) TTL(key interface{}) (ttl time.Duration, err error) {
+	if _, err = c.Get(key); err == nil {
+		ttl, err = TTL(c.Cache, key)
+	}
+	return
+}
+
+func (c *validator) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	return PutWithTTL(c.Cache, key, value, ttl)
+}
+
+func (c *validator) PutWithCost(key, value interface{}, cost int64) error {
+	return PutWithCost(c.Cache, key, value, cost)
+}
+
+func (c *validator) Clear() error {
+	return Clear(c.Cache)
+}
+
 // Validable can validate itself
 type Validable interface {
 	IsValid() (bool, error)
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"testing"
+	"time"
 )
 
 func TestVoidStorage(t *testing.T) {
@@ -117,3 +118,39 @@ func TestLoaderOption(t *testing.T) {
 		t.Error("Flush: expected <nil>")
 	}
 }
+
+func TestLoaderWithErrorTTL(t *testing.T) {
+
+	calls := 0
+	c := LoaderWithErrorTTL(func(k interface{}) (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errDown
+		}
+		return k.(int) + 10, nil
+	}, time.Second)(NewMemoryStorage(Spy(t.Logf))).(*loader)
+	cl := FakeClock(time.Unix(0, 0))
+	c.clock = &cl
+
+	for i := 0; i < 2; i++ {
+		if v, err := c.Get(5); v != nil || err != errDown {
+			t.Errorf("Get: expected <nil>, %v, got %v, %v", errDown, v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("calls: expected 1, got %d", calls)
+	}
+
+	cl.Advance(2 * time.Second)
+	if v, err := c.Get(5); v != 15 || err != nil {
+		t.Errorf("Get: expected 15, <nil>, got %v, %v", v, err)
+	}
+
+	calls = 0
+	c.Remove(5)
+	c.Get(5)
+	c.Put(5, 6)
+	if v, err := c.Get(5); v != 6 || err != nil {
+		t.Errorf("Get: expected 6, <nil>, got %v, %v", v, err)
+	}
+}
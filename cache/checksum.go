@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// ErrCorrupted is returned when a serialized value does not match its checksum.
+// The Validate layer removes the corrupted entries, as if they were invalid.
+var ErrCorrupted = errors.New("corrupted value")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksum wraps a serializer to append a CRC-32C checksum to the serialized values, and to check it
+// when unserializing them, so corrupted entries are detected before reaching the inner serializer.
+func Checksum(inner Serializer) Serializer {
+	return checksumSerializer{inner}
+}
+
+type checksumSerializer struct {
+	inner Serializer
+}
+
+func (s checksumSerializer) Serialize(value interface{}) ([]byte, error) {
+	data, err := s.inner.Serialize(value)
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint32(data, crc32.Checksum(data, castagnoli)), nil
+}
+
+func (s checksumSerializer) Unserialize(data []byte) (interface{}, error) {
+	n := len(data) - 4
+	if n < 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrCorrupted, len(data))
+	}
+	if sum := crc32.Checksum(data[:n], castagnoli); sum != binary.BigEndian.Uint32(data[n:]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupted)
+	}
+	return s.inner.Unserialize(data[:n])
+}
+
+func (s checksumSerializer) String() string {
+	return fmt.Sprintf("Checksum(%v)", s.inner)
+}
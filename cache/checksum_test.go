@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+
+	u := NewMemoryStorage()
+	c := Serialization(Checksum(GobSerializer))(u)
+
+	c.Put(5, "six")
+	if v, err := c.Get(5); v != "six" || err != nil {
+		t.Errorf("Get: expected six, <nil>, got %v, %v", v, err)
+	}
+
+	data, _ := u.Get(5)
+	data.([]byte)[0] ^= 1
+	if _, err := c.Get(5); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Get: expected %v, got %v", ErrCorrupted, err)
+	}
+
+	u.Put(6, []byte{1})
+	if _, err := c.Get(6); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Get: expected %v, got %v", ErrCorrupted, err)
+	}
+
+	v := options{
+		Validate(func(key, value interface{}) (bool, error) { return true, nil }),
+		Serialization(Checksum(GobSerializer)),
+	}.applyTo(u)
+	if _, err := v.Get(5); err != ErrKeyNotFound {
+		t.Errorf("Get: expected %v, got %v", ErrKeyNotFound, err)
+	}
+	if u.Len() != 1 {
+		t.Error("expected the corrupted entry to be removed")
+	}
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotClearable is returned by Clear when a cache does not support clearing.
+var ErrNotClearable = errors.New("cache is not clearable")
+
+// Clearer is implemented by caches which can remove all their entries at once.
+// The storages and the options of this package implement it, as long as their underlying cache does.
+// Options with an internal state, like Eviction, reset it.
+type Clearer interface {
+	// Clear removes all the entries of the cache.
+	Clear() error
+}
+
+// Clear removes all the entries of the cache.
+// It returns an error wrapping ErrNotClearable if the cache does not implement Clearer.
+func Clear(c Cache) error {
+	if cl, ok := c.(Clearer); ok {
+		return cl.Clear()
+	}
+	return fmt.Errorf("%w: %s", ErrNotClearable, c)
+}
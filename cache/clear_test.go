@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryStorageClear(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(t.Logf), Name("test"))
+	c.Put(5, 6)
+	c.Put(6, 7)
+
+	if err := Clear(c); err != nil {
+		t.Errorf("Clear: unexpected error %v", err)
+	}
+
+	if v := c.Len(); v != 0 {
+		t.Errorf("Len: expected 0, got %d", v)
+	}
+
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+}
+
+func TestEvictingCacheClear(t *testing.T) {
+
+	c := NewMemoryStorage(LRUEviction(2), Spy(t.Logf))
+	c.Put(1, 1)
+	c.Put(2, 2)
+
+	if err := Clear(c); err != nil {
+		t.Errorf("Clear: unexpected error %v", err)
+	}
+
+	c.Put(3, 3)
+	c.Put(4, 4)
+
+	if v := c.Len(); v != 2 {
+		t.Errorf("Len: expected 2, got %d", v)
+	}
+
+	if _, err := c.Get(3); err != nil {
+		t.Errorf("Get: expected <nil>, got %v", err)
+	}
+}
+
+func TestClearEmitsEvent(t *testing.T) {
+
+	ch := make(chan Event, 1)
+	c := NewMemoryStorage(Emitter(ch))
+
+	Clear(c)
+
+	if e := <-ch; e.Type != CLEAR || e.Err != nil {
+		t.Errorf("Emitter: expected a CLEAR event, got %#v", e.Type)
+	}
+}
+
+func TestNotClearable(t *testing.T) {
+
+	c := struct{ Cache }{NewMemoryStorage()}
+
+	if err := Clear(c); !errors.Is(err, ErrNotClearable) {
+		t.Errorf("Clear: expected %v, got %v", ErrNotClearable, err)
+	}
+}
+
+func TestTypedClear(t *testing.T) {
+
+	c := NewTypedMemoryStorage[int, string]()
+	c.Put(1, "one")
+
+	if err := Clear(Untyped(c)); err != nil {
+		t.Errorf("Clear: unexpected error %v", err)
+	}
+
+	if v := c.Len(); v != 0 {
+		t.Errorf("Len: expected 0, got %d", v)
+	}
+}
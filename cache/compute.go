@@ -0,0 +1,31 @@
+package cache
+
+// ComputeFunc computes the value of an entry missing from a cache.
+type ComputeFunc func() (interface{}, error)
+
+// Computer is implemented by caches which can atomically get or compute an entry.
+// SingleFlight implements it so concurrent callers for the same key only compute the value once. The options of
+// this package which do not alter the stored values, like Measure, Timeout or Locking, forward it to their
+// underlying cache; the other ones use Get then Put.
+type Computer interface {
+	// GetOrCompute returns the value of the entry if present, else computes it using f, stores and returns it.
+	GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error)
+}
+
+// GetOrCompute returns the value of the entry if present, else computes it using f, stores and returns it.
+//
+// If the cache does not implement Computer, this is done using Get then Put, so concurrent callers may compute
+// the value several times. Errors of f are returned as is, and the value is not stored.
+func GetOrCompute(c Cache, key interface{}, f ComputeFunc) (interface{}, error) {
+	if co, ok := c.(Computer); ok {
+		return co.GetOrCompute(key, f)
+	}
+	value, err := c.Get(key)
+	if err != ErrKeyNotFound {
+		return value, err
+	}
+	if value, err = f(); err != nil {
+		return nil, err
+	}
+	return value, c.Put(key, value)
+}
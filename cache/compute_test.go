@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrCompute(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(t.Logf))
+
+	if v, err := GetOrCompute(c, 5, func() (interface{}, error) { return 6, nil }); v != 6 || err != nil {
+		t.Error("GetOrCompute: expected 6, <nil>")
+	}
+
+	if v, err := GetOrCompute(c, 5, func() (interface{}, error) { return 7, nil }); v != 6 || err != nil {
+		t.Error("GetOrCompute: expected 6, <nil>")
+	}
+
+	failure := errors.New("failure")
+	if _, err := GetOrCompute(c, 6, func() (interface{}, error) { return nil, failure }); err != failure {
+		t.Errorf("GetOrCompute: expected %v", failure)
+	}
+
+	if v := c.Len(); v != 1 {
+		t.Errorf("Len: expected 1, got %d", v)
+	}
+}
+
+func TestSingleFlight_GetOrCompute(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(timedPrintf(t)), SingleFlight)
+
+	var (
+		computed int32
+		wg       sync.WaitGroup
+	)
+	compute := func() (interface{}, error) {
+		atomic.AddInt32(&computed, 1)
+		time.Sleep(50 * time.Millisecond)
+		return 6, nil
+	}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := GetOrCompute(c, 5, compute); v != 6 || err != nil {
+				t.Errorf("GetOrCompute: expected 6, <nil>, got %v, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&computed); n != 1 {
+		t.Errorf("expected 1 computation, got %d", n)
+	}
+
+	if v, err := c.Get(5); v != 6 || err != nil {
+		t.Error("Get: expected 6, <nil>")
+	}
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// CopyOption customizes Copy.
+type CopyOption func(*copyConfig)
+
+type copyConfig struct {
+	rate     float64
+	progress func(copied, total int)
+	keepTTL  bool
+}
+
+// CopyRate limits the number of entries written per second.
+func CopyRate(rate float64) CopyOption {
+	return func(c *copyConfig) {
+		c.rate = rate
+	}
+}
+
+// CopyProgress calls f after each copied entry, with the number of entries copied so far and the length of the
+// source cache before the copy.
+func CopyProgress(f func(copied, total int)) CopyOption {
+	return func(c *copyConfig) {
+		c.progress = f
+	}
+}
+
+// CopyTTL keeps the time left before the entries expire, using TTL and PutWithTTL.
+func CopyTTL() CopyOption {
+	return func(c *copyConfig) {
+		c.keepTTL = true
+	}
+}
+
+// Copy writes all the entries of src into dst, e.g. to migrate a cache to another storage, or to rebuild it after
+// a change of serializer. It stops at the first error.
+// It returns an error wrapping ErrNotIterable if src does not implement Iterable.
+func Copy(dst, src Cache, opts ...CopyOption) (err error) {
+	var conf copyConfig
+	for _, o := range opts {
+		o(&conf)
+	}
+	if conf.rate > 0 {
+		dst = RateLimitWith(RateLimitConfig{Rate: conf.rate, Block: true})(dst)
+	}
+	total := src.Len()
+	copied := 0
+	if rerr := Range(src, func(key, value interface{}) bool {
+		var ttl time.Duration
+		if conf.keepTTL {
+			if ttl, err = TTL(src, key); err == ErrKeyNotFound {
+				// Expired in the meantime.
+				err = nil
+				return true
+			} else if err != nil {
+				err = fmt.Errorf("cannot copy key %v: %w", key, err)
+				return false
+			}
+		}
+		if ttl > 0 {
+			err = PutWithTTL(dst, key, value, ttl)
+		} else {
+			err = dst.Put(key, value)
+		}
+		if err != nil {
+			err = fmt.Errorf("cannot copy key %v: %w", key, err)
+			return false
+		}
+		copied++
+		if conf.progress != nil {
+			conf.progress(copied, total)
+		}
+		return true
+	}); rerr != nil {
+		return rerr
+	}
+	return
+}
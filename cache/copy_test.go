@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCopy(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	src := NewMemoryStorage(ExpirationUsingClock(time.Minute, &cl))
+	for i := 0; i < 5; i++ {
+		src.Put(i, i*10)
+	}
+	cl.Advance(time.Second)
+
+	dst := NewMemoryStorage(Spy(t.Logf), ExpirationUsingClock(time.Hour, &cl))
+	var progress []int
+	err := Copy(dst, src, CopyTTL(), CopyProgress(func(copied, total int) {
+		if total != 5 {
+			t.Errorf("CopyProgress: expected a total of 5, got %d", total)
+		}
+		progress = append(progress, copied)
+	}))
+	if err != nil {
+		t.Errorf("Copy: unexpected error %v", err)
+	}
+	if len(progress) != 5 || progress[4] != 5 {
+		t.Errorf("CopyProgress: expected [1 2 3 4 5], got %v", progress)
+	}
+	if v, err := dst.Get(3); v != 30 || err != nil {
+		t.Errorf("Get: expected 30, <nil>, got %v, %v", v, err)
+	}
+	if ttl, err := TTL(dst, 3); ttl != 59*time.Second || err != nil {
+		t.Errorf("TTL: expected 59s, <nil>, got %v, %v", ttl, err)
+	}
+
+	if err := Copy(dst, mapStorage{}); !errors.Is(err, ErrNotIterable) {
+		t.Errorf("Copy: expected %v, got %v", ErrNotIterable, err)
+	}
+	if err := Copy(Untyped(NewTypedMemoryStorage[int, string]()), src); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("Copy: expected %v, got %v", ErrUnexpectedType, err)
+	}
+}
+
+func TestCopyRate(t *testing.T) {
+
+	src := NewMemoryStorage()
+	for i := 0; i < 3; i++ {
+		src.Put(i, i)
+	}
+
+	start := time.Now()
+	if err := Copy(NewMemoryStorage(), src, CopyRate(100)); err != nil {
+		t.Errorf("Copy: unexpected error %v", err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("Copy: expected to take at least 20ms, took %s", d)
+	}
+}
@@ -0,0 +1,17 @@
+package cache
+
+// CostCache is implemented by caches which can store entries with a specific cost.
+// CostEviction implements it, and the other options of this package forward it to their underlying cache.
+type CostCache interface {
+	// PutWithCost stores an entry into the cache, which counts for the given cost in the cache budget.
+	PutWithCost(key, value interface{}, cost int64) error
+}
+
+// PutWithCost stores an entry into the cache, which counts for the given cost in the cache budget.
+// If the cache does not implement CostCache, the entry is stored using Put, and the cost is ignored.
+func PutWithCost(c Cache, key, value interface{}, cost int64) error {
+	if cc, ok := c.(CostCache); ok {
+		return cc.PutWithCost(key, value, cost)
+	}
+	return c.Put(key, value)
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPutWithCost(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(t.Logf), CostEviction(10, nil, NewLRUEviction))
+
+	if err := PutWithCost(c, 1, "a", 6); err != nil {
+		t.Errorf("PutWithCost: unexpected error %v", err)
+	}
+	c.Put(2, "b")
+	c.Put(3, "c")
+	c.Put(4, "d")
+	if c.Len() != 4 {
+		t.Error("Expected length 4")
+	}
+
+	if err := PutWithCost(c, 5, "e", 5); err != nil {
+		t.Errorf("PutWithCost: unexpected error %v", err)
+	}
+	if v, err := c.Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+	if c.Len() != 4 {
+		t.Error("Expected length 4")
+	}
+
+	if err := PutWithCost(c, 6, "f", 11); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("PutWithCost: expected %v, got %v", ErrTooLarge, err)
+	}
+}
+
+func TestPutWithCostFallback(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(t.Logf))
+
+	if err := PutWithCost(c, 5, 6, 1000); err != nil {
+		t.Errorf("PutWithCost: unexpected error %v", err)
+	}
+
+	if v, err := c.Get(5); v != 6 || err != nil {
+		t.Error("Get: expected 6, <nil>")
+	}
+}
+
+func TestPutWithCostForwarding(t *testing.T) {
+
+	c := NewMemoryStorage(Expiration(time.Hour), Serialization(gobSerializer{}), CostEviction(10, nil, NewLRUEviction))
+
+	PutWithCost(c, 1, "a", 6)
+	PutWithCost(c, 2, "b", 6)
+	if v, err := c.Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+}
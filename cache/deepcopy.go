@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// DeepCopy adds a layer which copies the values on Put and on Get, using cloner, so the callers cannot modify the
+// values held by in-memory storages.
+//
+// It defaults to a gob round trip: the concrete types of the values must be registered using gob.Register, unless
+// they are basic types, and their unexported fields are not copied.
+func DeepCopy(cloner func(interface{}) interface{}) Option {
+	clone := func(value interface{}) (interface{}, error) { return cloner(value), nil }
+	if cloner == nil {
+		clone = gobClone
+	}
+	return func(c Cache) Cache {
+		return &copyingCache{c, clone}
+	}
+}
+
+func gobClone(value interface{}) (interface{}, error) {
+	data, err := gobEncode(value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot copy value: %w", err)
+	}
+	return gobDecode(data)
+}
+
+type copyingCache struct {
+	Cache
+	clone func(interface{}) (interface{}, error)
+}
+
+func (c *copyingCache) Put(key, value interface{}) error {
+	v, err := c.clone(value)
+	if err != nil {
+		return err
+	}
+	return c.Cache.Put(key, v)
+}
+
+func (c *copyingCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	v, err := c.clone(value)
+	if err != nil {
+		return err
+	}
+	return PutWithTTL(c.Cache, key, v, ttl)
+}
+
+func (c *copyingCache) PutWithCost(key, value interface{}, cost int64) error {
+	v, err := c.clone(value)
+	if err != nil {
+		return err
+	}
+	return PutWithCost(c.Cache, key, v, cost)
+}
+
+func (c *copyingCache) Get(key interface{}) (interface{}, error) {
+	value, err := c.Cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.clone(value)
+}
+
+func (c *copyingCache) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	value, expiresAt, err := GetWithExpiry(c.Cache, key)
+	if err != nil {
+		return nil, expiresAt, err
+	}
+	value, err = c.clone(value)
+	return value, expiresAt, err
+}
+
+func (c *copyingCache) TTL(key interface{}) (time.Duration, error) {
+	return TTL(c.Cache, key)
+}
+
+func (c *copyingCache) Range(f func(key, value interface{}) bool) (err error) {
+	if rerr := Range(c.Cache, func(key, value interface{}) bool {
+		if value, err = c.clone(value); err != nil {
+			return false
+		}
+		return f(key, value)
+	}); rerr != nil {
+		return rerr
+	}
+	return
+}
+
+func (c *copyingCache) Clear() error {
+	return Clear(c.Cache)
+}
+
+func (c *copyingCache) notifyDrops(f dropFunc) {
+	notifyDrops(c.Cache, f)
+}
+
+func (c *copyingCache) String() string {
+	return fmt.Sprintf("DeepCopy(%s)", c.Cache)
+}
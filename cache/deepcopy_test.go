@@ -0,0 +1,40 @@
+package cache
+
+import "testing"
+
+func TestDeepCopy(t *testing.T) {
+
+	c := NewMemoryStorage(DeepCopy(nil), Spy(t.Logf))
+
+	value := []int{1, 2, 3}
+	if err := c.Put(1, value); err != nil {
+		t.Fatalf("Put: unexpected error %v", err)
+	}
+	value[0] = 10
+
+	v, err := c.Get(1)
+	if s, ok := v.([]int); !ok || s[0] != 1 || err != nil {
+		t.Fatalf("Get: expected [1 2 3], <nil>, got %v, %v", v, err)
+	}
+	v.([]int)[1] = 20
+
+	Range(c, func(_, v interface{}) bool {
+		if s := v.([]int); s[0] != 1 || s[1] != 2 {
+			t.Errorf("Range: expected [1 2 3], got %v", s)
+		}
+		return true
+	})
+
+	if err := c.Put(2, make(chan int)); err == nil {
+		t.Error("Put: expected an error")
+	}
+
+	c = NewMemoryStorage(DeepCopy(func(v interface{}) interface{} {
+		return append([]int(nil), v.([]int)...)
+	}))
+	c.Put(1, value)
+	value[0] = 100
+	if v, _ := c.Get(1); v.([]int)[0] != 10 {
+		t.Errorf("Get: expected [10 2 3], got %v", v)
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Encrypt wraps a serializer to encrypt the serialized values using AES-GCM, e.g. to store sensitive data on disk.
+// The key must be 16, 24 or 32 bytes long, to select AES-128, AES-192 or AES-256.
+func Encrypt(inner Serializer, key []byte) (Serializer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingSerializer{inner, aead}, nil
+}
+
+type encryptingSerializer struct {
+	inner Serializer
+	aead  cipher.AEAD
+}
+
+func (s *encryptingSerializer) Serialize(value interface{}) ([]byte, error) {
+	data, err := s.inner.Serialize(value)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(data)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func (s *encryptingSerializer) Unserialize(data []byte) (interface{}, error) {
+	n := s.aead.NonceSize()
+	if len(data) < n {
+		return nil, errors.New("cannot decrypt value: too short")
+	}
+	plain, err := s.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt value: %w", err)
+	}
+	return s.inner.Unserialize(plain)
+}
+
+func (s *encryptingSerializer) String() string {
+	return fmt.Sprintf("Encrypt(%v)", s.inner)
+}
+
+// HMACKeys adds a layer which replaces the keys by their HMAC-SHA256, as hexadecimal strings, so the keys
+// are not stored in clear either. The keys are gob-encoded first.
+//
+// Range returns the hashed keys.
+func HMACKeys(secret []byte) Option {
+	return func(c Cache) Cache {
+		return &hashedKeys{c, func(key interface{}) (interface{}, error) {
+			data, err := gobEncode(key)
+			if err != nil {
+				return nil, err
+			}
+			mac := hmac.New(sha256.New, secret)
+			mac.Write(data)
+			return hex.EncodeToString(mac.Sum(nil)), nil
+		}}
+	}
+}
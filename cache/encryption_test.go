@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+
+	if _, err := Encrypt(GobSerializer, []byte("short")); err == nil {
+		t.Error("Encrypt: expected an error for an invalid key")
+	}
+
+	s, err := Encrypt(GobSerializer, []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Encrypt: unexpected error %v", err)
+	}
+
+	u := NewMemoryStorage()
+	c := options{Serialization(s), HMACKeys([]byte("secret"))}.applyTo(u)
+
+	if err := c.Put("password", "s3cr3t"); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	if v, err := c.Get("password"); v != "s3cr3t" || err != nil {
+		t.Errorf("Get: expected s3cr3t, <nil>, got %v, %v", v, err)
+	}
+
+	Range(u, func(key, value interface{}) bool {
+		if key == "password" {
+			t.Error("expected the key to be hashed")
+		}
+		if bytes.Contains(value.([]byte), []byte("s3cr3t")) {
+			t.Error("expected the value to be encrypted")
+		}
+		data := value.([]byte)
+		data[len(data)-1] ^= 1
+		return true
+	})
+
+	if _, err := c.Get("password"); err == nil {
+		t.Error("Get: expected an error for a tampered value")
+	}
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EventFilter selects the events sent to a subscriber.
+type EventFilter func(Event) bool
+
+// EventTypes selects the events of the given types.
+func EventTypes(types ...EventType) EventFilter {
+	return func(e Event) bool {
+		for _, t := range types {
+			if e.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// EventKeys selects the events whose key matches the predicate.
+func EventKeys(pred func(key interface{}) bool) EventFilter {
+	return func(e Event) bool {
+		return e.Key != nil && pred(e.Key)
+	}
+}
+
+// EventHub dispatches cache events to several subscribers. See Events.
+type EventHub struct {
+	subs map[*Subscription]struct{}
+	mu   sync.RWMutex
+}
+
+// Subscription is returned by EventHub.Subscribe.
+type Subscription struct {
+	hub     *EventHub
+	ch      chan<- Event
+	filter  EventFilter
+	dropped atomic.Uint64
+}
+
+// NewEventHub creates an EventHub without subscribers.
+func NewEventHub() *EventHub {
+	return &EventHub{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe sends the events selected by filter to ch; a nil filter selects all events.
+// The events are dropped when ch is full, so a slow subscriber does not block the cache nor the other subscribers.
+func (h *EventHub) Subscribe(ch chan<- Event, filter EventFilter) *Subscription {
+	s := &Subscription{hub: h, ch: ch, filter: filter}
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Unsubscribe stops sending events to the subscriber.
+func (s *Subscription) Unsubscribe() {
+	s.hub.mu.Lock()
+	delete(s.hub.subs, s)
+	s.hub.mu.Unlock()
+}
+
+// DroppedEvents returns the number of events dropped because the channel of the subscriber was full.
+func (s *Subscription) DroppedEvents() uint64 {
+	return s.dropped.Load()
+}
+
+func (h *EventHub) publish(e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Events sends cache events to the subscribers of the hub, like Emitter.
+func Events(h *EventHub) Option {
+	return func(c Cache) Cache {
+		e := &emitter{Cache: c, hub: h}
+		notifyDrops(c, func(t EventType, key, value interface{}) {
+			e.emit(t, key, value, nil)
+		})
+		return e
+	}
+}
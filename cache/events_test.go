@@ -0,0 +1,37 @@
+package cache
+
+import "testing"
+
+func TestEvents(t *testing.T) {
+
+	h := NewEventHub()
+	c := NewMemoryStorage(Events(h), LRUEviction(1))
+
+	all := make(chan Event, 10)
+	evictions := make(chan Event, 10)
+	even := make(chan Event, 10)
+	h.Subscribe(all, nil)
+	h.Subscribe(evictions, EventTypes(EVICT))
+	sub := h.Subscribe(even, EventKeys(func(key interface{}) bool { return key.(int)%2 == 0 }))
+
+	c.Put(1, 10)
+	c.Put(2, 20)
+	sub.Unsubscribe()
+	c.Put(4, 40)
+
+	if n := len(all); n != 5 {
+		t.Errorf("expected 5 events, got %d", n)
+	}
+	if n := len(evictions); n != 2 {
+		t.Errorf("expected 2 EVICT events, got %d", n)
+	}
+	if e := <-evictions; e.Key != 1 || e.Value != 10 {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+	if n := len(even); n != 1 {
+		t.Errorf("expected 1 event, got %d", n)
+	}
+	if e := <-even; e.Type != PUT || e.Key != 2 {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+}
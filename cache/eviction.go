@@ -3,8 +3,11 @@ package cache
 import (
 	"container/heap"
 	"container/list"
+	"errors"
 	"fmt"
+	"math/rand"
 	"sync"
+	"time"
 )
 
 // EvictionStrategy is used to select entries to evict when the underlying cache is full.
@@ -30,14 +33,67 @@ type EvictionFactory func() EvictionStrategy
 type evictingCache struct {
 	Cache
 	maxLen int
+	f      EvictionFactory
 	s      EvictionStrategy
+	// ignoreHits is set for strategies implementing hitless.
+	ignoreHits bool
+	// The cost budget of size-aware and cost-based eviction, if costFn is not nil.
+	maxCost int64
+	costFn  Sizer
+	costs   map[interface{}]int64
+	total   int64
+	drops   dropHooks
 	sync.Mutex
 }
 
+// ErrTooLarge is returned by size-aware and cost-based evicting caches for entries larger than their whole budget.
+var ErrTooLarge = errors.New("entry too large")
+
+// Sizer returns the size of an entry, e.g. in bytes, or its cost.
+type Sizer func(key, value interface{}) int64
+
 // Eviction adds a layer to evict entries when the underlying cache is full.
 func Eviction(maxLen int, f EvictionFactory) Option {
 	return func(c Cache) Cache {
-		return &evictingCache{Cache: c, maxLen: maxLen, s: f()}
+		s := f()
+		_, ignoreHits := s.(hitless)
+		return &evictingCache{Cache: c, maxLen: maxLen, f: f, s: s, ignoreHits: ignoreHits}
+	}
+}
+
+// SizeEviction adds a layer to evict entries using the Least-Recently-Used strategy when the total size
+// of the entries exceeds the budget.
+func SizeEviction(maxBytes int64, sizer Sizer) Option {
+	return SizeEvictionUsing(maxBytes, sizer, NewLRUEviction)
+}
+
+// SizeEvictionUsing adds a layer to evict entries using the given strategy when the total size
+// of the entries exceeds the budget.
+func SizeEvictionUsing(maxBytes int64, sizer Sizer, f EvictionFactory) Option {
+	return CostEviction(maxBytes, sizer, f)
+}
+
+// CostEviction adds a layer to evict entries using the given strategy when the total cost
+// of the entries exceeds the budget.
+//
+// The cost of the entries stored using PutWithCost is given by the caller. The cost of the other entries
+// is computed using the given function, or is 1 if it is nil.
+func CostEviction(maxCost int64, cost Sizer, f EvictionFactory) Option {
+	if cost == nil {
+		cost = func(interface{}, interface{}) int64 { return 1 }
+	}
+	return func(c Cache) Cache {
+		s := f()
+		_, ignoreHits := s.(hitless)
+		return &evictingCache{
+			Cache:      c,
+			f:          f,
+			s:          s,
+			ignoreHits: ignoreHits,
+			maxCost:    maxCost,
+			costFn:     cost,
+			costs:      make(map[interface{}]int64),
+		}
 	}
 }
 
@@ -51,30 +107,110 @@ func LFUEviction(maxLen int) Option {
 	return Eviction(maxLen, NewLFUEviction)
 }
 
+// RandomEviction adds entry eviction using the random replacement strategy
+func RandomEviction(maxLen int) Option {
+	return Eviction(maxLen, NewRandomEviction)
+}
+
+// SLRUEviction adds entry eviction using the Segmented-Least-Recently-Used strategy
+func SLRUEviction(maxLen int, protectedRatio float64) Option {
+	return Eviction(maxLen, func() EvictionStrategy { return NewSLRUEviction(protectedRatio) })
+}
+
 func (c *evictingCache) Put(key, value interface{}) (err error) {
-	for c.Cache.Len() >= c.maxLen {
+	return c.put(key, c.cost(key, value), func() error { return c.Cache.Put(key, value) })
+}
+
+func (c *evictingCache) PutWithTTL(key, value interface{}, ttl time.Duration) (err error) {
+	return c.put(key, c.cost(key, value), func() error { return PutWithTTL(c.Cache, key, value, ttl) })
+}
+
+func (c *evictingCache) PutWithCost(key, value interface{}, cost int64) (err error) {
+	return c.put(key, cost, func() error { return c.Cache.Put(key, value) })
+}
+
+func (c *evictingCache) cost(key, value interface{}) int64 {
+	if c.costFn == nil {
+		return 0
+	}
+	return c.costFn(key, value)
+}
+
+// isFull returns whether an entry must be evicted before storing the new one.
+func (c *evictingCache) isFull(key interface{}, cost int64) bool {
+	if c.costFn == nil {
+		return c.Cache.Len() >= c.maxLen
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.total-c.costs[key]+cost > c.maxCost
+}
+
+func (c *evictingCache) put(key interface{}, cost int64, put func() error) (err error) {
+	if c.costFn != nil && cost > c.maxCost {
+		return fmt.Errorf("%w: cost %d for key %v in %s", ErrTooLarge, cost, key, c.Cache)
+	}
+	full := c.isFull(key, cost)
+	if full && c.costFn == nil {
+		// Updates do not need room.
+		_, err := c.Cache.Get(key)
+		full = err != nil
+	}
+	checkAdmission := true
+	for full && c.isFull(key, cost) {
 		c.Lock()
 		toEvict := c.s.Pop()
+		if a, ok := c.s.(admitter); ok && checkAdmission && toEvict != nil {
+			if !a.admit(key, toEvict) {
+				c.s.Added(toEvict)
+				c.Unlock()
+				return nil
+			}
+			checkAdmission = false
+		}
 		c.Unlock()
 		if toEvict == nil {
 			break
 		}
-		if !c.Cache.Remove(toEvict) {
+		var value interface{}
+		if len(c.drops) > 0 {
+			value, _ = c.Cache.Get(toEvict)
+		}
+		removed := c.Cache.Remove(toEvict)
+		if removed {
+			c.drops.dropped(EVICT, toEvict, value)
+		}
+		c.Lock()
+		c.removeCost(toEvict)
+		c.Unlock()
+		if !removed && c.costFn == nil {
 			break
 		}
 	}
-	err = c.Cache.Put(key, value)
+	err = put()
 	if err == nil {
 		c.Lock()
 		c.s.Added(key)
+		if c.costFn != nil {
+			c.total += cost - c.costs[key]
+			c.costs[key] = cost
+		}
 		c.Unlock()
 	}
 	return nil
 }
 
+// removeCost forgets the cost of an entry. It must be called with the lock held.
+func (c *evictingCache) removeCost(key interface{}) {
+	if c.costFn != nil {
+		c.total -= c.costs[key]
+		delete(c.costs, key)
+	}
+}
+
 func (c *evictingCache) Get(key interface{}) (value interface{}, err error) {
 	value, err = c.Cache.Get(key)
-	if err == nil {
+	if err == nil && !c.ignoreHits {
 		c.Lock()
 		c.s.Hit(key)
 		c.Unlock()
@@ -82,17 +218,62 @@ func (c *evictingCache) Get(key interface{}) (value interface{}, err error) {
 	return
 }
 
+func (c *evictingCache) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	value, expiresAt, err = GetWithExpiry(c.Cache, key)
+	if err == nil && !c.ignoreHits {
+		c.Lock()
+		c.s.Hit(key)
+		c.Unlock()
+	}
+	return
+}
+
+func (c *evictingCache) TTL(key interface{}) (time.Duration, error) {
+	return TTL(c.Cache, key)
+}
+
 func (c *evictingCache) Remove(key interface{}) bool {
 	c.Lock()
 	c.s.Removed(key)
+	c.removeCost(key)
 	c.Unlock()
 	return c.Cache.Remove(key)
 }
 
+func (c *evictingCache) Clear() (err error) {
+	c.Lock()
+	defer c.Unlock()
+	if err = Clear(c.Cache); err == nil {
+		c.s = c.f()
+		if c.costFn != nil {
+			c.costs = make(map[interface{}]int64)
+			c.total = 0
+		}
+	}
+	return
+}
+
+func (c *evictingCache) Range(f func(key, value interface{}) bool) error {
+	return Range(c.Cache, f)
+}
+
+func (c *evictingCache) notifyDrops(f dropFunc) {
+	c.drops = append(c.drops, f)
+	notifyDrops(c.Cache, f)
+}
+
 func (c *evictingCache) String() string {
+	if c.costFn != nil {
+		return fmt.Sprintf("CostEvicting(%s,%d/%d,%v)", c.Cache, c.total, c.maxCost, c.s)
+	}
 	return fmt.Sprintf("Evicting(%s,%d,%v)", c.Cache, c.maxLen, c.s)
 }
 
+// hitless is implemented by the strategies that ignore hits, so the cache can skip the locking.
+type hitless interface {
+	hitless()
+}
+
 // Least-Recently Used eviction strategy
 
 type lruEviction struct {
@@ -106,6 +287,10 @@ func NewLRUEviction() EvictionStrategy {
 }
 
 func (e *lruEviction) Added(key interface{}) {
+	if elem, found := e.elements[key]; found {
+		e.keys.MoveToFront(elem)
+		return
+	}
 	e.elements[key] = e.keys.PushFront(key)
 }
 
@@ -140,6 +325,89 @@ func (e *lruEviction) String() string {
 	return fmt.Sprintf("LRU(%d)", len(e.elements))
 }
 
+// Segmented Least-Recently Used eviction strategy
+
+type slruEviction struct {
+	probation *list.List
+	protected *list.List
+	elements  map[interface{}]*list.Element
+	ratio     float64
+}
+
+type slruEntry struct {
+	key       interface{}
+	protected bool
+}
+
+// NewSLRUEviction creates a new instance of the Segmented-Least-Recently-Used strategy.
+// New entries enter a probation segment and are promoted to a protected segment on their second hit,
+// so one-shot reads do not evict hot entries. protectedRatio is the maximum share of the entries
+// in the protected segment; the least recently used protected entries are moved back into the probation segment.
+func NewSLRUEviction(protectedRatio float64) EvictionStrategy {
+	return &slruEviction{list.New(), list.New(), make(map[interface{}]*list.Element), protectedRatio}
+}
+
+func (e *slruEviction) Added(key interface{}) {
+	if elem, found := e.elements[key]; found {
+		e.segment(elem).MoveToFront(elem)
+		return
+	}
+	e.elements[key] = e.probation.PushFront(&slruEntry{key, false})
+}
+
+func (e *slruEviction) Removed(key interface{}) (found bool) {
+	elem, found := e.elements[key]
+	if found {
+		e.segment(elem).Remove(elem)
+		delete(e.elements, key)
+	}
+	return
+}
+
+func (e *slruEviction) Hit(key interface{}) {
+	elem, found := e.elements[key]
+	if !found {
+		e.Added(key)
+		return
+	}
+	entry := elem.Value.(*slruEntry)
+	if entry.protected {
+		e.protected.MoveToFront(elem)
+		return
+	}
+	e.probation.Remove(elem)
+	entry.protected = true
+	e.elements[key] = e.protected.PushFront(entry)
+	for float64(e.protected.Len()) > e.ratio*float64(len(e.elements)) {
+		entry = e.protected.Remove(e.protected.Back()).(*slruEntry)
+		entry.protected = false
+		e.elements[entry.key] = e.probation.PushFront(entry)
+	}
+}
+
+func (e *slruEviction) Pop() (key interface{}) {
+	elem := e.probation.Back()
+	if elem == nil {
+		if elem = e.protected.Back(); elem == nil {
+			return
+		}
+	}
+	key = e.segment(elem).Remove(elem).(*slruEntry).key
+	delete(e.elements, key)
+	return
+}
+
+func (e *slruEviction) segment(elem *list.Element) *list.List {
+	if elem.Value.(*slruEntry).protected {
+		return e.protected
+	}
+	return e.probation
+}
+
+func (e *slruEviction) String() string {
+	return fmt.Sprintf("SLRU(%d,%d)", e.probation.Len(), e.protected.Len())
+}
+
 // Least-Frequently Used eviction strategy
 
 type lfuEviction struct {
@@ -154,7 +422,9 @@ func NewLFUEviction() EvictionStrategy {
 }
 
 func (e *lfuEviction) Added(key interface{}) {
-	heap.Push(e.heap, key)
+	if _, found := e.heap.index[key]; !found {
+		heap.Push(e.heap, key)
+	}
 }
 
 func (e *lfuEviction) Removed(key interface{}) (found bool) {
@@ -176,6 +446,63 @@ func (e *lfuEviction) String() string {
 	return fmt.Sprintf("LFU(%d)", e.heap.Len())
 }
 
+// Random replacement eviction strategy
+
+type randomEviction struct {
+	keys  []interface{}
+	index map[interface{}]int
+	rand  *rand.Rand
+}
+
+// NewRandomEviction creates a new instance of the random replacement strategy, which evicts a random entry.
+// It ignores hits, so it has no overhead on Get.
+func NewRandomEviction() EvictionStrategy {
+	return &randomEviction{index: make(map[interface{}]int), rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (e *randomEviction) hitless() {}
+
+func (e *randomEviction) Added(key interface{}) {
+	if _, found := e.index[key]; !found {
+		e.index[key] = len(e.keys)
+		e.keys = append(e.keys, key)
+	}
+}
+
+func (e *randomEviction) Removed(key interface{}) (found bool) {
+	i, found := e.index[key]
+	if found {
+		e.removeAt(i)
+	}
+	return
+}
+
+func (e *randomEviction) Hit(interface{}) {}
+
+func (e *randomEviction) Pop() (key interface{}) {
+	if len(e.keys) > 0 {
+		i := e.rand.Intn(len(e.keys))
+		key = e.keys[i]
+		e.removeAt(i)
+	}
+	return
+}
+
+func (e *randomEviction) removeAt(i int) {
+	n := len(e.keys) - 1
+	delete(e.index, e.keys[i])
+	if i != n {
+		e.keys[i] = e.keys[n]
+		e.index[e.keys[i]] = i
+	}
+	e.keys[n] = nil
+	e.keys = e.keys[:n]
+}
+
+func (e *randomEviction) String() string {
+	return fmt.Sprintf("Random(%d)", len(e.keys))
+}
+
 type countHeap struct {
 	index  map[interface{}]int
 	keys   []interface{}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -92,6 +93,55 @@ func TestEvictingCache(t *testing.T) {
 	}
 }
 
+func TestSizeEviction(t *testing.T) {
+
+	sizer := func(key, value interface{}) int64 {
+		return int64(len(value.(string)))
+	}
+
+	c := NewMemoryStorage(Spy(t.Logf), SizeEviction(10, sizer), Spy(t.Logf))
+
+	c.Put(1, "aaaa")
+	c.Put(2, "bbbb")
+	if c.Len() != 2 {
+		t.Error("Expected length 2")
+	}
+
+	c.Get(1)
+	c.Put(3, "cccc")
+	if c.Len() != 2 {
+		t.Error("Expected length 2")
+	}
+	if _, err := c.Get(2); err != ErrKeyNotFound {
+		t.Error("2 should have been evicted")
+	}
+
+	c.Put(3, "ccccccc")
+	if c.Len() != 1 {
+		t.Error("Expected length 1")
+	}
+	if _, err := c.Get(1); err != ErrKeyNotFound {
+		t.Error("1 should have been evicted")
+	}
+
+	if err := c.Put(4, "ddddddddddd"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected %v, got %v", ErrTooLarge, err)
+	}
+
+	c.Remove(3)
+	c.Put(5, "eeeee")
+	c.Put(6, "fffff")
+	if c.Len() != 2 {
+		t.Error("Expected length 2")
+	}
+
+	Clear(c)
+	c.Put(7, "gggggggggg")
+	if c.Len() != 1 {
+		t.Error("Expected length 1")
+	}
+}
+
 func TestLRUEviction(t *testing.T) {
 
 	e := NewLRUEviction()
@@ -154,3 +204,67 @@ func TestLFUEviction(t *testing.T) {
 		t.Fatalf("not empty when it should")
 	}
 }
+
+func TestSLRUEviction(t *testing.T) {
+
+	e := NewSLRUEviction(0.5)
+
+	for i := 1; i <= 4; i++ {
+		e.Added(i)
+	}
+
+	e.Hit(1)
+	e.Hit(2)
+	e.Hit(3)
+	e.Hit(5)
+
+	if !e.Removed(4) {
+		t.Fatalf("should be able to remove 4")
+	}
+	if e.Removed(6) {
+		t.Fatalf("should not be able to remove 6")
+	}
+
+	expectedOrder := []interface{}{1, 5, 2, 3}
+	for i, exp := range expectedOrder {
+		a := e.Pop()
+		t.Logf("Pop() => %v", a)
+		if a != exp {
+			t.Fatalf("Pop() mismatchs (step #%d), expected %v, got %v", i+1, exp, a)
+		}
+	}
+	if e.Pop() != nil {
+		t.Fatalf("not empty when it should")
+	}
+}
+
+func TestRandomEviction(t *testing.T) {
+
+	e := NewRandomEviction()
+
+	for i := 1; i <= 4; i++ {
+		e.Added(i)
+	}
+	e.Added(4)
+	e.Hit(5)
+
+	if !e.Removed(3) {
+		t.Fatalf("should be able to remove 3")
+	}
+	if e.Removed(6) {
+		t.Fatalf("should not be able to remove 6")
+	}
+
+	popped := make(map[interface{}]bool)
+	for i := 0; i < 3; i++ {
+		a := e.Pop()
+		t.Logf("Pop() => %v", a)
+		if a == nil || a == 3 || popped[a] {
+			t.Fatalf("Pop() returned an unexpected key (step #%d): %v", i+1, a)
+		}
+		popped[a] = true
+	}
+	if e.Pop() != nil {
+		t.Fatalf("not empty when it should")
+	}
+}
@@ -3,13 +3,66 @@ package cache
 import (
 	"encoding/gob"
 	"fmt"
+	"math/rand"
+	"sync"
 	"time"
 )
 
+// ExpiryCache is implemented by caches which can tell when their entries expire.
+// Expiration implements it, and the other options of this package forward it to their underlying cache.
+type ExpiryCache interface {
+	// GetWithExpiry fetchs an entry from the cache, with its expiration time.
+	GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error)
+
+	// TTL returns the time left before the entry expires.
+	TTL(key interface{}) (time.Duration, error)
+}
+
+// GetWithExpiry fetchs an entry from the cache, with its expiration time.
+// If the cache does not implement ExpiryCache, it uses Get and the expiration time is zero.
+func GetWithExpiry(c Cache, key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	if ec, ok := c.(ExpiryCache); ok {
+		return ec.GetWithExpiry(key)
+	}
+	value, err = c.Get(key)
+	return
+}
+
+// TTL returns the time left before the entry expires.
+// If the cache does not implement ExpiryCache, it uses Get and the time left is zero.
+// It returns ErrKeyNotFound if the entry is not present or has expired.
+func TTL(c Cache, key interface{}) (time.Duration, error) {
+	if ec, ok := c.(ExpiryCache); ok {
+		return ec.TTL(key)
+	}
+	_, err := c.Get(key)
+	return 0, err
+}
+
 type expiringCache struct {
 	Cache
 	Clock
-	ttl time.Duration
+	ttl    time.Duration
+	ttlFn  TTLFunc
+	jitter float64
+	drops  dropHooks
+	// touches holds the extended expiration times of sliding expiration layers, and is nil otherwise.
+	touches map[interface{}]time.Time
+	mu      sync.Mutex
+}
+
+// TTLFunc returns the lifetime of an entry.
+type TTLFunc func(key, value interface{}) time.Duration
+
+// ExpirationSetting customizes an expiration layer.
+type ExpirationSetting func(*expiringCache)
+
+// ExpirationJitter randomizes the lifetime of each entry by up to ±fraction (e.g. 0.1 for ±10%),
+// so entries stored at the same time do not expire, and get reloaded, at the same instant.
+func ExpirationJitter(fraction float64) ExpirationSetting {
+	return func(e *expiringCache) {
+		e.jitter = fraction
+	}
 }
 
 type expirableItem struct {
@@ -21,41 +74,194 @@ func init() {
 	gob.Register(expirableItem{})
 }
 
+// asExpirableItem accepts items decoded by gob-based storages, which are not pointers.
+func asExpirableItem(item interface{}) *expirableItem {
+	if it, ok := item.(expirableItem); ok {
+		return &it
+	}
+	return item.(*expirableItem)
+}
+
 // Expiration adds automatic expiration to new entries using the given delay.
-func Expiration(ttl time.Duration) Option {
-	return ExpirationUsingClock(ttl, RealClock)
+func Expiration(ttl time.Duration, settings ...ExpirationSetting) Option {
+	return ExpirationUsingClock(ttl, RealClock, settings...)
+}
+
+// ExpirationFunc adds automatic expiration to new entries, using f to select the delay of each entry.
+// It allows different kinds of entries to get different lifetimes, e.g. short ones for error placeholders.
+func ExpirationFunc(f TTLFunc, settings ...ExpirationSetting) Option {
+	return ExpirationFuncUsingClock(f, RealClock, settings...)
+}
+
+// ExpirationFuncUsingClock adds automatic expiration to new entries, using f and the given clock.
+func ExpirationFuncUsingClock(f TTLFunc, cl Clock, settings ...ExpirationSetting) Option {
+	return ExpirationUsingClock(0, cl, append(settings, func(e *expiringCache) {
+		e.ttlFn = f
+	})...)
+}
+
+// SlidingExpiration adds automatic expiration to entries which are not accessed during the given delay.
+// Each successful Get extends the lifetime of the entry by the delay.
+func SlidingExpiration(ttl time.Duration, settings ...ExpirationSetting) Option {
+	return SlidingExpirationUsingClock(ttl, RealClock, settings...)
+}
+
+// SlidingExpirationUsingClock adds sliding expiration using the given clock.
+func SlidingExpirationUsingClock(ttl time.Duration, cl Clock, settings ...ExpirationSetting) Option {
+	return ExpirationUsingClock(ttl, cl, append(settings, func(e *expiringCache) {
+		e.touches = make(map[interface{}]time.Time)
+	})...)
 }
 
 // ExpirationUsingClock adds automatic expiration to new entries using the given clock.
-func ExpirationUsingClock(ttl time.Duration, cl Clock) Option {
+func ExpirationUsingClock(ttl time.Duration, cl Clock, settings ...ExpirationSetting) Option {
 	return func(c Cache) Cache {
-		return &expiringCache{Cache: c, Clock: cl, ttl: ttl}
+		e := &expiringCache{Cache: c, Clock: cl, ttl: ttl}
+		for _, s := range settings {
+			s(e)
+		}
+		return e
 	}
 }
 
 func (e *expiringCache) Put(key, value interface{}) error {
-	return e.PutWithTTL(key, value, e.ttl)
+	return e.PutWithTTL(key, value, e.lifetime(key, value))
+}
+
+func (e *expiringCache) lifetime(key, value interface{}) time.Duration {
+	if e.ttlFn != nil {
+		return e.ttlFn(key, value)
+	}
+	return e.ttl
 }
 
 func (e *expiringCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
-	return e.Cache.Put(key, &expirableItem{value, e.Now().Add(ttl)})
+	return e.put(key, value, ttl, e.Cache.Put)
+}
+
+func (e *expiringCache) PutWithCost(key, value interface{}, cost int64) error {
+	return e.put(key, value, e.lifetime(key, value), func(key, item interface{}) error {
+		return PutWithCost(e.Cache, key, item, cost)
+	})
+}
+
+// put stores the value with its expiration time, using the given function.
+func (e *expiringCache) put(key, value interface{}, ttl time.Duration, put func(key, item interface{}) error) error {
+	if e.jitter > 0 {
+		ttl += time.Duration(float64(ttl) * e.jitter * (2*rand.Float64() - 1))
+	}
+	err := put(key, &expirableItem{value, e.Now().Add(ttl)})
+	e.untouch(key)
+	return err
 }
 
 func (e *expiringCache) Get(key interface{}) (interface{}, error) {
+	value, _, err := e.GetWithExpiry(key)
+	return value, err
+}
+
+func (e *expiringCache) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	return e.get(key, true)
+}
+
+func (e *expiringCache) get(key interface{}, touch bool) (interface{}, time.Time, error) {
 	item, err := e.Cache.Get(key)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
+	}
+	it := asExpirableItem(item)
+	now := e.Now()
+	expiresAt := e.expiresAt(key, it)
+	if expiresAt.Before(now) {
+		e.expire(key, it)
+		return nil, time.Time{}, ErrKeyNotFound
 	}
-	it := item.(*expirableItem)
-	if it.Expiration.Before(e.Now()) {
-		e.Cache.Remove(key)
-		return nil, ErrKeyNotFound
+	if touch && e.touches != nil {
+		expiresAt = now.Add(e.lifetime(key, it.Value))
+		e.mu.Lock()
+		e.touches[key] = expiresAt
+		e.mu.Unlock()
+	}
+	return it.Value, expiresAt, nil
+}
+
+func (e *expiringCache) TTL(key interface{}) (time.Duration, error) {
+	_, expiresAt, err := e.get(key, false)
+	if err != nil {
+		return 0, err
 	}
-	return it.Value, nil
+	return expiresAt.Sub(e.Now()), nil
+}
+
+func (e *expiringCache) Remove(key interface{}) bool {
+	e.untouch(key)
+	return e.Cache.Remove(key)
+}
+
+func (e *expiringCache) Range(f func(key, value interface{}) bool) error {
+	now := e.Now()
+	return Range(e.Cache, func(key, item interface{}) bool {
+		it := asExpirableItem(item)
+		if e.expiresAt(key, it).Before(now) {
+			e.expire(key, it)
+			return true
+		}
+		return f(key, it.Value)
+	})
+}
+
+// expiresAt returns the expiration time of the entry, taking the sliding expiration into account.
+func (e *expiringCache) expiresAt(key interface{}, it *expirableItem) time.Time {
+	if e.touches == nil {
+		return it.Expiration
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if t, found := e.touches[key]; found && t.After(it.Expiration) {
+		return t
+	}
+	return it.Expiration
+}
+
+func (e *expiringCache) untouch(key interface{}) {
+	if e.touches != nil {
+		e.mu.Lock()
+		delete(e.touches, key)
+		e.mu.Unlock()
+	}
+}
+
+func (e *expiringCache) expire(key interface{}, it *expirableItem) {
+	e.untouch(key)
+	if e.Cache.Remove(key) {
+		e.drops.dropped(EXPIRE, key, it.Value)
+	}
+}
+
+func (e *expiringCache) notifyDrops(f dropFunc) {
+	e.drops = append(e.drops, f)
+	notifyDrops(e.Cache, f)
+}
+
+func (e *expiringCache) Clear() error {
+	err := Clear(e.Cache)
+	if err == nil && e.touches != nil {
+		e.mu.Lock()
+		e.touches = make(map[interface{}]time.Time)
+		e.mu.Unlock()
+	}
+	return err
 }
 
 func (e *expiringCache) String() string {
-	return fmt.Sprintf("Expiring(%s,%s)", e.Cache, e.ttl)
+	var ttl interface{} = e.ttl
+	if e.ttlFn != nil {
+		ttl = e.ttlFn
+	}
+	if e.touches != nil {
+		return fmt.Sprintf("SlidingExpiring(%s,%v)", e.Cache, ttl)
+	}
+	return fmt.Sprintf("Expiring(%s,%v)", e.Cache, ttl)
 }
 
 // Clock is a simple clock abstraction to be used with ExpirationUsingClock.
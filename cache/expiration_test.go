@@ -55,3 +55,107 @@ func TestExpiringCache(t *testing.T) {
 		t.Error("Flush: expected <nil>")
 	}
 }
+
+func TestExpiringCacheExpiry(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(Spy(t.Logf), LRUEviction(10), ExpirationUsingClock(8*time.Second, &cl))
+
+	c.Put(5, 6)
+	cl.Advance(3 * time.Second)
+
+	if v, exp, err := GetWithExpiry(c, 5); v != 6 || !exp.Equal(time.Unix(8, 0)) || err != nil {
+		t.Errorf("GetWithExpiry: expected 6, %s, <nil>, got %v, %s, %v", time.Unix(8, 0), v, exp, err)
+	}
+
+	if ttl, err := TTL(c, 5); ttl != 5*time.Second || err != nil {
+		t.Errorf("TTL: expected 5s, <nil>, got %s, %v", ttl, err)
+	}
+
+	cl.Advance(6 * time.Second)
+
+	if ttl, err := TTL(c, 5); ttl != 0 || err != ErrKeyNotFound {
+		t.Errorf("TTL: expected 0, %v, got %s, %v", ErrKeyNotFound, ttl, err)
+	}
+}
+
+func TestExpirationJitter(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(ExpirationUsingClock(100*time.Second, &cl, ExpirationJitter(0.1)))
+
+	ttls := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		c.Put(i, i)
+		ttl, err := TTL(c, i)
+		if ttl < 90*time.Second || ttl > 110*time.Second || err != nil {
+			t.Fatalf("TTL: expected 100s±10%%, <nil>, got %s, %v", ttl, err)
+		}
+		ttls[ttl] = true
+	}
+	if len(ttls) < 2 {
+		t.Error("expected different TTLs")
+	}
+}
+
+func TestSlidingExpiration(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(Spy(t.Logf), SlidingExpirationUsingClock(5*time.Second, &cl))
+
+	c.Put(5, 6)
+	c.Put(7, 8)
+
+	for i := 0; i < 3; i++ {
+		cl.Advance(3 * time.Second)
+		if v, err := c.Get(5); v != 6 || err != nil {
+			t.Fatalf("Get: expected 6, <nil>, got %v, %v", v, err)
+		}
+	}
+
+	if ttl, err := TTL(c, 5); ttl != 5*time.Second || err != nil {
+		t.Errorf("TTL: expected 5s, <nil>, got %s, %v", ttl, err)
+	}
+
+	if v, err := c.Get(7); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	cl.Advance(6 * time.Second)
+
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+}
+
+func TestExpirationFunc(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(Spy(t.Logf), ExpirationFuncUsingClock(func(key, value interface{}) time.Duration {
+		if _, isErr := value.(error); isErr {
+			return 10 * time.Second
+		}
+		return 10 * time.Minute
+	}, &cl))
+
+	c.Put(5, 6)
+	c.Put(7, ErrKeyNotFound)
+
+	if ttl, err := TTL(c, 5); ttl != 10*time.Minute || err != nil {
+		t.Errorf("TTL: expected 10m, <nil>, got %s, %v", ttl, err)
+	}
+
+	if ttl, err := TTL(c, 7); ttl != 10*time.Second || err != nil {
+		t.Errorf("TTL: expected 10s, <nil>, got %s, %v", ttl, err)
+	}
+
+	cl.Advance(time.Minute)
+
+	if v, err := c.Get(5); v != 6 || err != nil {
+		t.Error("Get: expected 6, <nil>")
+	}
+
+	if v, err := c.Get(7); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FallbackConfig holds the configuration of FallbackWith.
+type FallbackConfig struct {
+	// Backups are tried in order when the previous caches fail.
+	Backups []Cache
+
+	// Promote copies the entries found in the backups back into the primary cache when it misses them,
+	// e.g. the ones written while it was failing. It costs a lookup in the backups for each miss.
+	Promote bool
+}
+
+// Fallback adds a layer which tries Get and Put on the underlying cache, then on the backups, in order,
+// as long as they return errors other than ErrKeyNotFound, e.g. because a remote cache is down.
+func Fallback(backups ...Cache) Option {
+	return FallbackWith(FallbackConfig{Backups: backups})
+}
+
+// FallbackWith adds a layer which falls back to the backup caches when the underlying one fails.
+//
+// Remove, Flush and Clear are applied to all caches, so stale entries are not served back from the backups.
+// Len and Range only use the underlying cache.
+func FallbackWith(conf FallbackConfig) Option {
+	return func(c Cache) Cache {
+		return &fallback{Cache: c, FallbackConfig: conf}
+	}
+}
+
+type fallback struct {
+	Cache
+	FallbackConfig
+}
+
+func (f *fallback) each(op func(c Cache) error) (err error) {
+	if err = op(f.Cache); err == nil || err == ErrKeyNotFound {
+		return
+	}
+	for _, b := range f.Backups {
+		if err = op(b); err == nil || err == ErrKeyNotFound {
+			return
+		}
+	}
+	return
+}
+
+func (f *fallback) Put(key, value interface{}) error {
+	return f.each(func(c Cache) error { return c.Put(key, value) })
+}
+
+func (f *fallback) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	return f.each(func(c Cache) error { return PutWithTTL(c, key, value, ttl) })
+}
+
+func (f *fallback) Get(key interface{}) (value interface{}, err error) {
+	value, err = f.Cache.Get(key)
+	if err == ErrKeyNotFound && f.Promote {
+		for _, b := range f.Backups {
+			if v, err := b.Get(key); err == nil {
+				f.Cache.Put(key, v)
+				return v, nil
+			}
+		}
+	}
+	if err == nil || err == ErrKeyNotFound {
+		return
+	}
+	for _, b := range f.Backups {
+		if value, err = b.Get(key); err == nil || err == ErrKeyNotFound {
+			return
+		}
+	}
+	return
+}
+
+func (f *fallback) PutWithCost(key, value interface{}, cost int64) error {
+	return f.each(func(c Cache) error { return PutWithCost(c, key, value, cost) })
+}
+
+// GetOrCompute falls back to the backups like Get, but the errors of the computation are returned as is.
+func (f *fallback) GetOrCompute(key interface{}, compute ComputeFunc) (value interface{}, err error) {
+	var computeErr error
+	fn := func() (v interface{}, err error) {
+		v, err = compute()
+		computeErr = err
+		return
+	}
+	first := fn
+	if f.Promote {
+		first = func() (interface{}, error) {
+			for _, b := range f.Backups {
+				if v, err := b.Get(key); err == nil {
+					return v, nil
+				}
+			}
+			return fn()
+		}
+	}
+	if value, err = GetOrCompute(f.Cache, key, first); err == nil || computeErr != nil {
+		return
+	}
+	for _, b := range f.Backups {
+		if value, err = GetOrCompute(b, key, fn); err == nil || computeErr != nil {
+			return
+		}
+	}
+	return
+}
+
+func (f *fallback) Remove(key interface{}) (removed bool) {
+	removed = f.Cache.Remove(key)
+	for _, b := range f.Backups {
+		removed = b.Remove(key) || removed
+	}
+	return
+}
+
+func (f *fallback) Flush() (err error) {
+	err = f.Cache.Flush()
+	for _, b := range f.Backups {
+		if berr := b.Flush(); err == nil {
+			err = berr
+		}
+	}
+	return
+}
+
+func (f *fallback) Clear() (err error) {
+	err = Clear(f.Cache)
+	for _, b := range f.Backups {
+		if berr := Clear(b); err == nil {
+			err = berr
+		}
+	}
+	return
+}
+
+func (f *fallback) Range(fn func(key, value interface{}) bool) error {
+	return Range(f.Cache, fn)
+}
+
+func (f *fallback) notifyDrops(fn dropFunc) {
+	notifyDrops(f.Cache, fn)
+	for _, b := range f.Backups {
+		notifyDrops(b, fn)
+	}
+}
+
+func (f *fallback) String() string {
+	names := make([]string, len(f.Backups)+1)
+	names[0] = f.Cache.String()
+	for i, b := range f.Backups {
+		names[i+1] = b.String()
+	}
+	return fmt.Sprintf("Fallback(%s)", strings.Join(names, ","))
+}
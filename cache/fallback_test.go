@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+var errDown = errors.New("down")
+
+type failingCache struct {
+	Cache
+	down bool
+}
+
+func (f *failingCache) Put(key, value interface{}) error {
+	if f.down {
+		return errDown
+	}
+	return f.Cache.Put(key, value)
+}
+
+func (f *failingCache) Get(key interface{}) (interface{}, error) {
+	if f.down {
+		return nil, errDown
+	}
+	return f.Cache.Get(key)
+}
+
+func TestFallback(t *testing.T) {
+
+	primary := &failingCache{Cache: NewMemoryStorage()}
+	backup := NewMemoryStorage()
+	c := options{Spy(t.Logf), Fallback(backup)}.applyTo(primary)
+
+	c.Put(1, 10)
+	if v, err := backup.Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("backup.Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+
+	primary.down = true
+	if err := c.Put(2, 20); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	if v, err := c.Get(2); v != 20 || err != nil {
+		t.Errorf("Get: expected 20, <nil>, got %v, %v", v, err)
+	}
+	if v, err := c.Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+
+	primary.down = false
+	if v, err := c.Get(2); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+
+	if !c.Remove(2) {
+		t.Error("Remove: expected true")
+	}
+	if backup.Len() != 0 {
+		t.Error("Remove: expected an empty backup")
+	}
+}
+
+func TestFallbackPromote(t *testing.T) {
+
+	primary := &failingCache{Cache: NewMemoryStorage()}
+	backup := NewMemoryStorage()
+	c := FallbackWith(FallbackConfig{Backups: []Cache{backup}, Promote: true})(primary)
+
+	primary.down = true
+	c.Put(1, 10)
+	primary.down = false
+
+	if v, err := c.Get(1); v != 10 || err != nil {
+		t.Errorf("Get: expected 10, <nil>, got %v, %v", v, err)
+	}
+	if v, err := primary.Get(1); v != 10 || err != nil {
+		t.Errorf("primary.Get: expected 10, <nil>, got %v, %v", v, err)
+	}
+}
+
+func TestFallbackGetOrCompute(t *testing.T) {
+
+	primary := &failingCache{Cache: NewMemoryStorage()}
+	backup := NewMemoryStorage()
+	c := FallbackWith(FallbackConfig{Backups: []Cache{backup}, Promote: true})(primary)
+
+	calls := 0
+	compute := func() (interface{}, error) {
+		calls++
+		return 10, nil
+	}
+
+	primary.down = true
+	if v, err := GetOrCompute(c, 1, compute); v != 10 || err != nil {
+		t.Errorf("GetOrCompute: expected 10, <nil>, got %v, %v", v, err)
+	}
+	primary.down = false
+	if v, err := GetOrCompute(c, 1, compute); v != 10 || err != nil || calls != 1 {
+		t.Errorf("GetOrCompute: expected 10, <nil> from the backup, got %v, %v after %d calls", v, err, calls)
+	}
+
+	fail := func() (interface{}, error) {
+		calls++
+		return nil, errDown
+	}
+	calls = 0
+	if _, err := GetOrCompute(c, 2, fail); err != errDown || calls != 1 {
+		t.Errorf("GetOrCompute: expected %v after 1 call, got %v after %d calls", errDown, err, calls)
+	}
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// HashKeys adds a layer which replaces the keys using the given function, so arbitrarily long or non-hashable
+// keys (e.g. slices) can be used with the underlying storages. It defaults to the 64-bit FNV-1a hash of the
+// gob-encoded keys.
+//
+// Entries whose keys have the same hash overwrite each other. Range returns the hashed keys.
+func HashKeys(h func(interface{}) interface{}) Option {
+	hash := func(key interface{}) (interface{}, error) { return h(key), nil }
+	if h == nil {
+		hash = fnvKey
+	}
+	return func(c Cache) Cache {
+		return &hashedKeys{c, hash}
+	}
+}
+
+func fnvKey(key interface{}) (interface{}, error) {
+	data, err := gobEncode(key)
+	if err != nil {
+		return nil, err
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64(), nil
+}
+
+// hashedKeys replaces the keys by the result of hash before passing them to the underlying cache.
+type hashedKeys struct {
+	Cache
+	hash func(key interface{}) (interface{}, error)
+}
+
+func (c *hashedKeys) Put(key, value interface{}) error {
+	k, err := c.hash(key)
+	if err != nil {
+		return err
+	}
+	return c.Cache.Put(k, value)
+}
+
+func (c *hashedKeys) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	k, err := c.hash(key)
+	if err != nil {
+		return err
+	}
+	return PutWithTTL(c.Cache, k, value, ttl)
+}
+
+func (c *hashedKeys) PutWithCost(key, value interface{}, cost int64) error {
+	k, err := c.hash(key)
+	if err != nil {
+		return err
+	}
+	return PutWithCost(c.Cache, k, value, cost)
+}
+
+func (c *hashedKeys) GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error) {
+	k, err := c.hash(key)
+	if err != nil {
+		return nil, err
+	}
+	return GetOrCompute(c.Cache, k, f)
+}
+
+func (c *hashedKeys) Get(key interface{}) (interface{}, error) {
+	k, err := c.hash(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.Cache.Get(k)
+}
+
+func (c *hashedKeys) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	k, err := c.hash(key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return GetWithExpiry(c.Cache, k)
+}
+
+func (c *hashedKeys) TTL(key interface{}) (time.Duration, error) {
+	k, err := c.hash(key)
+	if err != nil {
+		return 0, err
+	}
+	return TTL(c.Cache, k)
+}
+
+func (c *hashedKeys) Remove(key interface{}) bool {
+	k, err := c.hash(key)
+	return err == nil && c.Cache.Remove(k)
+}
+
+func (c *hashedKeys) Clear() error {
+	return Clear(c.Cache)
+}
+
+func (c *hashedKeys) Range(f func(key, value interface{}) bool) error {
+	return Range(c.Cache, f)
+}
+
+func (c *hashedKeys) notifyDrops(f dropFunc) {
+	notifyDrops(c.Cache, f)
+}
+
+func (c *hashedKeys) String() string {
+	return fmt.Sprintf("HashedKeys(%s)", c.Cache)
+}
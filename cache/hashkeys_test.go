@@ -0,0 +1,35 @@
+package cache
+
+import "testing"
+
+func TestHashKeys(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(t.Logf), HashKeys(nil))
+
+	if err := c.Put([]string{"a", "b"}, 1); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	c.Put([]string{"a", "c"}, 2)
+
+	if v, err := c.Get([]string{"a", "b"}); v != 1 || err != nil {
+		t.Errorf("Get: expected 1, <nil>, got %v, %v", v, err)
+	}
+	if !c.Remove([]string{"a", "c"}) {
+		t.Error("Remove: expected true")
+	}
+	if v := c.Len(); v != 1 {
+		t.Errorf("Len: expected 1, got %d", v)
+	}
+
+	c = NewMemoryStorage(HashKeys(nil), SingleFlight)
+	compute := func() (interface{}, error) { return 3, nil }
+	if v, err := GetOrCompute(c, []string{"a", "d"}, compute); v != 3 || err != nil {
+		t.Errorf("GetOrCompute: expected 3, <nil>, got %v, %v", v, err)
+	}
+
+	c = NewMemoryStorage(HashKeys(func(key interface{}) interface{} { return len(key.([]int)) }))
+	c.Put([]int{1, 2}, 1)
+	if v, err := c.Get([]int{3, 4}); v != 1 || err != nil {
+		t.Errorf("Get: expected 1, <nil>, got %v, %v", v, err)
+	}
+}
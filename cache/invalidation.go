@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+)
+
+// InvalidationOp is the type of an invalidation.
+type InvalidationOp uint8
+
+// InvalidationOp values
+const (
+	// InvalidateKey invalidates one entry.
+	InvalidateKey InvalidationOp = iota + 1
+
+	// InvalidateAll invalidates all the entries.
+	InvalidateAll
+)
+
+// InvalidationMessage is sent over a Bus to invalidate entries in the caches of the other processes.
+type InvalidationMessage struct {
+	// Origin identifies the sending cache, which ignores its own messages.
+	Origin string
+
+	// Op is the type of invalidation.
+	Op InvalidationOp
+
+	// Key is the gob-encoded key of the entry (InvalidateKey).
+	Key []byte
+}
+
+// Bus broadcasts InvalidationMessages to all the subscribers, including the ones of the sending process.
+type Bus interface {
+	// Publish sends a message to all the subscribers.
+	Publish(msg InvalidationMessage) error
+
+	// Subscribe registers a handler called for each message, until unsubscribe is called.
+	Subscribe(handler func(InvalidationMessage)) (unsubscribe func(), err error)
+}
+
+// Invalidation adds a layer broadcasting the invalidations of entries over a bus, and applying the invalidations
+// received from the other processes to the underlying cache, e.g. to keep a WriteThrough outer layer consistent.
+//
+// Put and Remove invalidate the entry, Clear all entries. Keys are gob-encoded: their concrete types must be
+// registered using gob.Register, unless they are basic types. A failure to subscribe is reported by Flush.
+func Invalidation(bus Bus) Option {
+	return func(c Cache) Cache {
+		var id [8]byte
+		rand.Read(id[:])
+		inv := &invalidatingCache{Cache: c, bus: bus, origin: hex.EncodeToString(id[:])}
+		_, inv.err = bus.Subscribe(inv.receive)
+		return inv
+	}
+}
+
+type invalidatingCache struct {
+	Cache
+	bus    Bus
+	origin string
+	err    error
+}
+
+func (c *invalidatingCache) receive(msg InvalidationMessage) {
+	if msg.Origin == c.origin {
+		return
+	}
+	switch msg.Op {
+	case InvalidateKey:
+		if key, err := gobDecode(msg.Key); err == nil {
+			c.Cache.Remove(key)
+		}
+	case InvalidateAll:
+		Clear(c.Cache)
+	}
+}
+
+func (c *invalidatingCache) publish(op InvalidationOp, key interface{}) error {
+	msg := InvalidationMessage{Origin: c.origin, Op: op}
+	if op == InvalidateKey {
+		k, err := gobEncode(key)
+		if err != nil {
+			return err
+		}
+		msg.Key = k
+	}
+	return c.bus.Publish(msg)
+}
+
+func (c *invalidatingCache) Put(key, value interface{}) error {
+	if err := c.Cache.Put(key, value); err != nil {
+		return err
+	}
+	return c.publish(InvalidateKey, key)
+}
+
+func (c *invalidatingCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	if err := PutWithTTL(c.Cache, key, value, ttl); err != nil {
+		return err
+	}
+	return c.publish(InvalidateKey, key)
+}
+
+func (c *invalidatingCache) PutWithCost(key, value interface{}, cost int64) error {
+	if err := PutWithCost(c.Cache, key, value, cost); err != nil {
+		return err
+	}
+	return c.publish(InvalidateKey, key)
+}
+
+func (c *invalidatingCache) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	return GetWithExpiry(c.Cache, key)
+}
+
+func (c *invalidatingCache) TTL(key interface{}) (time.Duration, error) {
+	return TTL(c.Cache, key)
+}
+
+func (c *invalidatingCache) Remove(key interface{}) bool {
+	removed := c.Cache.Remove(key)
+	c.publish(InvalidateKey, key)
+	return removed
+}
+
+func (c *invalidatingCache) Clear() error {
+	if err := Clear(c.Cache); err != nil {
+		return err
+	}
+	return c.publish(InvalidateAll, nil)
+}
+
+func (c *invalidatingCache) Flush() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.Cache.Flush()
+}
+
+func (c *invalidatingCache) Range(f func(key, value interface{}) bool) error {
+	return Range(c.Cache, f)
+}
+
+func (c *invalidatingCache) notifyDrops(f dropFunc) {
+	notifyDrops(c.Cache, f)
+}
+
+func (c *invalidatingCache) String() string {
+	return fmt.Sprintf("Invalidation(%s,%s)", c.Cache, c.origin)
+}
+
+// LocalBus is an in-process Bus, e.g. for several caches sharing an outer layer.
+type LocalBus struct {
+	mu       sync.RWMutex
+	handlers map[int]func(InvalidationMessage)
+	next     int
+}
+
+// NewLocalBus creates an in-process Bus.
+func NewLocalBus() *LocalBus {
+	return &LocalBus{handlers: make(map[int]func(InvalidationMessage))}
+}
+
+// Publish implements Bus. The handlers are called synchronously.
+func (b *LocalBus) Publish(msg InvalidationMessage) error {
+	b.mu.RLock()
+	handlers := make([]func(InvalidationMessage), 0, len(b.handlers))
+	for _, h := range b.handlers {
+		handlers = append(handlers, h)
+	}
+	b.mu.RUnlock()
+	for _, h := range handlers {
+		h(msg)
+	}
+	return nil
+}
+
+// Subscribe implements Bus.
+func (b *LocalBus) Subscribe(handler func(InvalidationMessage)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}, nil
+}
+
+func encodeInvalidation(msg InvalidationMessage) ([]byte, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(msg)
+	return b.Bytes(), err
+}
+
+func decodeInvalidation(data []byte) (msg InvalidationMessage, err error) {
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&msg)
+	return
+}
+
+// NewRedisBus creates a Bus using a Redis pub/sub channel.
+func NewRedisBus(client redis.UniversalClient, channel string) Bus {
+	return &redisBus{client, channel}
+}
+
+type redisBus struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+func (b *redisBus) Publish(msg InvalidationMessage) error {
+	data, err := encodeInvalidation(msg)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(context.Background(), b.channel, data).Err()
+}
+
+func (b *redisBus) Subscribe(handler func(InvalidationMessage)) (func(), error) {
+	ctx := context.Background()
+	ps := b.client.Subscribe(ctx, b.channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return nil, err
+	}
+	go func() {
+		for m := range ps.Channel() {
+			if msg, err := decodeInvalidation([]byte(m.Payload)); err == nil {
+				handler(msg)
+			}
+		}
+	}()
+	return func() { ps.Close() }, nil
+}
+
+// NewNATSBus creates a Bus using a NATS subject.
+func NewNATSBus(conn *nats.Conn, subject string) Bus {
+	return &natsBus{conn, subject}
+}
+
+type natsBus struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func (b *natsBus) Publish(msg InvalidationMessage) error {
+	data, err := encodeInvalidation(msg)
+	if err != nil {
+		return err
+	}
+	return b.conn.Publish(b.subject, data)
+}
+
+func (b *natsBus) Subscribe(handler func(InvalidationMessage)) (func(), error) {
+	sub, err := b.conn.Subscribe(b.subject, func(m *nats.Msg) {
+		if msg, err := decodeInvalidation(m.Data); err == nil {
+			handler(msg)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() { sub.Unsubscribe() }, nil
+}
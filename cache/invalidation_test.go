@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvalidation(t *testing.T) {
+
+	bus := NewLocalBus()
+	a := NewMemoryStorage(Spy(t.Logf), Invalidation(bus), Name("a"))
+	b := NewMemoryStorage(Spy(t.Logf), Invalidation(bus), Name("b"))
+
+	a.Put(5, 6)
+	b.Put(5, 6)
+
+	if v, err := a.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected a to be invalidated by b, got %v, %v", v, err)
+	}
+
+	if v, err := b.Get(5); v != 6 || err != nil {
+		t.Error("Get: expected 6, <nil>")
+	}
+
+	a.Put(6, 7)
+	b.Remove(5)
+	b.Put(7, 8)
+
+	if err := Clear(b); err != nil {
+		t.Errorf("Clear: unexpected error %v", err)
+	}
+
+	if v := a.Len(); v != 0 {
+		t.Errorf("Len: expected a to be cleared, got %d", v)
+	}
+}
+
+func TestInvalidationTTL(t *testing.T) {
+
+	bus := NewLocalBus()
+	a := NewMemoryStorage(Invalidation(bus), Expiration(time.Hour))
+	b := NewMemoryStorage(Invalidation(bus), Expiration(time.Hour))
+
+	a.Put(5, 6)
+	if err := PutWithTTL(b, 5, 6, time.Minute); err != nil {
+		t.Errorf("PutWithTTL: unexpected error %v", err)
+	}
+	if v, err := a.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected a to be invalidated by b, got %v, %v", v, err)
+	}
+	if ttl, err := TTL(b, 5); ttl > time.Minute || err != nil {
+		t.Errorf("TTL: expected at most 1m0s, <nil>, got %v, %v", ttl, err)
+	}
+}
+
+func TestInvalidationMessageEncoding(t *testing.T) {
+
+	msg := InvalidationMessage{Origin: "a", Op: InvalidateKey, Key: []byte{1, 2}}
+	data, err := encodeInvalidation(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded, err := decodeInvalidation(data); err != nil || decoded.Origin != "a" || decoded.Op != InvalidateKey || len(decoded.Key) != 2 {
+		t.Errorf("decodeInvalidation: expected %v, got %v, %v", msg, decoded, err)
+	}
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotIterable is returned by Range and Keys when a cache does not support iteration.
+var ErrNotIterable = errors.New("cache is not iterable")
+
+// Iterable is implemented by caches which can enumerate their entries.
+// The storages and the options of this package implement it, as long as their underlying cache does.
+type Iterable interface {
+	// Range calls f for each entry of the cache, until f returns false.
+	// The entries may be modified during the iteration, which works on a snapshot or skips the missing entries.
+	Range(f func(key, value interface{}) bool) error
+}
+
+// Range calls f for each entry of the cache, until f returns false.
+// It returns an error wrapping ErrNotIterable if the cache does not implement Iterable.
+func Range(c Cache, f func(key, value interface{}) bool) error {
+	if it, ok := c.(Iterable); ok {
+		return it.Range(f)
+	}
+	return fmt.Errorf("%w: %s", ErrNotIterable, c)
+}
+
+// Keys returns the keys of the entries of the cache.
+// It returns an error wrapping ErrNotIterable if the cache does not implement Iterable.
+func Keys(c Cache) (keys []interface{}, err error) {
+	err = Range(c, func(key, _ interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func sortedKeys(t *testing.T, c Cache) []int {
+	keys, err := Keys(c)
+	if err != nil {
+		t.Fatalf("Keys: unexpected error %v", err)
+	}
+	ints := make([]int, len(keys))
+	for i, k := range keys {
+		ints[i] = k.(int)
+	}
+	sort.Ints(ints)
+	return ints
+}
+
+func TestMemoryStorageRange(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(t.Logf), Name("test"))
+	for i := 1; i <= 3; i++ {
+		c.Put(i, i*10)
+	}
+
+	if keys := sortedKeys(t, c); len(keys) != 3 || keys[0] != 1 || keys[2] != 3 {
+		t.Errorf("Keys: expected [1 2 3], got %v", keys)
+	}
+
+	sum := 0
+	if err := Range(c, func(key, value interface{}) bool {
+		sum += value.(int)
+		c.Remove(key)
+		return true
+	}); err != nil {
+		t.Errorf("Range: unexpected error %v", err)
+	}
+	if sum != 60 {
+		t.Errorf("Range: expected a sum of 60, got %d", sum)
+	}
+	if c.Len() != 0 {
+		t.Error("Len: expected 0")
+	}
+}
+
+func TestRangeStop(t *testing.T) {
+
+	c := NewMemoryStorage()
+	for i := 1; i <= 3; i++ {
+		c.Put(i, i)
+	}
+
+	n := 0
+	Range(c, func(key, value interface{}) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Range: expected 1 call, got %d", n)
+	}
+}
+
+func TestExpiringCacheRange(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(ExpirationUsingClock(time.Second, &cl))
+	c.Put(1, "one")
+	cl.Advance(2 * time.Second)
+	c.Put(2, "two")
+
+	var values []interface{}
+	Range(c, func(key, value interface{}) bool {
+		values = append(values, value)
+		return true
+	})
+	if len(values) != 1 || values[0] != "two" {
+		t.Errorf("Range: expected [two], got %v", values)
+	}
+	if c.Len() != 1 {
+		t.Error("Len: expected 1")
+	}
+}
+
+func TestNotIterable(t *testing.T) {
+
+	c := struct{ Cache }{NewMemoryStorage()}
+
+	if _, err := Keys(c); !errors.Is(err, ErrNotIterable) {
+		t.Errorf("Keys: expected %v, got %v", ErrNotIterable, err)
+	}
+}
+
+func TestTypedRange(t *testing.T) {
+
+	c := NewTypedMemoryStorage[int, string]()
+	c.Put(1, "one")
+
+	if keys := sortedKeys(t, Untyped(c)); len(keys) != 1 || keys[0] != 1 {
+		t.Errorf("Keys: expected [1], got %v", keys)
+	}
+
+	u := NewMemoryStorage()
+	u.Put(1, "one")
+	u.Put(2, 2)
+
+	var values []string
+	Typed[int, string](u).(TypedIterable[int, string]).Range(func(key int, value string) bool {
+		values = append(values, value)
+		return true
+	})
+	if len(values) != 1 || values[0] != "one" {
+		t.Errorf("Range: expected [one], got %v", values)
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Locking adds a layer which serializes all the operations using a mutex, e.g. to use a storage which is not safe
+// for concurrent use.
+func Locking() Option {
+	return func(c Cache) Cache {
+		l := &lockingCache{Cache: c}
+		l.rmu = &l.mu
+		return l
+	}
+}
+
+// RLocking adds a layer like Locking, but which lets Get, GetWithExpiry, TTL, Len and Range run concurrently, so
+// read-heavy workloads do not serialize.
+//
+// The underlying cache must support concurrent reads: RLocking should be listed after the layers which write on Get,
+// like Loader or WriteThrough, so these writes take the exclusive lock. The options of this package which update
+// their own state on Get, like LRU eviction or sliding expiration, are safe for concurrent use.
+func RLocking() Option {
+	return func(c Cache) Cache {
+		l := &lockingCache{Cache: c}
+		l.rmu = l.mu.RLocker()
+		return l
+	}
+}
+
+type lockingCache struct {
+	Cache
+	mu sync.RWMutex
+	// rmu is used by the read operations: it is either mu or its read locker.
+	rmu sync.Locker
+}
+
+func (l *lockingCache) Put(key, value interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Cache.Put(key, value)
+}
+
+func (l *lockingCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return PutWithTTL(l.Cache, key, value, ttl)
+}
+
+func (l *lockingCache) PutWithCost(key, value interface{}, cost int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return PutWithCost(l.Cache, key, value, cost)
+}
+
+func (l *lockingCache) Get(key interface{}) (interface{}, error) {
+	l.rmu.Lock()
+	defer l.rmu.Unlock()
+	return l.Cache.Get(key)
+}
+
+func (l *lockingCache) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	l.rmu.Lock()
+	defer l.rmu.Unlock()
+	return GetWithExpiry(l.Cache, key)
+}
+
+func (l *lockingCache) TTL(key interface{}) (time.Duration, error) {
+	l.rmu.Lock()
+	defer l.rmu.Unlock()
+	return TTL(l.Cache, key)
+}
+
+// GetOrCompute holds the exclusive lock while computing the value, so it is computed only once. f must not use the
+// cache.
+func (l *lockingCache) GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return GetOrCompute(l.Cache, key, f)
+}
+
+func (l *lockingCache) Remove(key interface{}) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Cache.Remove(key)
+}
+
+func (l *lockingCache) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Cache.Flush()
+}
+
+func (l *lockingCache) Len() int {
+	l.rmu.Lock()
+	defer l.rmu.Unlock()
+	return l.Cache.Len()
+}
+
+func (l *lockingCache) Clear() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Clear(l.Cache)
+}
+
+func (l *lockingCache) Range(f func(key, value interface{}) bool) error {
+	// Copy the entries first, so f can use the cache.
+	var keys, values []interface{}
+	l.rmu.Lock()
+	err := Range(l.Cache, func(key, value interface{}) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	l.rmu.Unlock()
+	if err != nil {
+		return err
+	}
+	for i, key := range keys {
+		if !f(key, values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+func (l *lockingCache) notifyDrops(f dropFunc) {
+	notifyDrops(l.Cache, f)
+}
+
+func (l *lockingCache) String() string {
+	if l.rmu == &l.mu {
+		return fmt.Sprintf("Locking(%s)", l.Cache)
+	}
+	return fmt.Sprintf("RLocking(%s)", l.Cache)
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// mapStorage is a storage which is not safe for concurrent use.
+type mapStorage map[interface{}]interface{}
+
+func (m mapStorage) Put(key, value interface{}) error { m[key] = value; return nil }
+func (m mapStorage) Flush() error                     { return nil }
+func (m mapStorage) Len() int                         { return len(m) }
+func (m mapStorage) String() string                   { return fmt.Sprintf("Map(%p)", m) }
+
+func (m mapStorage) Get(key interface{}) (interface{}, error) {
+	if value, found := m[key]; found {
+		return value, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (m mapStorage) Remove(key interface{}) bool {
+	_, found := m[key]
+	delete(m, key)
+	return found
+}
+
+func testLocking(t *testing.T, opt Option) {
+
+	c := options{Spy(t.Logf), opt}.applyTo(mapStorage{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if j%10 == 0 {
+					c.Put(i, j)
+				} else if j%10 == 5 {
+					GetOrCompute(c, i, func() (interface{}, error) { return j, nil })
+				} else {
+					c.Get(i)
+				}
+			}
+			c.Remove(i)
+		}(i)
+	}
+	wg.Wait()
+
+	if v := c.Len(); v != 0 {
+		t.Errorf("Len: expected 0, got %d", v)
+	}
+}
+
+func TestLocking(t *testing.T) {
+	testLocking(t, Locking())
+}
+
+func TestRLocking(t *testing.T) {
+	testLocking(t, RLocking())
+}
+
+func benchmarkReadHeavy(b *testing.B, opt Option) {
+	c := opt(mapStorage{})
+	for i := 0; i < 64; i++ {
+		c.Put(i, i)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if i%10 == 0 {
+				c.Put(i%64, i)
+			} else {
+				c.Get(i % 64)
+			}
+		}
+	})
+}
+
+func BenchmarkLocking_ReadHeavy(b *testing.B) {
+	benchmarkReadHeavy(b, Locking())
+}
+
+func BenchmarkRLocking_ReadHeavy(b *testing.B) {
+	benchmarkReadHeavy(b, RLocking())
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// Field names of the entries logged by SpyLogger and LogErrorsTo.
+const (
+	OperationKey = "operation"
+	KeyKey       = "key"
+)
+
+// SpyLogger logs operations as structured entries at the given level, with the operation, the key,
+// the duration and the error, if any.
+func SpyLogger(l logging.Logger, level zapcore.Level) Option {
+	return func(c Cache) Cache {
+		return &spy{Cache: c, l: l, level: level}
+	}
+}
+
+// LogErrorsTo catchs and logs errors as structured entries, with the operation and the key.
+func LogErrorsTo(l logging.Logger) Option {
+	return func(c Cache) Cache {
+		return &errorLogger{Cache: c, l: l}
+	}
+}
+
+func (s *spy) log(start time.Time, op string, key interface{}, err error, format string, args ...interface{}) {
+	if s.f != nil {
+		s.f(format, args...)
+		return
+	}
+	fields := append(operationFields(s.Cache, op, key), logging.DurationKey, time.Since(start).String())
+	fields = append(fields, logging.ErrorFields(err)...)
+	switch {
+	case s.level <= logging.DebugLevel:
+		s.l.Debugw("cache operation", fields...)
+	case s.level == logging.InfoLevel:
+		s.l.Infow("cache operation", fields...)
+	case s.level == logging.WarnLevel:
+		s.l.Warnw("cache operation", fields...)
+	default:
+		s.l.Errorw("cache operation", fields...)
+	}
+}
+
+func (c *errorLogger) log(op string, key interface{}, err error, format string, args ...interface{}) {
+	if c.f != nil {
+		c.f(format, args...)
+		return
+	}
+	c.l.ErrorE(err, "cache operation failed", operationFields(c.Cache, op, key)...)
+}
+
+func operationFields(c Cache, op string, key interface{}) []interface{} {
+	fields := []interface{}{logging.CacheKey, c.String(), OperationKey, op}
+	if key != nil {
+		fields = append(fields, KeyKey, key)
+	}
+	return fields
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+func TestSpyLogger(t *testing.T) {
+
+	c := NewMemoryStorage(SpyLogger(logging.NewTesting(t), logging.DebugLevel))
+
+	if err := c.Put(5, 6); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	if v, err := c.Get(5); v != 6 || err != nil {
+		t.Errorf("Get: expected 6, <nil>, got %v, %v", v, err)
+	}
+	if v, err := c.Get(6); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+}
+
+func TestLogErrorsTo(t *testing.T) {
+
+	f := &failingCache{Cache: NewMemoryStorage(), down: true}
+	c := LogErrorsTo(logging.NewTesting(t))(f)
+
+	if err := c.Put(5, 6); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgpackSerializer uses MessagePack, which is faster and denser than gob, especially for small values.
+// As it does not record the Go types, the values are unserialized as basic types, maps and slices;
+// NewMsgpackSerializer returns values of a given type.
+var MsgpackSerializer Serializer = msgpackSerializer[interface{}]{}
+
+// NewMsgpackSerializer creates a MessagePack serializer which unserializes values of type V.
+//
+// The values implementing the interfaces generated by tinylib/msgp (MarshalMsg and UnmarshalMsg)
+// are serialized using them.
+func NewMsgpackSerializer[V any]() Serializer {
+	return msgpackSerializer[V]{}
+}
+
+type msgpackSerializer[V any] struct{}
+
+type msgpMarshaler interface {
+	MarshalMsg([]byte) ([]byte, error)
+}
+
+type msgpUnmarshaler interface {
+	UnmarshalMsg([]byte) ([]byte, error)
+}
+
+func (msgpackSerializer[V]) Serialize(value interface{}) ([]byte, error) {
+	if m, ok := value.(msgpMarshaler); ok {
+		return m.MarshalMsg(nil)
+	}
+	return msgpack.Marshal(value)
+}
+
+func (msgpackSerializer[V]) Unserialize(data []byte) (interface{}, error) {
+	var value V
+	if u, ok := interface{}(&value).(msgpUnmarshaler); ok {
+		_, err := u.UnmarshalMsg(data)
+		return value, err
+	}
+	err := msgpack.Unmarshal(data, &value)
+	return value, err
+}
+
+func (msgpackSerializer[V]) String() string {
+	return "msgpack"
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"encoding/gob"
+	"testing"
+)
+
+type serializedStruct struct {
+	Name  string
+	Count int
+	Tags  []string
+}
+
+func init() {
+	gob.Register(serializedStruct{})
+}
+
+var serializedValue = serializedStruct{"value", 42, []string{"a", "b"}}
+
+func TestMsgpackSerializer(t *testing.T) {
+
+	c := NewMemoryStorage(Serialization(NewMsgpackSerializer[serializedStruct]()))
+
+	if err := c.Put(5, serializedValue); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	v, err := c.Get(5)
+	if s, ok := v.(serializedStruct); !ok || s.Name != "value" || s.Count != 42 || len(s.Tags) != 2 || err != nil {
+		t.Errorf("Get: expected %v, <nil>, got %v, %v", serializedValue, v, err)
+	}
+
+	gobData, _ := GobSerializer.Serialize(serializedValue)
+	msgpackData, _ := MsgpackSerializer.Serialize(serializedValue)
+	t.Logf("gob: %d bytes, msgpack: %d bytes", len(gobData), len(msgpackData))
+	if len(msgpackData) >= len(gobData) {
+		t.Error("expected msgpack to be denser than gob")
+	}
+}
+
+func benchmarkSerializer(b *testing.B, s Serializer) {
+	for i := 0; i < b.N; i++ {
+		data, err := s.Serialize(serializedValue)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := s.Unserialize(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGobSerializer(b *testing.B) {
+	benchmarkSerializer(b, GobSerializer)
+}
+
+func BenchmarkMsgpackSerializer(b *testing.B) {
+	benchmarkSerializer(b, NewMsgpackSerializer[serializedStruct]())
+}
@@ -1,6 +1,14 @@
 package cache
 
-import "fmt"
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/Adirelle/go-libs/logging"
+)
 
 // Printf is a printf-like function to be used with Spy()
 type Printf func(string, ...interface{})
@@ -8,60 +16,137 @@ type Printf func(string, ...interface{})
 type spy struct {
 	Cache
 	f Printf
+	// l and level are used by SpyLogger, when f is nil.
+	l     logging.Logger
+	level zapcore.Level
 }
 
 // Spy logs operations using the given function.
 func Spy(f Printf) Option {
 	return func(c Cache) Cache {
-		return &spy{c, f}
+		return &spy{Cache: c, f: f}
 	}
 }
 
 func (s *spy) Put(key, value interface{}) (err error) {
+	start := time.Now()
 	err = s.Cache.Put(key, value)
-	s.f("%s.Put(%T(%v), %T(%v)) -> %v", s.Cache, key, key, value, value, err)
+	s.log(start, "Put", key, err, "%s.Put(%T(%v), %T(%v)) -> %v", s.Cache, key, key, value, value, err)
+	return
+}
+
+func (s *spy) PutWithTTL(key, value interface{}, ttl time.Duration) (err error) {
+	start := time.Now()
+	err = PutWithTTL(s.Cache, key, value, ttl)
+	s.log(start, "PutWithTTL", key, err, "%s.PutWithTTL(%T(%v), %T(%v), %s) -> %v", s.Cache, key, key, value, value, ttl, err)
+	return
+}
+
+func (s *spy) PutWithCost(key, value interface{}, cost int64) (err error) {
+	start := time.Now()
+	err = PutWithCost(s.Cache, key, value, cost)
+	s.log(start, "PutWithCost", key, err, "%s.PutWithCost(%T(%v), %T(%v), %d) -> %v", s.Cache, key, key, value, value, cost, err)
 	return
 }
 
 func (s *spy) Get(key interface{}) (value interface{}, err error) {
+	start := time.Now()
 	value, err = s.Cache.Get(key)
-	s.f("%s.Get(%T(%v)) -> %T(%v), %v", s.Cache, key, key, value, value, err)
+	s.log(start, "Get", key, err, "%s.Get(%T(%v)) -> %T(%v), %v", s.Cache, key, key, value, value, err)
+	return
+}
+
+func (s *spy) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	start := time.Now()
+	value, expiresAt, err = GetWithExpiry(s.Cache, key)
+	s.log(start, "GetWithExpiry", key, err, "%s.GetWithExpiry(%T(%v)) -> %T(%v), %s, %v", s.Cache, key, key, value, value, expiresAt, err)
+	return
+}
+
+func (s *spy) TTL(key interface{}) (ttl time.Duration, err error) {
+	start := time.Now()
+	ttl, err = TTL(s.Cache, key)
+	s.log(start, "TTL", key, err, "%s.TTL(%T(%v)) -> %s, %v", s.Cache, key, key, ttl, err)
 	return
 }
 
 func (s *spy) Remove(key interface{}) (removed bool) {
+	start := time.Now()
 	removed = s.Cache.Remove(key)
-	s.f("%s.Remove(%T(%v)) -> %v", s.Cache, key, key, removed)
+	s.log(start, "Remove", key, nil, "%s.Remove(%T(%v)) -> %v", s.Cache, key, key, removed)
 	return
 }
 
 func (s *spy) Flush() (err error) {
+	start := time.Now()
 	err = s.Cache.Flush()
-	s.f("%s.Flush() -> %v", s.Cache, err)
+	s.log(start, "Flush", nil, err, "%s.Flush() -> %v", s.Cache, err)
 	return
 }
 
 func (s *spy) Len() (len int) {
+	start := time.Now()
 	len = s.Cache.Len()
-	s.f("%s.Len() -> %v", s.Cache, len)
+	s.log(start, "Len", nil, nil, "%s.Len() -> %v", s.Cache, len)
 	return
 }
 
+func (s *spy) GetOrCompute(key interface{}, f ComputeFunc) (value interface{}, err error) {
+	start := time.Now()
+	value, err = GetOrCompute(s.Cache, key, f)
+	s.log(start, "GetOrCompute", key, err, "%s.GetOrCompute(%T(%v)) -> %T(%v), %v", s.Cache, key, key, value, value, err)
+	return
+}
+
+func (s *spy) Clear() (err error) {
+	start := time.Now()
+	err = Clear(s.Cache)
+	s.log(start, "Clear", nil, err, "%s.Clear() -> %v", s.Cache, err)
+	return
+}
+
+func (s *spy) Range(f func(key, value interface{}) bool) (err error) {
+	start := time.Now()
+	err = Range(s.Cache, f)
+	s.log(start, "Range", nil, err, "%s.Range() -> %v", s.Cache, err)
+	return
+}
+
+func (s *spy) notifyDrops(f dropFunc) {
+	notifyDrops(s.Cache, f)
+}
+
 type errorLogger struct {
 	Cache
-	log Printf
+	f Printf
+	// l is used by LogErrorsTo, when f is nil.
+	l logging.Logger
 }
 
 // LogErrors catchs and logs errors using the given function.
 func LogErrors(f Printf) Option {
 	return func(c Cache) Cache {
-		return &errorLogger{c, f}
+		return &errorLogger{Cache: c, f: f}
 	}
 }
 
 func (c *errorLogger) Put(key, value interface{}) (err error) {
 	if err := c.Cache.Put(key, value); err != nil {
-		c.log("%s.Put(%v, %s): %s", c.Cache, key, value, err)
+		c.log("Put", key, err, "%s.Put(%v, %s): %s", c.Cache, key, value, err)
+	}
+	return nil
+}
+
+func (c *errorLogger) PutWithTTL(key, value interface{}, ttl time.Duration) (err error) {
+	if err := PutWithTTL(c.Cache, key, value, ttl); err != nil {
+		c.log("PutWithTTL", key, err, "%s.PutWithTTL(%v, %s, %s): %s", c.Cache, key, value, ttl, err)
+	}
+	return nil
+}
+
+func (c *errorLogger) PutWithCost(key, value interface{}, cost int64) (err error) {
+	if err := PutWithCost(c.Cache, key, value, cost); err != nil {
+		c.log("PutWithCost", key, err, "%s.PutWithCost(%v, %s, %d): %s", c.Cache, key, value, cost, err)
 	}
 	return nil
 }
@@ -69,19 +154,52 @@ func (c *errorLogger) Put(key, value interface{}) (err error) {
 func (c *errorLogger) Get(key interface{}) (value interface{}, err error) {
 	value, err = c.Cache.Get(key)
 	if err != nil && err != ErrKeyNotFound {
-		c.log("%s.Get(%v): %s", c.Cache, key, err)
-		key = ErrKeyNotFound
+		c.log("Get", key, err, "%s.Get(%v): %s", c.Cache, key, err)
+		err = ErrKeyNotFound
+	}
+	return
+}
+
+func (c *errorLogger) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	value, expiresAt, err = GetWithExpiry(c.Cache, key)
+	if err != nil && err != ErrKeyNotFound {
+		c.log("GetWithExpiry", key, err, "%s.GetWithExpiry(%v): %s", c.Cache, key, err)
+		err = ErrKeyNotFound
+	}
+	return
+}
+
+func (c *errorLogger) TTL(key interface{}) (ttl time.Duration, err error) {
+	ttl, err = TTL(c.Cache, key)
+	if err != nil && err != ErrKeyNotFound {
+		c.log("TTL", key, err, "%s.TTL(%v): %s", c.Cache, key, err)
+		err = ErrKeyNotFound
 	}
 	return
 }
 
 func (c *errorLogger) Flush() error {
 	if err := c.Cache.Flush(); err != nil {
-		c.log("%s.Flush(): %s", c.Cache, err)
+		c.log("Flush", nil, err, "%s.Flush(): %s", c.Cache, err)
 	}
 	return nil
 }
 
+func (c *errorLogger) Clear() error {
+	if err := Clear(c.Cache); err != nil {
+		c.log("Clear", nil, err, "%s.Clear(): %s", c.Cache, err)
+	}
+	return nil
+}
+
+func (c *errorLogger) Range(f func(key, value interface{}) bool) error {
+	return Range(c.Cache, f)
+}
+
+func (c *errorLogger) notifyDrops(f dropFunc) {
+	notifyDrops(c.Cache, f)
+}
+
 // EventType represents the type of operation that has been performed.
 type EventType uint8
 
@@ -93,6 +211,9 @@ const (
 	REMOVE
 	FLUSH
 	LEN
+	CLEAR
+	EVICT
+	EXPIRE
 )
 
 func (e EventType) String() string {
@@ -107,6 +228,12 @@ func (e EventType) String() string {
 		return "FLUSH"
 	case LEN:
 		return "LEN"
+	case CLEAR:
+		return "CLEAR"
+	case EVICT:
+		return "EVICT"
+	case EXPIRE:
+		return "EXPIRE"
 	default:
 		return fmt.Sprintf("EventType(%d)", e)
 	}
@@ -125,33 +252,103 @@ type Event struct {
 	// The targetted cache
 	Cache Cache
 
-	// The entry key (PUT, GET, REMOVE)
+	// The entry key (PUT, GET, REMOVE, EVICT, EXPIRE)
 	Key interface{}
 
-	// The entry value (PUT) or any value returned by the operation (GET, REMOVE, LEN).
+	// The entry value (PUT), the former value of the dropped entry (EVICT, EXPIRE)
+	// or any value returned by the operation (GET, REMOVE, LEN).
 	Value interface{}
 
-	// Any error returned by the operation (PUT, GET, FLUSH).
+	// Any error returned by the operation (PUT, GET, FLUSH, CLEAR).
 	Err error
 }
 
 type emitter struct {
 	Cache
 	ch chan<- Event
+	// in is the receiving end of ch, used by DropOldest.
+	in      chan Event
+	policy  EmitPolicy
+	dropped atomic.Uint64
+	// hub is used by Events, instead of ch.
+	hub *EventHub
 }
 
-// Emitter sends cache events to the given channel.
+// EmitPolicy tells what an Emitter does when its channel is full.
+type EmitPolicy uint8
+
+// EmitPolicy values
+const (
+	// DropNewest drops the new event.
+	DropNewest EmitPolicy = iota
+	// DropOldest drops the oldest event of the channel to make room for the new one.
+	DropOldest
+	// Block waits for room in the channel, blocking the operation.
+	Block
+)
+
+// DropCounter is implemented by the caches returned by the Emitter options.
+type DropCounter interface {
+	// DroppedEvents returns the number of events dropped because the channel was full.
+	DroppedEvents() uint64
+}
+
+// Emitter sends cache events to the given channel. The events are dropped when the channel is full.
+// It also sends EVICT and EXPIRE events when the underlying evicting or expiring layers drop entries.
 func Emitter(ch chan<- Event) Option {
+	return newEmitter(ch, nil, DropNewest)
+}
+
+// EmitterBlocking sends cache events to the given channel, waiting for room in the channel when it is full.
+func EmitterBlocking(ch chan<- Event) Option {
+	return newEmitter(ch, nil, Block)
+}
+
+// EmitterWithPolicy sends cache events to the given channel, using the given policy when it is full.
+func EmitterWithPolicy(ch chan Event, policy EmitPolicy) Option {
+	return newEmitter(ch, ch, policy)
+}
+
+func newEmitter(ch chan<- Event, in chan Event, policy EmitPolicy) Option {
 	return func(c Cache) Cache {
-		return &emitter{c, ch}
+		e := &emitter{Cache: c, ch: ch, in: in, policy: policy}
+		notifyDrops(c, func(t EventType, key, value interface{}) {
+			e.emit(t, key, value, nil)
+		})
+		return e
 	}
 }
 
 func (e *emitter) emit(t EventType, key, value interface{}, err error) {
-	select {
-	case e.ch <- Event{t, e.Cache, key, value, err}:
-	default:
+	ev := Event{t, e.Cache, key, value, err}
+	if e.hub != nil {
+		e.hub.publish(ev)
+		return
+	}
+	if e.policy == Block {
+		e.ch <- ev
+		return
 	}
+	for {
+		select {
+		case e.ch <- ev:
+			return
+		default:
+		}
+		if e.policy != DropOldest {
+			e.dropped.Add(1)
+			return
+		}
+		select {
+		case <-e.in:
+			e.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+func (e *emitter) DroppedEvents() uint64 {
+	return e.dropped.Load()
 }
 
 func (e *emitter) Put(key, value interface{}) (err error) {
@@ -160,12 +357,34 @@ func (e *emitter) Put(key, value interface{}) (err error) {
 	return
 }
 
+func (e *emitter) PutWithTTL(key, value interface{}, ttl time.Duration) (err error) {
+	err = PutWithTTL(e.Cache, key, value, ttl)
+	e.emit(PUT, key, value, err)
+	return
+}
+
+func (e *emitter) PutWithCost(key, value interface{}, cost int64) (err error) {
+	err = PutWithCost(e.Cache, key, value, cost)
+	e.emit(PUT, key, value, err)
+	return
+}
+
 func (e *emitter) Get(key interface{}) (value interface{}, err error) {
 	value, err = e.Cache.Get(key)
 	e.emit(GET, key, value, err)
 	return
 }
 
+func (e *emitter) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	value, expiresAt, err = GetWithExpiry(e.Cache, key)
+	e.emit(GET, key, value, err)
+	return
+}
+
+func (e *emitter) TTL(key interface{}) (time.Duration, error) {
+	return TTL(e.Cache, key)
+}
+
 func (e *emitter) Remove(key interface{}) (removed bool) {
 	removed = e.Cache.Remove(key)
 	e.emit(REMOVE, key, removed, nil)
@@ -178,8 +397,47 @@ func (e *emitter) Flush() (err error) {
 	return
 }
 
+func (e *emitter) Clear() (err error) {
+	err = Clear(e.Cache)
+	e.emit(CLEAR, nil, nil, err)
+	return
+}
+
+func (e *emitter) Range(f func(key, value interface{}) bool) error {
+	return Range(e.Cache, f)
+}
+
+func (e *emitter) notifyDrops(f dropFunc) {
+	notifyDrops(e.Cache, f)
+}
+
 func (e *emitter) Len() (len int) {
 	len = e.Cache.Len()
 	e.emit(LEN, nil, len, nil)
 	return
 }
+
+// dropFunc is called when a layer drops an entry by itself, with EVICT or EXPIRE.
+type dropFunc func(t EventType, key, value interface{})
+
+// dropNotifier is implemented by the layers which drop entries by themselves, i.e. the evicting and expiring caches.
+// The other options of this package forward it to their underlying cache.
+type dropNotifier interface {
+	notifyDrops(f dropFunc)
+}
+
+// notifyDrops registers f to be called when a layer of the cache drops an entry.
+// It must be called while building the cache, before it is used.
+func notifyDrops(c Cache, f dropFunc) {
+	if n, ok := c.(dropNotifier); ok {
+		n.notifyDrops(f)
+	}
+}
+
+type dropHooks []dropFunc
+
+func (h dropHooks) dropped(t EventType, key, value interface{}) {
+	for _, f := range h {
+		f(t, key, value)
+	}
+}
@@ -1,6 +1,9 @@
 package cache
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestEmiter(t *testing.T) {
 
@@ -33,3 +36,74 @@ func TestEmiter(t *testing.T) {
 		t.Errorf("Event mismatch, got %#v", e)
 	}
 }
+
+func TestEmiterDrops(t *testing.T) {
+
+	ch := make(chan Event, 10)
+	cl := FakeClock(time.Unix(0, 0))
+
+	c := NewMemoryStorage(Emitter(ch), Spy(t.Logf), LRUEviction(1), ExpirationUsingClock(time.Second, &cl))
+
+	c.Put(1, 10)
+	c.Put(2, 20)
+	<-ch
+	if e := <-ch; e.Type != EVICT || e.Key != 1 || e.Value != 10 || e.Err != nil {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+	if e := <-ch; e.Type != PUT || e.Key != 2 || e.Value != 20 || e.Err != nil {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+
+	cl.Advance(2 * time.Second)
+
+	c.Get(2)
+	if e := <-ch; e.Type != EXPIRE || e.Key != 2 || e.Value != 20 || e.Err != nil {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+	if e := <-ch; e.Type != GET || e.Key != 2 || e.Value != nil || e.Err != ErrKeyNotFound {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+}
+
+func TestEmitterWithPolicy(t *testing.T) {
+
+	ch := make(chan Event, 2)
+	c := NewVoidStorage(EmitterWithPolicy(ch, DropOldest))
+
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Put(3, 3)
+
+	if n := c.(DropCounter).DroppedEvents(); n != 1 {
+		t.Errorf("DroppedEvents: expected 1, got %d", n)
+	}
+	if e := <-ch; e.Key != 2 {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+	if e := <-ch; e.Key != 3 {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+
+	c = NewVoidStorage(Emitter(ch))
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Put(3, 3)
+
+	if n := c.(DropCounter).DroppedEvents(); n != 1 {
+		t.Errorf("DroppedEvents: expected 1, got %d", n)
+	}
+	if e := <-ch; e.Key != 1 {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+}
+
+func TestEmitterBlocking(t *testing.T) {
+
+	ch := make(chan Event)
+	c := NewVoidStorage(EmitterBlocking(ch))
+
+	go c.Put(1, 1)
+	if e := <-ch; e.Type != PUT || e.Key != 1 {
+		t.Errorf("Event mismatch, got %#v", e)
+	}
+}
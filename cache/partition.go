@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"encoding/gob"
+	"fmt"
+)
+
+// Partitioner is implemented by storages which have a native way to host several logical caches, like Bolt.
+type Partitioner interface {
+	// Partition returns the cache of the named group.
+	Partition(name string) Cache
+}
+
+// Partition returns a view of the cache scoped to the named group, with its own Len and Clear, so one storage
+// can host several logical caches.
+//
+// If the cache does not implement Partitioner, the keys are stored along with the name of the group. Len and
+// Clear then iterate over the whole cache, and return 0 or an error if it is not iterable.
+func Partition(c Cache, name string) Cache {
+	if p, ok := c.(Partitioner); ok {
+		return p.Partition(name)
+	}
+	p := &partitionedCache{name: name}
+	p.hashedKeys = hashedKeys{c, p.partitionKey}
+	return p
+}
+
+// partitionKey is the actual key of an entry of a partition in the underlying cache.
+type partitionKey struct {
+	Partition string
+	Key       interface{}
+}
+
+func init() {
+	gob.Register(partitionKey{})
+}
+
+type partitionedCache struct {
+	hashedKeys
+	name string
+}
+
+func (p *partitionedCache) partitionKey(key interface{}) (interface{}, error) {
+	return partitionKey{p.name, key}, nil
+}
+
+// rangeInner calls f with the actual keys of the entries of the partition.
+func (p *partitionedCache) rangeInner(f func(k partitionKey, value interface{}) bool) error {
+	return Range(p.Cache, func(key, value interface{}) bool {
+		if k, ok := key.(partitionKey); ok && k.Partition == p.name {
+			return f(k, value)
+		}
+		return true
+	})
+}
+
+func (p *partitionedCache) Len() (n int) {
+	p.rangeInner(func(partitionKey, interface{}) bool {
+		n++
+		return true
+	})
+	return
+}
+
+func (p *partitionedCache) Range(f func(key, value interface{}) bool) error {
+	return p.rangeInner(func(k partitionKey, value interface{}) bool {
+		return f(k.Key, value)
+	})
+}
+
+func (p *partitionedCache) Clear() error {
+	var keys []partitionKey
+	if err := p.rangeInner(func(k partitionKey, _ interface{}) bool {
+		keys = append(keys, k)
+		return true
+	}); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		p.Cache.Remove(k)
+	}
+	return nil
+}
+
+func (p *partitionedCache) notifyDrops(f dropFunc) {
+	notifyDrops(p.Cache, func(t EventType, key, value interface{}) {
+		if k, ok := key.(partitionKey); ok && k.Partition == p.name {
+			f(t, k.Key, value)
+		}
+	})
+}
+
+func (p *partitionedCache) String() string {
+	return fmt.Sprintf("Partition(%s,%s)", p.Cache, p.name)
+}
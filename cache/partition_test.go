@@ -0,0 +1,61 @@
+package cache
+
+import "testing"
+
+func testPartition(t *testing.T, c Cache) {
+
+	users := Partition(c, "users")
+	posts := Partition(c, "posts")
+	t.Logf("partitions: %s, %s", users, posts)
+
+	users.Put(1, "alice")
+	users.Put(2, "bob")
+	posts.Put(1, "hello")
+
+	if v, err := users.Get(1); v != "alice" || err != nil {
+		t.Errorf("Get: expected alice, <nil>, got %v, %v", v, err)
+	}
+	if v, err := posts.Get(1); v != "hello" || err != nil {
+		t.Errorf("Get: expected hello, <nil>, got %v, %v", v, err)
+	}
+	if v := users.Len(); v != 2 {
+		t.Errorf("Len: expected 2, got %d", v)
+	}
+
+	if err := Clear(users); err != nil {
+		t.Errorf("Clear: unexpected error %v", err)
+	}
+	if v := users.Len(); v != 0 {
+		t.Errorf("Len: expected 0, got %d", v)
+	}
+	if keys, err := Keys(posts); len(keys) != 1 || keys[0] != 1 || err != nil {
+		t.Errorf("Keys: expected [1], <nil>, got %v, %v", keys, err)
+	}
+
+	users.Put(3, "carol")
+	if v, err := users.Get(3); v != "carol" || err != nil {
+		t.Errorf("Get: expected carol, <nil>, got %v, %v", v, err)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	testPartition(t, NewMemoryStorage())
+}
+
+func TestBoltPartition(t *testing.T) {
+
+	c, err := NewBoltStorage(openTestBolt(t), "cache", BoltOptions{})
+	if err != nil {
+		t.Fatalf("NewBoltStorage: unexpected error %v", err)
+	}
+	c.Put(1, "root")
+
+	testPartition(t, c)
+
+	if v := c.Len(); v != 1 {
+		t.Errorf("Len: expected 1, got %d", v)
+	}
+	if keys, err := Keys(c); len(keys) != 1 || err != nil {
+		t.Errorf("Keys: expected [1], <nil>, got %v, %v", keys, err)
+	}
+}
@@ -0,0 +1,426 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HashRing maps keys to peers using consistent hashing, so adding or removing a peer only moves
+// a fraction of the keys.
+type HashRing struct {
+	replicas int
+	hash     func([]byte) uint32
+	hashes   []uint32
+	peers    map[uint32]string
+}
+
+// NewHashRing creates an empty HashRing placing each peer at the given number of points of the ring.
+// The hash function defaults to crc32.ChecksumIEEE.
+func NewHashRing(replicas int, hash func([]byte) uint32) *HashRing {
+	if hash == nil {
+		hash = crc32.ChecksumIEEE
+	}
+	return &HashRing{replicas: replicas, hash: hash, peers: make(map[uint32]string)}
+}
+
+// Add adds peers to the ring.
+func (r *HashRing) Add(peers ...string) {
+	for _, peer := range peers {
+		for i := 0; i < r.replicas; i++ {
+			h := r.hash([]byte(strconv.Itoa(i) + peer))
+			r.hashes = append(r.hashes, h)
+			r.peers[h] = peer
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+}
+
+// IsEmpty returns whether the ring has no peers.
+func (r *HashRing) IsEmpty() bool {
+	return len(r.hashes) == 0
+}
+
+// Get returns the peer owning the key, or an empty string if the ring is empty.
+func (r *HashRing) Get(key []byte) string {
+	if r.IsEmpty() {
+		return ""
+	}
+	h := r.hash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.peers[r.hashes[i]]
+}
+
+// HTTPPoolConfig configures a HTTPPool.
+type HTTPPoolConfig struct {
+	// Self is the base URL of this peer, e.g. "http://10.0.0.1:8080".
+	Self string
+
+	// BasePath is the path the pool handler is served on. It defaults to "/_cache/".
+	BasePath string
+
+	// Replicas is the number of points of each peer on the hash ring. It defaults to 50.
+	Replicas int
+
+	// Hash is the hash function of the ring. It defaults to crc32.ChecksumIEEE.
+	Hash func([]byte) uint32
+
+	// Client sends the requests to the other peers. It defaults to http.DefaultClient.
+	Client *http.Client
+
+	// Timeout bounds each request sent to the other peers. It defaults to 5 seconds.
+	Timeout time.Duration
+
+	// MaxValueSize is the maximum size of the encoded values accepted from the other peers. It defaults to 1 MiB.
+	MaxValueSize int64
+
+	// Authenticate, if not nil, is called to add credentials to the requests sent to the other peers.
+	Authenticate func(*http.Request)
+
+	// Authorize, if not nil, tells whether a request from another peer is allowed. The denied requests get a
+	// 403 response. The pool handler is open to anyone who can reach it otherwise.
+	Authorize func(*http.Request) bool
+}
+
+// expiresHeader holds the expiration time of the entries sent to the other peers, if any.
+const expiresHeader = "X-Cache-Expires"
+
+// HTTPPool is a group of peers sharing a cache over HTTP. Each key is owned by one peer: the operations
+// on the keys owned by other peers are forwarded to them.
+//
+// The pool must be served by the HTTP server of each peer, on its BasePath, and the local cache of
+// the peer must be attached using the Peers option.
+type HTTPPool struct {
+	conf  HTTPPoolConfig
+	mu    sync.RWMutex
+	ring  *HashRing
+	local Cache
+}
+
+// NewHTTPPool creates a HTTPPool, with this peer only.
+func NewHTTPPool(conf HTTPPoolConfig) *HTTPPool {
+	if conf.BasePath == "" {
+		conf.BasePath = "/_cache/"
+	}
+	if conf.Replicas <= 0 {
+		conf.Replicas = 50
+	}
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = 5 * time.Second
+	}
+	if conf.MaxValueSize <= 0 {
+		conf.MaxValueSize = 1 << 20
+	}
+	conf.Self = strings.TrimSuffix(conf.Self, "/")
+	p := &HTTPPool{conf: conf}
+	p.Set()
+	return p
+}
+
+// Set replaces the peers of the pool. The base URLs must match the Self setting of the peers.
+// This peer is always part of the pool.
+func (p *HTTPPool) Set(peers ...string) {
+	ring := NewHashRing(p.conf.Replicas, p.conf.Hash)
+	ring.Add(p.conf.Self)
+	for _, peer := range peers {
+		if peer = strings.TrimSuffix(peer, "/"); peer != p.conf.Self {
+			ring.Add(peer)
+		}
+	}
+	p.mu.Lock()
+	p.ring = ring
+	p.mu.Unlock()
+}
+
+// owner returns the URL of the entry on the peer owning the key, or an empty string if this peer owns it.
+func (p *HTTPPool) owner(key []byte) string {
+	p.mu.RLock()
+	peer := p.ring.Get(key)
+	p.mu.RUnlock()
+	if peer == p.conf.Self {
+		return ""
+	}
+	return peer + p.conf.BasePath + base64.RawURLEncoding.EncodeToString(key)
+}
+
+// ServeHTTP serves the requests of the other peers, using the local cache.
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, p.conf.BasePath) || p.local == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if p.conf.Authorize != nil && !p.conf.Authorize(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	k, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(r.URL.Path, p.conf.BasePath))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, err := gobDecode(k)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		value, expiresAt, err := GetWithExpiry(p.local, key)
+		if err == ErrKeyNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		var data []byte
+		if err == nil {
+			data, err = gobEncode(value)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if !expiresAt.IsZero() {
+			w.Header().Set(expiresHeader, expiresAt.Format(time.RFC3339Nano))
+		}
+		w.Write(data)
+
+	case http.MethodPut:
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, p.conf.MaxValueSize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		value, err := gobDecode(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := r.URL.Query()
+		ttl, terr := strconv.ParseInt(query.Get("ttl"), 10, 64)
+		cost, cerr := strconv.ParseInt(query.Get("cost"), 10, 64)
+		switch {
+		case terr == nil:
+			err = PutWithTTL(p.local, key, value, time.Duration(ttl))
+		case cerr == nil:
+			err = PutWithCost(p.local, key, value, cost)
+		default:
+			err = p.local.Put(key, value)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case http.MethodDelete:
+		if !p.local.Remove(key) {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *HTTPPool) do(method, url string, body []byte) (*http.Response, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.conf.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.conf.Authenticate != nil {
+		p.conf.Authenticate(req)
+	}
+	resp, err := p.conf.Client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	return resp, data, err
+}
+
+// Peers adds a layer forwarding the operations on the keys owned by other peers of the pool.
+// The underlying cache is used for the keys owned by this peer, and to serve the requests of the other peers.
+//
+// Keys and values are gob-encoded: their concrete types must be registered using gob.Register,
+// unless they are basic types. The expiration times and the costs are forwarded along with the entries.
+// Len and the other operations only apply to the local entries.
+func Peers(pool *HTTPPool) Option {
+	return func(c Cache) Cache {
+		pool.local = c
+		return &peerCache{c, pool}
+	}
+}
+
+type peerCache struct {
+	Cache
+	pool *HTTPPool
+}
+
+// owner returns the URL of the entry on its owner, or an empty string if it is owned locally.
+func (c *peerCache) owner(key interface{}) (string, error) {
+	k, err := gobEncode(key)
+	if err != nil {
+		return "", err
+	}
+	return c.pool.owner(k), nil
+}
+
+func (c *peerCache) Put(key, value interface{}) error {
+	url, err := c.owner(key)
+	if err != nil || url == "" {
+		if err == nil {
+			err = c.Cache.Put(key, value)
+		}
+		return err
+	}
+	return c.put(url, value)
+}
+
+func (c *peerCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	url, err := c.owner(key)
+	if err != nil || url == "" {
+		if err == nil {
+			err = PutWithTTL(c.Cache, key, value, ttl)
+		}
+		return err
+	}
+	return c.put(url+"?ttl="+strconv.FormatInt(int64(ttl), 10), value)
+}
+
+func (c *peerCache) PutWithCost(key, value interface{}, cost int64) error {
+	url, err := c.owner(key)
+	if err != nil || url == "" {
+		if err == nil {
+			err = PutWithCost(c.Cache, key, value, cost)
+		}
+		return err
+	}
+	return c.put(url+"?cost="+strconv.FormatInt(cost, 10), value)
+}
+
+// put sends an entry to its owner.
+func (c *peerCache) put(url string, value interface{}) error {
+	data, err := gobEncode(value)
+	if err != nil {
+		return err
+	}
+	resp, _, err := c.pool.do(http.MethodPut, url, data)
+	if err == nil && resp.StatusCode != http.StatusNoContent {
+		err = fmt.Errorf("peer %s: %s", url, resp.Status)
+	}
+	return err
+}
+
+func (c *peerCache) Get(key interface{}) (interface{}, error) {
+	value, _, err := c.GetWithExpiry(key)
+	return value, err
+}
+
+func (c *peerCache) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	url, err := c.owner(key)
+	if err != nil || url == "" {
+		if err == nil {
+			return GetWithExpiry(c.Cache, key)
+		}
+		return nil, time.Time{}, err
+	}
+	resp, data, err := c.get(http.MethodGet, url)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	value, err := gobDecode(data)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return value, expiresAt(resp), nil
+}
+
+func (c *peerCache) TTL(key interface{}) (time.Duration, error) {
+	url, err := c.owner(key)
+	if err != nil || url == "" {
+		if err == nil {
+			return TTL(c.Cache, key)
+		}
+		return 0, err
+	}
+	resp, _, err := c.get(http.MethodHead, url)
+	if err != nil {
+		return 0, err
+	}
+	if at := expiresAt(resp); !at.IsZero() {
+		return time.Until(at), nil
+	}
+	return 0, nil
+}
+
+// get fetchs an entry from its owner.
+func (c *peerCache) get(method, url string) (*http.Response, []byte, error) {
+	resp, data, err := c.pool.do(method, url, nil)
+	switch {
+	case err != nil:
+		return nil, nil, err
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil, ErrKeyNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, fmt.Errorf("peer %s: %s", url, resp.Status)
+	}
+	return resp, data, nil
+}
+
+// expiresAt returns the expiration time sent by the owner of an entry, or zero.
+func expiresAt(resp *http.Response) time.Time {
+	at, _ := time.Parse(time.RFC3339Nano, resp.Header.Get(expiresHeader))
+	return at
+}
+
+func (c *peerCache) Remove(key interface{}) bool {
+	url, err := c.owner(key)
+	if err != nil || url == "" {
+		return err == nil && c.Cache.Remove(key)
+	}
+	resp, _, err := c.pool.do(http.MethodDelete, url, nil)
+	return err == nil && resp.StatusCode == http.StatusNoContent
+}
+
+func (c *peerCache) Range(f func(key, value interface{}) bool) error {
+	return Range(c.Cache, f)
+}
+
+func (c *peerCache) notifyDrops(f dropFunc) {
+	notifyDrops(c.Cache, f)
+}
+
+func (c *peerCache) Clear() error {
+	return Clear(c.Cache)
+}
+
+func (c *peerCache) String() string {
+	return fmt.Sprintf("Peers(%s,%s)", c.Cache, c.pool.conf.Self)
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHashRing(t *testing.T) {
+
+	r := NewHashRing(10, nil)
+
+	if r.Get([]byte("a")) != "" {
+		t.Error("Get: expected an empty string")
+	}
+
+	r.Add("a", "b", "c")
+	owners := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		owners[key] = r.Get([]byte(key))
+	}
+
+	r.Add("d")
+	moved := 0
+	for key, owner := range owners {
+		if o := r.Get([]byte(key)); o != owner {
+			if o != "d" {
+				t.Errorf("Get: key %s moved from %s to %s", key, owner, o)
+			}
+			moved++
+		}
+	}
+	if moved == 0 || moved > 50 {
+		t.Errorf("expected some keys to move to the new peer, got %d", moved)
+	}
+}
+
+func TestPeers(t *testing.T) {
+
+	var handlers [2]http.Handler
+	servers := make([]*httptest.Server, 2)
+	urls := make([]string, 2)
+	for i := range servers {
+		i := i
+		servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlers[i].ServeHTTP(w, r)
+		}))
+		defer servers[i].Close()
+		urls[i] = servers[i].URL
+	}
+
+	caches := make([]Cache, 2)
+	locals := make([]Cache, 2)
+	for i := range caches {
+		pool := NewHTTPPool(HTTPPoolConfig{Self: urls[i]})
+		pool.Set(urls...)
+		handlers[i] = pool
+		locals[i] = NewMemoryStorage()
+		caches[i] = Peers(pool)(locals[i])
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := caches[i%2].Put(i, strconv.Itoa(i)); err != nil {
+			t.Fatalf("Put: unexpected error %v", err)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		if v, err := caches[(i+1)%2].Get(i); v != strconv.Itoa(i) || err != nil {
+			t.Errorf("Get(%d): expected %d, <nil>, got %v, %v", i, i, v, err)
+		}
+	}
+
+	if n := locals[0].Len() + locals[1].Len(); n != 20 || locals[0].Len() == 0 || locals[1].Len() == 0 {
+		t.Errorf("expected the entries to be spread over the peers, got %d and %d", locals[0].Len(), locals[1].Len())
+	}
+
+	for i := 0; i < 20; i++ {
+		if !caches[i%2].Remove(i) {
+			t.Errorf("Remove(%d): expected true", i)
+		}
+	}
+
+	if v, err := caches[0].Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+}
+
+func TestPeersTTLAndAuthorization(t *testing.T) {
+
+	var handlers [2]http.Handler
+	urls := make([]string, 2)
+	for i := range urls {
+		i := i
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlers[i].ServeHTTP(w, r)
+		}))
+		defer server.Close()
+		urls[i] = server.URL
+	}
+
+	caches := make([]Cache, 2)
+	for i := range caches {
+		pool := NewHTTPPool(HTTPPoolConfig{
+			Self:         urls[i],
+			MaxValueSize: 64,
+			Authenticate: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") },
+			Authorize:    func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer secret" },
+		})
+		pool.Set(urls...)
+		handlers[i] = pool
+		caches[i] = Peers(pool)(NewMemoryStorage(Expiration(time.Hour)))
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := PutWithTTL(caches[0], i, i, time.Minute); err != nil {
+			t.Fatalf("PutWithTTL: unexpected error %v", err)
+		}
+		if ttl, err := TTL(caches[1], i); ttl <= 0 || ttl > time.Minute || err != nil {
+			t.Errorf("TTL(%d): expected at most 1m0s, <nil>, got %v, %v", i, ttl, err)
+		}
+		if v, expiresAt, err := GetWithExpiry(caches[1], i); v != i || expiresAt.IsZero() || err != nil {
+			t.Errorf("GetWithExpiry(%d): expected %d, a time, <nil>, got %v, %v, %v", i, i, v, expiresAt, err)
+		}
+	}
+
+	// Send a large value to the other peer.
+	key := 0
+	for url, _ := caches[0].(*peerCache).owner(key); url == ""; url, _ = caches[0].(*peerCache).owner(key) {
+		key++
+	}
+	if err := caches[0].Put(key, make([]byte, 128)); err == nil {
+		t.Error("Put: expected the large value to be rejected by the other peer")
+	}
+
+	resp, err := http.Get(urls[0] + "/_cache/AA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected unauthenticated requests to be forbidden, got %s", resp.Status)
+	}
+}
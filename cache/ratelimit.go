@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by rate-limited caches when the limit is exceeded.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitConfig holds the configuration of RateLimitWith.
+type RateLimitConfig struct {
+	// Rate is the number of operations per second allowed in the long run.
+	Rate float64
+
+	// Burst is the number of operations that can be made at once. It defaults to 1.
+	Burst int
+
+	// Block waits for the limit to allow the operations, instead of failing with ErrRateLimited.
+	Block bool
+
+	// Clock is used to refill the bucket. It defaults to RealClock.
+	Clock Clock
+}
+
+// RateLimit adds a layer which limits the rate of Get, Put and Remove operations reaching the underlying cache,
+// e.g. a Loader calling an external API with quotas. Operations above the limit fail with ErrRateLimited;
+// Remove returns false. It panics if the rate is not positive.
+func RateLimit(rate float64, burst int) Option {
+	return RateLimitWith(RateLimitConfig{Rate: rate, Burst: burst})
+}
+
+// RateLimitWith adds a layer which limits the rate of Get, Put and Remove operations reaching the underlying cache,
+// using a token bucket. It panics if the rate is not positive.
+func RateLimitWith(conf RateLimitConfig) Option {
+	if !(conf.Rate > 0) {
+		panic("RateLimitWith: rate must be positive")
+	}
+	if conf.Burst < 1 {
+		conf.Burst = 1
+	}
+	if conf.Clock == nil {
+		conf.Clock = RealClock
+	}
+	return func(c Cache) Cache {
+		return &rateLimited{Cache: c, conf: conf, tokens: float64(conf.Burst), updated: conf.Clock.Now()}
+	}
+}
+
+type rateLimited struct {
+	Cache
+	conf    RateLimitConfig
+	tokens  float64
+	updated time.Time
+	mu      sync.Mutex
+}
+
+// take consumes a token, waiting for it if the layer blocks.
+func (r *rateLimited) take() error {
+	r.mu.Lock()
+	now := r.conf.Clock.Now()
+	r.tokens += now.Sub(r.updated).Seconds() * r.conf.Rate
+	if max := float64(r.conf.Burst); r.tokens > max {
+		r.tokens = max
+	}
+	r.updated = now
+	if r.tokens >= 1 {
+		r.tokens--
+		r.mu.Unlock()
+		return nil
+	}
+	wait := time.Duration((1 - r.tokens) / r.conf.Rate * float64(time.Second))
+	if !r.conf.Block {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: retry in %s for %s", ErrRateLimited, wait, r.Cache)
+	}
+	// Reserve the token, so the waiting operations are served in order.
+	r.tokens--
+	r.mu.Unlock()
+	time.Sleep(wait)
+	return nil
+}
+
+func (r *rateLimited) Put(key, value interface{}) error {
+	if err := r.take(); err != nil {
+		return err
+	}
+	return r.Cache.Put(key, value)
+}
+
+func (r *rateLimited) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	if err := r.take(); err != nil {
+		return err
+	}
+	return PutWithTTL(r.Cache, key, value, ttl)
+}
+
+func (r *rateLimited) PutWithCost(key, value interface{}, cost int64) error {
+	if err := r.take(); err != nil {
+		return err
+	}
+	return PutWithCost(r.Cache, key, value, cost)
+}
+
+// GetOrCompute counts as a single operation, even if the value is computed and stored.
+func (r *rateLimited) GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error) {
+	if err := r.take(); err != nil {
+		return nil, err
+	}
+	return GetOrCompute(r.Cache, key, f)
+}
+
+func (r *rateLimited) Get(key interface{}) (interface{}, error) {
+	if err := r.take(); err != nil {
+		return nil, err
+	}
+	return r.Cache.Get(key)
+}
+
+func (r *rateLimited) Remove(key interface{}) bool {
+	return r.take() == nil && r.Cache.Remove(key)
+}
+
+func (r *rateLimited) Clear() error {
+	return Clear(r.Cache)
+}
+
+func (r *rateLimited) Range(f func(key, value interface{}) bool) error {
+	return Range(r.Cache, f)
+}
+
+func (r *rateLimited) notifyDrops(f dropFunc) {
+	notifyDrops(r.Cache, f)
+}
+
+func (r *rateLimited) String() string {
+	return fmt.Sprintf("RateLimited(%s,%g,%d)", r.Cache, r.conf.Rate, r.conf.Burst)
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(Spy(t.Logf), RateLimitWith(RateLimitConfig{Rate: 2, Burst: 2, Clock: &cl}))
+
+	if err := c.Put(1, 10); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	if v, err := c.Get(1); v != 10 || err != nil {
+		t.Errorf("Get: expected 10, <nil>, got %v, %v", v, err)
+	}
+	if _, err := c.Get(1); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Get: expected %v, got %v", ErrRateLimited, err)
+	}
+
+	cl.Advance(500 * time.Millisecond)
+
+	if v, err := c.Get(1); v != 10 || err != nil {
+		t.Errorf("Get: expected 10, <nil>, got %v, %v", v, err)
+	}
+	if c.Remove(1) {
+		t.Error("Remove: expected false")
+	}
+
+	cl.Advance(time.Second)
+
+	compute := func() (interface{}, error) { return 20, nil }
+	if v, err := GetOrCompute(c, 2, compute); v != 20 || err != nil {
+		t.Errorf("GetOrCompute: expected 20, <nil>, got %v, %v", v, err)
+	}
+	if err := PutWithCost(c, 3, 30, 1); err != nil {
+		t.Errorf("PutWithCost: unexpected error %v", err)
+	}
+	if _, err := GetOrCompute(c, 2, compute); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("GetOrCompute: expected %v, got %v", ErrRateLimited, err)
+	}
+}
+
+func TestRateLimitBlock(t *testing.T) {
+
+	c := NewMemoryStorage(RateLimitWith(RateLimitConfig{Rate: 100, Block: true}))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := c.Put(i, i); err != nil {
+			t.Errorf("Put: unexpected error %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected to wait at least 15ms, waited %s", elapsed)
+	}
+}
+
+func TestRateLimitInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimit(%g): expected a panic", rate)
+				}
+			}()
+			RateLimit(rate, 1)
+		}()
+	}
+}
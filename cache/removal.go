@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// RemovalReason tells why an entry has been removed from a cache.
+type RemovalReason uint8
+
+// RemovalReason values
+const (
+	// Removed using Remove.
+	RemovalExplicit RemovalReason = iota
+	// Evicted by an eviction layer.
+	RemovalEvicted
+	// Dropped by an expiration layer.
+	RemovalExpired
+	// Removed using Clear.
+	RemovalCleared
+)
+
+func (r RemovalReason) String() string {
+	switch r {
+	case RemovalExplicit:
+		return "explicit"
+	case RemovalEvicted:
+		return "evicted"
+	case RemovalExpired:
+		return "expired"
+	case RemovalCleared:
+		return "cleared"
+	default:
+		return fmt.Sprintf("RemovalReason(%d)", r)
+	}
+}
+
+// RemovalFunc is called with the key and the former value of the removed entries.
+type RemovalFunc func(key, value interface{}, reason RemovalReason)
+
+type removalListener struct {
+	Cache
+	f RemovalFunc
+}
+
+// OnRemoval calls f for each entry removed from the cache, e.g. to release the resources held by the values.
+//
+// Remove fetchs the entry before removing it, so OnRemoval should be listed after Loader.
+// Clear can only report the entries of iterable caches.
+func OnRemoval(f RemovalFunc) Option {
+	return func(c Cache) Cache {
+		notifyDrops(c, func(t EventType, key, value interface{}) {
+			if t == EXPIRE {
+				f(key, value, RemovalExpired)
+			} else {
+				f(key, value, RemovalEvicted)
+			}
+		})
+		return &removalListener{c, f}
+	}
+}
+
+func (r *removalListener) Remove(key interface{}) bool {
+	value, err := r.Cache.Get(key)
+	removed := r.Cache.Remove(key)
+	if removed && err == nil {
+		r.f(key, value, RemovalExplicit)
+	}
+	return removed
+}
+
+func (r *removalListener) Clear() error {
+	var keys, values []interface{}
+	Range(r.Cache, func(key, value interface{}) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	if err := Clear(r.Cache); err != nil {
+		return err
+	}
+	for i, key := range keys {
+		r.f(key, values[i], RemovalCleared)
+	}
+	return nil
+}
+
+func (r *removalListener) Range(f func(key, value interface{}) bool) error {
+	return Range(r.Cache, f)
+}
+
+func (r *removalListener) notifyDrops(f dropFunc) {
+	notifyDrops(r.Cache, f)
+}
+
+func (r *removalListener) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	return PutWithTTL(r.Cache, key, value, ttl)
+}
+
+func (r *removalListener) PutWithCost(key, value interface{}, cost int64) error {
+	return PutWithCost(r.Cache, key, value, cost)
+}
+
+func (r *removalListener) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	return GetWithExpiry(r.Cache, key)
+}
+
+func (r *removalListener) TTL(key interface{}) (time.Duration, error) {
+	return TTL(r.Cache, key)
+}
+
+func (r *removalListener) GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error) {
+	return GetOrCompute(r.Cache, key, f)
+}
+
+func (r *removalListener) String() string {
+	return fmt.Sprintf("OnRemoval(%s)", r.Cache)
+}
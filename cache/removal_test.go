@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnRemoval(t *testing.T) {
+
+	type removal struct {
+		key, value interface{}
+		reason     RemovalReason
+	}
+	var removals []removal
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(
+		OnRemoval(func(key, value interface{}, reason RemovalReason) {
+			t.Logf("Removed %v, %v: %s", key, value, reason)
+			removals = append(removals, removal{key, value, reason})
+		}),
+		Spy(t.Logf),
+		LRUEviction(2),
+		ExpirationUsingClock(time.Second, &cl),
+	)
+
+	c.Put(1, 10)
+	c.Put(2, 20)
+	c.Put(3, 30)
+	c.Remove(2)
+	c.Remove(4)
+	cl.Advance(2 * time.Second)
+	c.Get(3)
+	c.Put(5, 50)
+	Clear(c)
+
+	expected := []removal{
+		{1, 10, RemovalEvicted},
+		{2, 20, RemovalExplicit},
+		{3, 30, RemovalExpired},
+		{5, 50, RemovalCleared},
+	}
+	if len(removals) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, removals)
+	}
+	for i, exp := range expected {
+		if removals[i] != exp {
+			t.Errorf("removal #%d: expected %v, got %v", i+1, exp, removals[i])
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// BackoffFunc returns the delay before the given retry, starting at 1.
+type BackoffFunc func(retry int) time.Duration
+
+// ExponentialBackoff returns a backoff function doubling the delay on each retry, starting from base,
+// with up to 50% of random jitter.
+func ExponentialBackoff(base time.Duration) BackoffFunc {
+	return func(retry int) time.Duration {
+		d := base << uint(retry-1)
+		return d + time.Duration(rand.Int63n(int64(d)/2+1))
+	}
+}
+
+// Retry adds a layer which retries the failed Get and Put operations, up to the given number of attempts.
+// GetOrCompute is retried as a whole, so the errors of the computation are retried too.
+//
+// backoff defaults to an exponential backoff from 100ms. retryable selects the errors to retry; it defaults
+// to all errors. ErrKeyNotFound is never retried.
+func Retry(attempts int, backoff BackoffFunc, retryable func(error) bool) Option {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if backoff == nil {
+		backoff = ExponentialBackoff(100 * time.Millisecond)
+	}
+	if retryable == nil {
+		retryable = func(error) bool { return true }
+	}
+	return func(c Cache) Cache {
+		return &retrying{c, attempts, backoff, retryable}
+	}
+}
+
+type retrying struct {
+	Cache
+	attempts  int
+	backoff   BackoffFunc
+	retryable func(error) bool
+}
+
+func (r *retrying) do(op func() error) (err error) {
+	for retry := 0; ; retry++ {
+		err = op()
+		if err == nil || errors.Is(err, ErrKeyNotFound) || retry+1 >= r.attempts || !r.retryable(err) {
+			return
+		}
+		time.Sleep(r.backoff(retry + 1))
+	}
+}
+
+func (r *retrying) Put(key, value interface{}) error {
+	return r.do(func() error { return r.Cache.Put(key, value) })
+}
+
+func (r *retrying) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	return r.do(func() error { return PutWithTTL(r.Cache, key, value, ttl) })
+}
+
+func (r *retrying) PutWithCost(key, value interface{}, cost int64) error {
+	return r.do(func() error { return PutWithCost(r.Cache, key, value, cost) })
+}
+
+func (r *retrying) GetOrCompute(key interface{}, f ComputeFunc) (value interface{}, err error) {
+	err = r.do(func() (err error) {
+		value, err = GetOrCompute(r.Cache, key, f)
+		return
+	})
+	return
+}
+
+func (r *retrying) Get(key interface{}) (value interface{}, err error) {
+	err = r.do(func() (err error) {
+		value, err = r.Cache.Get(key)
+		return
+	})
+	return
+}
+
+func (r *retrying) Clear() error {
+	return Clear(r.Cache)
+}
+
+func (r *retrying) Range(f func(key, value interface{}) bool) error {
+	return Range(r.Cache, f)
+}
+
+func (r *retrying) notifyDrops(f dropFunc) {
+	notifyDrops(r.Cache, f)
+}
+
+func (r *retrying) String() string {
+	return fmt.Sprintf("Retry(%s,%d)", r.Cache, r.attempts)
+}
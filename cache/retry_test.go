@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type flakyCache struct {
+	Cache
+	failures int
+}
+
+func (f *flakyCache) Get(key interface{}) (interface{}, error) {
+	if f.failures > 0 {
+		f.failures--
+		return nil, errDown
+	}
+	return f.Cache.Get(key)
+}
+
+func TestRetry(t *testing.T) {
+
+	noBackoff := func(int) time.Duration { return 0 }
+	f := &flakyCache{Cache: NewMemoryStorage()}
+	c := options{Spy(t.Logf), Retry(3, noBackoff, nil)}.applyTo(f)
+
+	c.Put(1, 10)
+
+	f.failures = 2
+	if v, err := c.Get(1); v != 10 || err != nil {
+		t.Errorf("Get: expected 10, <nil>, got %v, %v", v, err)
+	}
+
+	f.failures = 3
+	if v, err := c.Get(1); v != nil || err != errDown {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", errDown, v, err)
+	}
+
+	f.failures = 0
+	if v, err := c.Get(2); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+
+	f.failures = 2
+	compute := func() (interface{}, error) { return 30, nil }
+	if v, err := GetOrCompute(c, 3, compute); v != 30 || err != nil {
+		t.Errorf("GetOrCompute: expected 30, <nil>, got %v, %v", v, err)
+	}
+
+	c = Retry(3, noBackoff, func(err error) bool { return err != errDown })(f)
+	f.failures = 1
+	if v, err := c.Get(1); v != nil || err != errDown {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", errDown, v, err)
+	}
+}
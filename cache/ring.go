@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// NewRingStorage creates an empty cache holding at most n entries in preallocated slots.
+// New entries overwrite the oldest slots, without any eviction bookkeeping: updating an entry does not move it.
+func NewRingStorage(n int, opts ...Option) Cache {
+	if n <= 0 {
+		panic("NewRingStorage: capacity must be positive")
+	}
+	return options(opts).applyTo(&ringStorage{
+		slots: make([]ringSlot, n),
+		index: make(map[interface{}]int, n),
+	})
+}
+
+type ringSlot struct {
+	key   interface{}
+	value interface{}
+	used  bool
+}
+
+type ringStorage struct {
+	slots []ringSlot
+	index map[interface{}]int
+	next  int
+	mu    sync.RWMutex
+}
+
+func (s *ringStorage) Put(key, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i, found := s.index[key]; found {
+		s.slots[i].value = value
+		return nil
+	}
+	slot := &s.slots[s.next]
+	if slot.used {
+		delete(s.index, slot.key)
+	}
+	*slot = ringSlot{key, value, true}
+	s.index[key] = s.next
+	s.next = (s.next + 1) % len(s.slots)
+	return nil
+}
+
+func (s *ringStorage) Get(key interface{}) (interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i, found := s.index[key]; found {
+		return s.slots[i].value, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (s *ringStorage) Remove(key interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, found := s.index[key]
+	if found {
+		s.slots[i] = ringSlot{}
+		delete(s.index, key)
+	}
+	return found
+}
+
+func (s *ringStorage) Flush() error {
+	return nil
+}
+
+func (s *ringStorage) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.index)
+}
+
+func (s *ringStorage) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.slots {
+		s.slots[i] = ringSlot{}
+	}
+	s.index = make(map[interface{}]int, len(s.slots))
+	s.next = 0
+	return nil
+}
+
+func (s *ringStorage) Range(f func(key, value interface{}) bool) error {
+	s.mu.RLock()
+	slots := make([]ringSlot, 0, len(s.index))
+	for _, slot := range s.slots {
+		if slot.used {
+			slots = append(slots, slot)
+		}
+	}
+	s.mu.RUnlock()
+	for _, slot := range slots {
+		if !f(slot.key, slot.value) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *ringStorage) String() string {
+	return fmt.Sprintf("Ring(%d)", len(s.slots))
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestRingStorage(t *testing.T) {
+
+	c := NewRingStorage(3, Spy(t.Logf))
+
+	for i := 1; i <= 3; i++ {
+		c.Put(i, i*10)
+	}
+	c.Put(1, 11)
+
+	if v, err := c.Get(1); v != 11 || err != nil {
+		t.Error("Get: expected 11, <nil>")
+	}
+
+	c.Put(4, 40)
+
+	if v := c.Len(); v != 3 {
+		t.Errorf("Len: expected 3, got %d", v)
+	}
+
+	if v, err := c.Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	if !c.Remove(2) {
+		t.Error("Remove: expected true")
+	}
+
+	if c.Remove(2) {
+		t.Error("Remove: expected false")
+	}
+
+	// Reuses the slot of 2, which was the oldest one.
+	c.Put(5, 50)
+
+	if keys := sortedKeys(t, c); len(keys) != 3 || keys[0] != 3 || keys[2] != 5 {
+		t.Errorf("Keys: expected [3 4 5], got %v", keys)
+	}
+
+	c.Put(6, 60)
+
+	if v, err := c.Get(3); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+}
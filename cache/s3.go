@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// S3StorageConfig configures a storage using an S3-compatible bucket.
+type S3StorageConfig struct {
+	// Client is the client of the object store.
+	Client *minio.Client
+
+	// Bucket is the name of the bucket. It must exist.
+	Bucket string
+
+	// Prefix is prepended to the object names, e.g. "cache/".
+	Prefix string
+
+	// ContentType is the content type of the objects. It defaults to "application/octet-stream".
+	ContentType string
+
+	// Timeout is the timeout of each operation. It defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// NewS3Storage creates a cache storing its entries as objects of an S3-compatible bucket.
+//
+// The object names are the prefix followed by the path-escaped string representation of the keys,
+// so Range returns the keys as strings. Values must be []byte: other types are rejected with an error wrapping
+// ErrUnexpectedType. The storage implements Iterable and Clearer.
+func NewS3Storage(conf S3StorageConfig, opts ...Option) Cache {
+	if conf.ContentType == "" {
+		conf.ContentType = "application/octet-stream"
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = 30 * time.Second
+	}
+	return options(opts).applyTo(&s3Storage{conf})
+}
+
+type s3Storage struct {
+	S3StorageConfig
+}
+
+func (s *s3Storage) objectName(key interface{}) string {
+	return s.Prefix + url.PathEscape(fmt.Sprint(key))
+}
+
+func (s *s3Storage) keyOf(name string) (string, error) {
+	return url.PathUnescape(strings.TrimPrefix(name, s.Prefix))
+}
+
+func (s *s3Storage) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.Timeout)
+}
+
+func (s *s3Storage) Put(key, value interface{}) error {
+	data, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("%w: %T for key %v in %s", ErrUnexpectedType, value, key, s)
+	}
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.Client.PutObject(ctx, s.Bucket, s.objectName(key), bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: s.ContentType})
+	return err
+}
+
+func (s *s3Storage) Get(key interface{}) (interface{}, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.get(ctx, s.objectName(key))
+}
+
+func (s *s3Storage) get(ctx context.Context, name string) ([]byte, error) {
+	obj, err := s.Client.GetObject(ctx, s.Bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	defer obj.Close()
+	data, err := ioutil.ReadAll(obj)
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	return data, nil
+}
+
+func (s *s3Storage) Remove(key interface{}) bool {
+	ctx, cancel := s.context()
+	defer cancel()
+	name := s.objectName(key)
+	if _, err := s.Client.StatObject(ctx, s.Bucket, name, minio.StatObjectOptions{}); err != nil {
+		return false
+	}
+	return s.Client.RemoveObject(ctx, s.Bucket, name, minio.RemoveObjectOptions{}) == nil
+}
+
+func (s *s3Storage) Flush() error {
+	return nil
+}
+
+func (s *s3Storage) Len() (n int) {
+	ctx, cancel := s.context()
+	defer cancel()
+	for obj := range s.list(ctx) {
+		if obj.Err != nil {
+			break
+		}
+		n++
+	}
+	return
+}
+
+func (s *s3Storage) list(ctx context.Context) <-chan minio.ObjectInfo {
+	return s.Client.ListObjects(ctx, s.Bucket, minio.ListObjectsOptions{Prefix: s.Prefix, Recursive: true})
+}
+
+func (s *s3Storage) Clear() error {
+	ctx, cancel := s.context()
+	defer cancel()
+	for err := range s.Client.RemoveObjects(ctx, s.Bucket, s.list(ctx), minio.RemoveObjectsOptions{}) {
+		return err.Err
+	}
+	return nil
+}
+
+func (s *s3Storage) Range(f func(key, value interface{}) bool) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	var names []string
+	for obj := range s.list(ctx) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		names = append(names, obj.Key)
+	}
+	for _, name := range names {
+		data, err := s.rangeGet(name)
+		if err == ErrKeyNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		key, err := s.keyOf(name)
+		if err != nil {
+			return err
+		}
+		if !f(key, data) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *s3Storage) rangeGet(name string) ([]byte, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.get(ctx, name)
+}
+
+func (s *s3Storage) String() string {
+	return fmt.Sprintf("S3(%s/%s)", s.Bucket, s.Prefix)
+}
+
+func s3Error(err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return ErrKeyNotFound
+	}
+	return err
+}
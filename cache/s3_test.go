@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestS3StorageObjectNames(t *testing.T) {
+
+	s := &s3Storage{S3StorageConfig{Prefix: "cache/"}}
+
+	name := s.objectName("a/b c")
+	if name != "cache/a%2Fb%20c" {
+		t.Errorf("objectName: expected cache/a%%2Fb%%20c, got %s", name)
+	}
+
+	if key, err := s.keyOf(name); key != "a/b c" || err != nil {
+		t.Errorf("keyOf: expected a/b c, <nil>, got %s, %v", key, err)
+	}
+
+	if name := s.objectName(5); name != "cache/5" {
+		t.Errorf("objectName: expected cache/5, got %s", name)
+	}
+}
+
+func TestS3StorageRejectsNonBytes(t *testing.T) {
+
+	client, err := minio.New("localhost:9000", &minio.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewS3Storage(S3StorageConfig{Client: client, Bucket: "cache"})
+
+	if err := c.Put(5, 6); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("Put: expected %v, got %v", ErrUnexpectedType, err)
+	}
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// Serializer converts values to and from bytes.
+type Serializer interface {
+	Serialize(value interface{}) ([]byte, error)
+	Unserialize(data []byte) (interface{}, error)
+}
+
+// GobSerializer uses encoding/gob, like the persistent storages of this package.
+// The concrete types of the values must be registered using gob.Register, unless they are basic types.
+var GobSerializer Serializer = gobSerializer{}
+
+type gobSerializer struct{}
+
+func (gobSerializer) Serialize(value interface{}) ([]byte, error)  { return gobEncode(value) }
+func (gobSerializer) Unserialize(data []byte) (interface{}, error) { return gobDecode(data) }
+func (gobSerializer) String() string                               { return "gob" }
+
+// Serialization adds a layer which stores the values into the underlying cache as []byte, using the serializer,
+// e.g. for storages which only accept bytes.
+// Get returns an error wrapping ErrUnexpectedType if the stored value is not a []byte.
+func Serialization(s Serializer) Option {
+	return func(c Cache) Cache {
+		return &serializingCache{c, s}
+	}
+}
+
+type serializingCache struct {
+	Cache
+	s Serializer
+}
+
+func (c *serializingCache) Put(key, value interface{}) error {
+	data, err := c.s.Serialize(value)
+	if err != nil {
+		return err
+	}
+	return c.Cache.Put(key, data)
+}
+
+func (c *serializingCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	data, err := c.s.Serialize(value)
+	if err != nil {
+		return err
+	}
+	return PutWithTTL(c.Cache, key, data, ttl)
+}
+
+func (c *serializingCache) PutWithCost(key, value interface{}, cost int64) error {
+	data, err := c.s.Serialize(value)
+	if err != nil {
+		return err
+	}
+	return PutWithCost(c.Cache, key, data, cost)
+}
+
+func (c *serializingCache) Get(key interface{}) (interface{}, error) {
+	value, _, err := c.GetWithExpiry(key)
+	return value, err
+}
+
+func (c *serializingCache) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	raw, expiresAt, err := GetWithExpiry(c.Cache, key)
+	if err != nil {
+		return
+	}
+	value, err = c.unserialize(key, raw)
+	return
+}
+
+func (c *serializingCache) unserialize(key, raw interface{}) (interface{}, error) {
+	data, ok := raw.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T for key %v in %s", ErrUnexpectedType, raw, key, c.Cache)
+	}
+	return c.s.Unserialize(data)
+}
+
+func (c *serializingCache) TTL(key interface{}) (time.Duration, error) {
+	return TTL(c.Cache, key)
+}
+
+// Range stops on the first value which cannot be unserialized, and returns the error.
+func (c *serializingCache) Range(f func(key, value interface{}) bool) (err error) {
+	rerr := Range(c.Cache, func(key, raw interface{}) bool {
+		var value interface{}
+		if value, err = c.unserialize(key, raw); err != nil {
+			return false
+		}
+		return f(key, value)
+	})
+	if rerr != nil {
+		return rerr
+	}
+	return
+}
+
+func (c *serializingCache) Clear() error {
+	return Clear(c.Cache)
+}
+
+func (c *serializingCache) notifyDrops(f dropFunc) {
+	notifyDrops(c.Cache, f)
+}
+
+func (c *serializingCache) String() string {
+	return fmt.Sprintf("Serialization(%s,%v)", c.Cache, c.s)
+}
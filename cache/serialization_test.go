@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSerialization(t *testing.T) {
+
+	u := NewMemoryStorage()
+	c := Serialization(GobSerializer)(u)
+
+	if err := c.Put(5, "six"); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	if v, err := c.Get(5); v != "six" || err != nil {
+		t.Errorf("Get: expected six, <nil>, got %v, %v", v, err)
+	}
+	if v, _ := u.Get(5); v == nil {
+		t.Error("expected serialized value")
+	} else if _, ok := v.([]byte); !ok {
+		t.Errorf("expected []byte, got %T", v)
+	}
+
+	u.Put(6, 6)
+	if _, err := c.Get(6); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("Get: expected %v, got %v", ErrUnexpectedType, err)
+	}
+}
@@ -1,59 +1,131 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
+// errCallPanicked is returned to the callers waiting for a call which panicked.
+var errCallPanicked = errors.New("single flight call panicked")
+
 type singleFlight struct {
 	Cache
 	calls map[interface{}]*call
 	sync.Mutex
 }
 
-// SingleFlight adds a layer that deduplicates Get queries from concurrent goroutines.
+// SingleFlight adds a layer that deduplicates Get and GetOrCompute queries from concurrent goroutines.
+// The first caller queries the underlying cache, while the others wait for its result.
+// A Put or a Remove of the key during the query resolves it for the waiting callers.
 func SingleFlight(c Cache) Cache {
 	return &singleFlight{Cache: c, calls: make(map[interface{}]*call)}
 }
 
 func (f *singleFlight) Put(key, value interface{}) (err error) {
+	return f.put(key, value, func() error { return f.Cache.Put(key, value) })
+}
+
+func (f *singleFlight) PutWithTTL(key, value interface{}, ttl time.Duration) (err error) {
+	return f.put(key, value, func() error { return PutWithTTL(f.Cache, key, value, ttl) })
+}
+
+func (f *singleFlight) PutWithCost(key, value interface{}, cost int64) (err error) {
+	return f.put(key, value, func() error { return PutWithCost(f.Cache, key, value, cost) })
+}
+
+func (f *singleFlight) put(key, value interface{}, put func() error) (err error) {
 	f.Lock()
 	defer f.Unlock()
-	err = f.Cache.Put(key, value)
-	c := f.calls[key]
-	if c != nil {
-		c.Resolve(value, err)
+	err = put()
+	if c := f.calls[key]; c != nil {
+		f.resolve(key, c, value, err)
 	}
 	return err
 }
 
 func (f *singleFlight) Get(key interface{}) (value interface{}, err error) {
+	c := f.do(key, func() (interface{}, error) {
+		return f.Cache.Get(key)
+	})
+	return c.value, c.err
+}
+
+func (f *singleFlight) GetOrCompute(key interface{}, compute ComputeFunc) (value interface{}, err error) {
+	process := func() (interface{}, error) {
+		return GetOrCompute(f.Cache, key, compute)
+	}
+	c := f.do(key, process)
+	if c.err != ErrKeyNotFound {
+		return c.value, c.err
+	}
+	// The pending call was a Get: compute the value unless another caller is already doing it.
+	c = f.do(key, process)
+	return c.value, c.err
+}
+
+// do joins the pending call for the key, or executes fetch in a new call, then waits for the call to be resolved.
+func (f *singleFlight) do(key interface{}, fetch func() (interface{}, error)) *call {
 	f.Lock()
 	c := f.calls[key]
 	if c == nil {
-		c = newCall(
-			func() (interface{}, error) {
-				return f.Cache.Get(key)
-			},
-			func() {
-				f.Lock()
-				delete(f.calls, key)
-				f.Unlock()
-			},
-		)
+		c = &call{done: make(chan struct{})}
 		f.calls[key] = c
+		f.Unlock()
+		f.execute(key, c, fetch)
+	} else {
+		f.Unlock()
 	}
-	f.Unlock()
-	return c.Await()
+	<-c.done
+	return c
+}
+
+// execute resolves the call with the result of fetch. If fetch panics, the waiting callers get errCallPanicked.
+func (f *singleFlight) execute(key interface{}, c *call, fetch func() (interface{}, error)) {
+	var value interface{}
+	err := errCallPanicked
+	defer func() {
+		f.Lock()
+		f.resolve(key, c, value, err)
+		f.Unlock()
+	}()
+	value, err = fetch()
+}
+
+// resolve sets the result of the call, unless a Put or a Remove already did, and unregisters it.
+// It must be called with the lock held.
+func (f *singleFlight) resolve(key interface{}, c *call, value interface{}, err error) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	if err == nil {
+		c.value = value
+	} else {
+		c.err = err
+	}
+	close(c.done)
+	if f.calls[key] == c {
+		delete(f.calls, key)
+	}
+}
+
+func (f *singleFlight) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	return GetWithExpiry(f.Cache, key)
+}
+
+func (f *singleFlight) TTL(key interface{}) (time.Duration, error) {
+	return TTL(f.Cache, key)
 }
 
 func (f *singleFlight) Remove(key interface{}) (removed bool) {
 	f.Lock()
-	c := f.calls[key]
+	defer f.Unlock()
 	removed = f.Cache.Remove(key)
-	f.Unlock()
-	if c != nil {
-		c.Resolve(nil, ErrKeyNotFound)
+	if c := f.calls[key]; c != nil {
+		f.resolve(key, c, nil, ErrKeyNotFound)
 		removed = true
 	}
 	return removed
@@ -61,58 +133,44 @@ func (f *singleFlight) Remove(key interface{}) (removed bool) {
 
 func (f *singleFlight) Flush() (err error) {
 	f.Lock()
-	var wg sync.WaitGroup
-	wg.Add(len(f.calls))
+	calls := make([]*call, 0, len(f.calls))
 	for _, c := range f.calls {
-		go func(c *call) {
-			c.Await()
-			wg.Done()
-		}(c)
+		calls = append(calls, c)
 	}
 	err = f.Cache.Flush()
 	f.Unlock()
-	wg.Wait()
+	for _, c := range calls {
+		<-c.done
+	}
 	return
 }
 
-func (f *singleFlight) String() string {
-	return fmt.Sprintf("SingleFlight(%s)", f.Cache)
+func (f *singleFlight) Clear() (err error) {
+	f.Lock()
+	defer f.Unlock()
+	if err = Clear(f.Cache); err == nil {
+		for key, c := range f.calls {
+			f.resolve(key, c, nil, ErrKeyNotFound)
+		}
+	}
+	return
 }
 
-type call struct {
-	resolved  bool
-	value     interface{}
-	err       error
-	onResolve func()
-	sync.WaitGroup
-	sync.Mutex
+func (f *singleFlight) notifyDrops(fn dropFunc) {
+	notifyDrops(f.Cache, fn)
 }
 
-func newCall(process func() (interface{}, error), onResolve func()) *call {
-	c := new(call)
-	c.onResolve = onResolve
-	c.Add(1)
-	go func() { c.Resolve(process()) }()
-	return c
+func (f *singleFlight) Range(g func(key, value interface{}) bool) error {
+	return Range(f.Cache, g)
 }
 
-func (c *call) Resolve(value interface{}, err error) {
-	c.Lock()
-	defer c.Unlock()
-	if c.resolved {
-		return
-	}
-	c.resolved = true
-	if err != nil {
-		c.err = err
-	} else {
-		c.value = value
-	}
-	go c.onResolve()
-	c.Done()
+func (f *singleFlight) String() string {
+	return fmt.Sprintf("SingleFlight(%s)", f.Cache)
 }
 
-func (c *call) Await() (interface{}, error) {
-	c.Wait()
-	return c.value, c.err
+// call is a pending Get or GetOrCompute. Its result can be read once done is closed.
+type call struct {
+	done  chan struct{}
+	value interface{}
+	err   error
 }
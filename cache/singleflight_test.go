@@ -129,3 +129,37 @@ func TestSingleFlight_Flush(t *testing.T) {
 		t.Fatal("expected non-nil value")
 	}
 }
+
+func BenchmarkSingleFlight_ColdGet(b *testing.B) {
+	c := NewLoader(func(key interface{}) (interface{}, error) { return key, nil }, SingleFlight)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Get(i)
+	}
+}
+
+func BenchmarkSingleFlight_ParallelGet(b *testing.B) {
+	c := NewMemoryStorage(SingleFlight)
+	for i := 0; i < 64; i++ {
+		c.Put(i, i)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			c.Get(i % 64)
+		}
+	})
+}
+
+func BenchmarkSingleFlight_SharedGet(b *testing.B) {
+	c := NewLoader(func(key interface{}) (interface{}, error) {
+		time.Sleep(time.Microsecond)
+		return key, nil
+	}, SingleFlight)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Get(1)
+		}
+	})
+}
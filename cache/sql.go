@@ -0,0 +1,290 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLStorageConfig configures a storage using a database/sql database.
+type SQLStorageConfig struct {
+	// DB is the database.
+	DB *sql.DB
+
+	// Table is the name of the table holding the entries. It must be a valid SQL identifier.
+	Table string
+
+	// CreateTable is the statement creating the table, if it does not exist, in which "{table}" is replaced
+	// by the table name. It defaults to a statement for SQLite.
+	// The table must have a cache_key and a cache_value binary columns, and an expires_at integer column.
+	CreateTable string
+
+	// Placeholder returns the placeholder of the nth parameter (starting at 1).
+	// It defaults to QuestionPlaceholder, while PostgreSQL needs DollarPlaceholder.
+	Placeholder func(n int) string
+
+	// PurgeInterval is the interval between the deletions of the expired rows. Zero disables the purge.
+	PurgeInterval time.Duration
+
+	// Context stops the purge when done. It defaults to context.Background().
+	Context context.Context
+
+	// Clock is used to expire the entries. It defaults to RealClock.
+	Clock Clock
+}
+
+// DefaultSQLCreateTable is the default statement creating the table of a SQL storage.
+const DefaultSQLCreateTable = `CREATE TABLE IF NOT EXISTS {table} (
+	cache_key BLOB NOT NULL PRIMARY KEY,
+	cache_value BLOB NOT NULL,
+	expires_at INTEGER
+)`
+
+// QuestionPlaceholder returns "?" parameter placeholders, as used by SQLite and MySQL.
+func QuestionPlaceholder(int) string { return "?" }
+
+// DollarPlaceholder returns "$n" parameter placeholders, as used by PostgreSQL.
+func DollarPlaceholder(n int) string { return "$" + strconv.Itoa(n) }
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// NewSQLStorage creates a cache storing its entries in a table of a SQL database, using SQLite-compatible
+// statements. See NewSQLStorageWith.
+func NewSQLStorage(db *sql.DB, table string, opts ...Option) (Cache, error) {
+	return NewSQLStorageWith(SQLStorageConfig{DB: db, Table: table}, opts...)
+}
+
+// NewSQLStorageWith creates a cache storing its entries in a table of a SQL database, creating the table if needed.
+//
+// Keys and values are gob-encoded: their concrete types must be registered using gob.Register,
+// unless they are basic types. The storage natively supports per-entry TTLs, see PutWithTTL,
+// and implements Iterable and Clearer.
+func NewSQLStorageWith(conf SQLStorageConfig, opts ...Option) (Cache, error) {
+	if !sqlIdentifier.MatchString(conf.Table) {
+		return nil, fmt.Errorf("invalid table name: %q", conf.Table)
+	}
+	if conf.CreateTable == "" {
+		conf.CreateTable = DefaultSQLCreateTable
+	}
+	if conf.Placeholder == nil {
+		conf.Placeholder = QuestionPlaceholder
+	}
+	if conf.Context == nil {
+		conf.Context = context.Background()
+	}
+	if conf.Clock == nil {
+		conf.Clock = RealClock
+	}
+
+	s := &sqlStorage{db: conf.DB, table: conf.Table, Clock: conf.Clock}
+	if _, err := s.db.Exec(strings.ReplaceAll(conf.CreateTable, "{table}", conf.Table)); err != nil {
+		return nil, fmt.Errorf("cannot create table %s: %w", conf.Table, err)
+	}
+
+	p := conf.Placeholder
+	for _, st := range []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&s.get, "SELECT cache_value, expires_at FROM %[1]s WHERE cache_key = " + p(1)},
+		{&s.insert, "INSERT INTO %[1]s (cache_key, cache_value, expires_at) VALUES (" + p(1) + ", " + p(2) + ", " + p(3) + ")"},
+		{&s.remove, "DELETE FROM %[1]s WHERE cache_key = " + p(1)},
+		{&s.count, "SELECT COUNT(*) FROM %[1]s WHERE expires_at IS NULL OR expires_at > " + p(1)},
+		{&s.list, "SELECT cache_key, cache_value FROM %[1]s WHERE expires_at IS NULL OR expires_at > " + p(1)},
+		{&s.purge, "DELETE FROM %[1]s WHERE expires_at <= " + p(1)},
+		{&s.clear, "DELETE FROM %[1]s"},
+	} {
+		stmt, err := s.db.Prepare(fmt.Sprintf(st.query, conf.Table))
+		if err != nil {
+			return nil, fmt.Errorf("cannot prepare statement: %w", err)
+		}
+		*st.stmt = stmt
+	}
+
+	if conf.PurgeInterval > 0 {
+		go s.purgeEvery(conf.Context, conf.PurgeInterval)
+	}
+
+	return options(opts).applyTo(s), nil
+}
+
+type sqlStorage struct {
+	Clock
+	db     *sql.DB
+	table  string
+	get    *sql.Stmt
+	insert *sql.Stmt
+	remove *sql.Stmt
+	count  *sql.Stmt
+	list   *sql.Stmt
+	purge  *sql.Stmt
+	clear  *sql.Stmt
+}
+
+func (s *sqlStorage) Put(key, value interface{}) error {
+	return s.put(key, value, nil)
+}
+
+func (s *sqlStorage) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	expiresAt := s.Now().Add(ttl).UnixNano()
+	return s.put(key, value, &expiresAt)
+}
+
+func (s *sqlStorage) put(key, value interface{}, expiresAt *int64) (err error) {
+	k, err := gobEncode(key)
+	if err != nil {
+		return
+	}
+	v, err := gobEncode(value)
+	if err != nil {
+		return
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return
+	}
+	if _, err = tx.Stmt(s.remove).Exec(k); err == nil {
+		_, err = tx.Stmt(s.insert).Exec(k, v, expiresAt)
+	}
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit()
+}
+
+func (s *sqlStorage) Get(key interface{}) (value interface{}, err error) {
+	value, _, err = s.GetWithExpiry(key)
+	return
+}
+
+func (s *sqlStorage) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	k, err := gobEncode(key)
+	if err != nil {
+		return
+	}
+	var (
+		data []byte
+		exp  sql.NullInt64
+	)
+	switch err = s.get.QueryRow(k).Scan(&data, &exp); {
+	case err == sql.ErrNoRows:
+		err = ErrKeyNotFound
+		return
+	case err != nil:
+		return
+	}
+	if exp.Valid {
+		expiresAt = time.Unix(0, exp.Int64)
+		if expiresAt.Before(s.Now()) {
+			s.remove.Exec(k)
+			return nil, time.Time{}, ErrKeyNotFound
+		}
+	}
+	value, err = gobDecode(data)
+	return
+}
+
+func (s *sqlStorage) TTL(key interface{}) (time.Duration, error) {
+	_, expiresAt, err := s.GetWithExpiry(key)
+	if err != nil || expiresAt.IsZero() {
+		return 0, err
+	}
+	return expiresAt.Sub(s.Now()), nil
+}
+
+func (s *sqlStorage) Remove(key interface{}) bool {
+	k, err := gobEncode(key)
+	if err != nil {
+		return false
+	}
+	res, err := s.remove.Exec(k)
+	if err != nil {
+		return false
+	}
+	n, err := res.RowsAffected()
+	return err == nil && n > 0
+}
+
+func (s *sqlStorage) Flush() error {
+	return nil
+}
+
+func (s *sqlStorage) Len() (n int) {
+	s.count.QueryRow(s.Now().UnixNano()).Scan(&n)
+	return
+}
+
+func (s *sqlStorage) Clear() error {
+	_, err := s.clear.Exec()
+	return err
+}
+
+func (s *sqlStorage) Range(f func(key, value interface{}) bool) error {
+	rows, err := s.list.Query(s.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+	// Read all the rows first, so f can use the storage.
+	var keys, values [][]byte
+	for rows.Next() {
+		var k, v []byte
+		if err := rows.Scan(&k, &v); err != nil {
+			rows.Close()
+			return err
+		}
+		keys, values = append(keys, k), append(values, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i, k := range keys {
+		key, err := gobDecode(k)
+		if err != nil {
+			return err
+		}
+		value, err := gobDecode(values[i])
+		if err != nil {
+			return err
+		}
+		if !f(key, value) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *sqlStorage) purgeEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.purge.ExecContext(ctx, s.Now().UnixNano())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *sqlStorage) String() string {
+	return fmt.Sprintf("SQL(%s)", s.table)
+}
+
+func gobEncode(value interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(&value)
+	return b.Bytes(), err
+}
+
+func gobDecode(data []byte) (value interface{}, err error) {
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return
+}
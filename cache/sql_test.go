@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLStorage(t *testing.T) {
+
+	c, err := NewSQLStorage(openTestDB(t), "cache", Spy(t.Logf))
+	if err != nil {
+		t.Fatalf("NewSQLStorage: unexpected error %v", err)
+	}
+
+	if c.Put(5, "six") != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if c.Put(5, "six") != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if v, err := c.Get(5); v != "six" || err != nil {
+		t.Errorf("Get: expected six, <nil>, got %v, %v", v, err)
+	}
+
+	if v := c.Len(); v != 1 {
+		t.Errorf("Len: expected 1, got %d", v)
+	}
+
+	if keys, err := Keys(c); len(keys) != 1 || keys[0] != 5 || err != nil {
+		t.Errorf("Keys: expected [5], <nil>, got %v, %v", keys, err)
+	}
+
+	if !c.Remove(5) {
+		t.Error("Remove: expected true")
+	}
+
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	if c.Remove(5) {
+		t.Error("Remove: expected false")
+	}
+
+	c.Put("a", 1)
+	if err := Clear(c); err != nil || c.Len() != 0 {
+		t.Errorf("Clear: expected an empty cache, got %v", err)
+	}
+}
+
+func TestSQLStorageTTL(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c, err := NewSQLStorageWith(SQLStorageConfig{DB: openTestDB(t), Table: "cache", Clock: &cl}, Spy(t.Logf))
+	if err != nil {
+		t.Fatalf("NewSQLStorageWith: unexpected error %v", err)
+	}
+
+	PutWithTTL(c, 5, 6, 2*time.Second)
+	c.Put(6, 7)
+
+	if ttl, err := TTL(c, 5); ttl != 2*time.Second || err != nil {
+		t.Errorf("TTL: expected 2s, <nil>, got %s, %v", ttl, err)
+	}
+
+	cl.Advance(3 * time.Second)
+
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	if v, err := c.Get(6); v != 7 || err != nil {
+		t.Error("Get: expected 7, <nil>")
+	}
+}
+
+func TestSQLStorageInvalidTable(t *testing.T) {
+
+	if _, err := NewSQLStorage(openTestDB(t), "cache; DROP TABLE users"); err == nil {
+		t.Error("NewSQLStorage: expected an error")
+	}
+}
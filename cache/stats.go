@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBounds are the upper bounds of the buckets of the latency histograms.
+var LatencyBounds = []time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// Stats holds the statistics of a cache, as collected by Measure.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Errors      uint64
+	Puts        uint64
+	Removes     uint64
+	Evictions   uint64
+	Expirations uint64
+
+	// GetLatency counts the Get operations by duration, which includes the time taken by the underlying
+	// loaders, if any. GetLatency[i] counts the operations which took at most LatencyBounds[i];
+	// the last item counts the slower ones.
+	GetLatency []uint64
+}
+
+// HitRatio returns the ratio of successful Get operations.
+func (s Stats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+// Measured is implemented by the caches returned by Measure.
+type Measured interface {
+	// Stats returns a snapshot of the statistics of the cache.
+	Stats() Stats
+}
+
+// Measure adds a layer which collects statistics about the operations, which can be retrieved using Stats.
+// It also counts the entries dropped by the underlying evicting and expiring layers.
+//
+// Measure should be listed first, so the resulting cache implements Measured.
+func Measure() Option {
+	return func(c Cache) Cache {
+		m := &measuringCache{Cache: c, latency: make([]atomic.Uint64, len(LatencyBounds)+1)}
+		notifyDrops(c, func(t EventType, key, value interface{}) {
+			if t == EXPIRE {
+				m.expirations.Add(1)
+			} else {
+				m.evictions.Add(1)
+			}
+		})
+		return m
+	}
+}
+
+type measuringCache struct {
+	Cache
+	hits, misses, errors   atomic.Uint64
+	puts, removes          atomic.Uint64
+	evictions, expirations atomic.Uint64
+	latency                []atomic.Uint64
+}
+
+func (m *measuringCache) Stats() Stats {
+	s := Stats{
+		Hits:        m.hits.Load(),
+		Misses:      m.misses.Load(),
+		Errors:      m.errors.Load(),
+		Puts:        m.puts.Load(),
+		Removes:     m.removes.Load(),
+		Evictions:   m.evictions.Load(),
+		Expirations: m.expirations.Load(),
+		GetLatency:  make([]uint64, len(m.latency)),
+	}
+	for i := range m.latency {
+		s.GetLatency[i] = m.latency[i].Load()
+	}
+	return s
+}
+
+func (m *measuringCache) Get(key interface{}) (value interface{}, err error) {
+	start := time.Now()
+	value, err = m.Cache.Get(key)
+	m.got(err, time.Since(start))
+	return
+}
+
+func (m *measuringCache) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, err error) {
+	start := time.Now()
+	value, expiresAt, err = GetWithExpiry(m.Cache, key)
+	m.got(err, time.Since(start))
+	return
+}
+
+func (m *measuringCache) GetOrCompute(key interface{}, f ComputeFunc) (value interface{}, err error) {
+	start := time.Now()
+	computed := false
+	value, err = GetOrCompute(m.Cache, key, func() (interface{}, error) {
+		computed = true
+		return f()
+	})
+	if computed && err == nil {
+		// A miss, followed by a successful put.
+		m.puts.Add(1)
+		m.got(ErrKeyNotFound, time.Since(start))
+	} else {
+		m.got(err, time.Since(start))
+	}
+	return
+}
+
+func (m *measuringCache) got(err error, d time.Duration) {
+	switch err {
+	case nil:
+		m.hits.Add(1)
+	case ErrKeyNotFound:
+		m.misses.Add(1)
+	default:
+		m.errors.Add(1)
+	}
+	i := 0
+	for i < len(LatencyBounds) && d > LatencyBounds[i] {
+		i++
+	}
+	m.latency[i].Add(1)
+}
+
+func (m *measuringCache) Put(key, value interface{}) (err error) {
+	if err = m.Cache.Put(key, value); err == nil {
+		m.puts.Add(1)
+	} else {
+		m.errors.Add(1)
+	}
+	return
+}
+
+func (m *measuringCache) PutWithTTL(key, value interface{}, ttl time.Duration) (err error) {
+	if err = PutWithTTL(m.Cache, key, value, ttl); err == nil {
+		m.puts.Add(1)
+	} else {
+		m.errors.Add(1)
+	}
+	return
+}
+
+func (m *measuringCache) PutWithCost(key, value interface{}, cost int64) (err error) {
+	if err = PutWithCost(m.Cache, key, value, cost); err == nil {
+		m.puts.Add(1)
+	} else {
+		m.errors.Add(1)
+	}
+	return
+}
+
+func (m *measuringCache) Remove(key interface{}) (removed bool) {
+	if removed = m.Cache.Remove(key); removed {
+		m.removes.Add(1)
+	}
+	return
+}
+
+func (m *measuringCache) TTL(key interface{}) (time.Duration, error) {
+	return TTL(m.Cache, key)
+}
+
+func (m *measuringCache) Clear() error {
+	return Clear(m.Cache)
+}
+
+func (m *measuringCache) Range(f func(key, value interface{}) bool) error {
+	return Range(m.Cache, f)
+}
+
+func (m *measuringCache) notifyDrops(f dropFunc) {
+	notifyDrops(m.Cache, f)
+}
+
+func (m *measuringCache) String() string {
+	return fmt.Sprintf("Measured(%s)", m.Cache)
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeasure(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(Measure(), Spy(t.Logf), LRUEviction(2), ExpirationUsingClock(time.Second, &cl))
+
+	c.Put(1, 10)
+	c.Put(2, 20)
+	c.Put(3, 30)
+	c.Get(3)
+	c.Get(1)
+	c.Remove(3)
+	cl.Advance(2 * time.Second)
+	c.Get(2)
+
+	s := c.(Measured).Stats()
+	t.Logf("%+v", s)
+	if s.Puts != 3 || s.Hits != 1 || s.Misses != 2 || s.Removes != 1 || s.Evictions != 1 || s.Expirations != 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	if r := s.HitRatio(); r < 0.33 || r > 0.34 {
+		t.Errorf("HitRatio: expected 0.33, got %g", r)
+	}
+	var n uint64
+	for _, c := range s.GetLatency {
+		n += c
+	}
+	if n != 3 {
+		t.Errorf("GetLatency: expected 3 operations, got %d", n)
+	}
+}
+
+func TestMeasureGetOrCompute(t *testing.T) {
+
+	c := NewMemoryStorage(Measure(), SingleFlight)
+	compute := func() (interface{}, error) { return 10, nil }
+
+	GetOrCompute(c, 1, compute)
+	GetOrCompute(c, 1, compute)
+	PutWithCost(c, 2, 20, 1)
+
+	if s := c.(Measured).Stats(); s.Puts != 2 || s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
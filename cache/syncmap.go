@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// NewSyncMapStorage creates an empty cache using a sync.Map, which scales better than NewMemoryStorage
+// for read-mostly workloads with many goroutines.
+// Its length is maintained by an atomic counter, which may be off during concurrent updates.
+func NewSyncMapStorage(opts ...Option) Cache {
+	return options(opts).applyTo(&syncMapStorage{})
+}
+
+type syncMapStorage struct {
+	items sync.Map
+	len   int64
+}
+
+func (s *syncMapStorage) Put(key, value interface{}) error {
+	if _, loaded := s.items.Swap(key, value); !loaded {
+		atomic.AddInt64(&s.len, 1)
+	}
+	return nil
+}
+
+func (s *syncMapStorage) Get(key interface{}) (interface{}, error) {
+	if value, found := s.items.Load(key); found {
+		return value, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (s *syncMapStorage) Remove(key interface{}) (removed bool) {
+	if _, removed = s.items.LoadAndDelete(key); removed {
+		atomic.AddInt64(&s.len, -1)
+	}
+	return
+}
+
+func (s *syncMapStorage) Flush() error {
+	return nil
+}
+
+func (s *syncMapStorage) Len() int {
+	return int(atomic.LoadInt64(&s.len))
+}
+
+func (s *syncMapStorage) Clear() error {
+	s.items.Range(func(key, _ interface{}) bool {
+		s.Remove(key)
+		return true
+	})
+	return nil
+}
+
+func (s *syncMapStorage) Range(f func(key, value interface{}) bool) error {
+	s.items.Range(f)
+	return nil
+}
+
+func (s *syncMapStorage) String() string {
+	return fmt.Sprintf("SyncMap(%p)", s)
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncMapStorage(t *testing.T) {
+
+	c := NewSyncMapStorage(Spy(t.Logf))
+
+	if c.Put(5, 6) != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if c.Put(5, 7) != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if v, err := c.Get(5); v != 7 || err != nil {
+		t.Error("Get: expected 7, <nil>")
+	}
+
+	if v := c.Len(); v != 1 {
+		t.Errorf("Len: expected 1, got %d", v)
+	}
+
+	if !c.Remove(5) {
+		t.Error("Remove: expected true")
+	}
+
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	if c.Remove(5) {
+		t.Error("Remove: expected false")
+	}
+
+	if v := c.Len(); v != 0 {
+		t.Errorf("Len: expected 0, got %d", v)
+	}
+}
+
+func TestSyncMapStorageConcurrency(t *testing.T) {
+
+	c := NewSyncMapStorage()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Put(i%10, i)
+			c.Get(i % 10)
+		}(i)
+	}
+	wg.Wait()
+
+	if v := c.Len(); v != 10 {
+		t.Errorf("Len: expected 10, got %d", v)
+	}
+
+	if err := Clear(c); err != nil || c.Len() != 0 {
+		t.Errorf("Clear: expected an empty cache, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotTaggable is returned by RemoveByTag when a cache does not support tags.
+var ErrNotTaggable = errors.New("cache does not support tags")
+
+// TaggedCache is implemented by caches which can attach tags to their entries, like Tagging.
+type TaggedCache interface {
+	// PutWithTags stores an entry into the cache, with the given tags.
+	PutWithTags(key, value interface{}, tags ...string) error
+
+	// RemoveByTag removes all the entries having the given tag, and returns their number.
+	RemoveByTag(tag string) int
+}
+
+// PutWithTags stores an entry into the cache, with the given tags.
+// If the cache does not implement TaggedCache, the entry is stored using Put, and the tags are ignored.
+func PutWithTags(c Cache, key, value interface{}, tags ...string) error {
+	if tc, ok := c.(TaggedCache); ok {
+		return tc.PutWithTags(key, value, tags...)
+	}
+	return c.Put(key, value)
+}
+
+// RemoveByTag removes all the entries having the given tag, and returns their number.
+// It returns an error wrapping ErrNotTaggable if the cache does not implement TaggedCache.
+func RemoveByTag(c Cache, tag string) (int, error) {
+	if tc, ok := c.(TaggedCache); ok {
+		return tc.RemoveByTag(tag), nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrNotTaggable, c)
+}
+
+// Tagging adds a layer which keeps an in-memory index of the tags of the entries, so the entries depending on the
+// same thing (e.g. everything derived from one user) can be removed at once, using RemoveByTag.
+//
+// Put, PutWithTTL and PutWithCost remove the tags of the entry. The index is updated when the underlying cache evicts
+// or expires entries. Tagging should be listed first, so the cache implements TaggedCache.
+func Tagging() Option {
+	return func(c Cache) Cache {
+		t := &taggingCache{
+			Cache: c,
+			tags:  make(map[string]map[interface{}]struct{}),
+			keys:  make(map[interface{}][]string),
+		}
+		notifyDrops(c, func(_ EventType, key, _ interface{}) {
+			t.mu.Lock()
+			t.untag(key)
+			t.mu.Unlock()
+		})
+		return t
+	}
+}
+
+type taggingCache struct {
+	Cache
+	// tags holds the keys of each tag.
+	tags map[string]map[interface{}]struct{}
+	// keys holds the tags of each key.
+	keys map[interface{}][]string
+	mu   sync.Mutex
+}
+
+func (t *taggingCache) Put(key, value interface{}) error {
+	return t.PutWithTags(key, value)
+}
+
+func (t *taggingCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	err := PutWithTTL(t.Cache, key, value, ttl)
+	t.tag(key, nil)
+	return err
+}
+
+func (t *taggingCache) PutWithCost(key, value interface{}, cost int64) error {
+	err := PutWithCost(t.Cache, key, value, cost)
+	t.tag(key, nil)
+	return err
+}
+
+// GetOrCompute stores the computed values without tags.
+func (t *taggingCache) GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error) {
+	computed := false
+	value, err := GetOrCompute(t.Cache, key, func() (interface{}, error) {
+		computed = true
+		return f()
+	})
+	if computed {
+		t.tag(key, nil)
+	}
+	return value, err
+}
+
+func (t *taggingCache) PutWithTags(key, value interface{}, tags ...string) error {
+	err := t.Cache.Put(key, value)
+	if err != nil {
+		tags = nil
+	}
+	t.tag(key, tags)
+	return err
+}
+
+func (t *taggingCache) GetWithExpiry(key interface{}) (interface{}, time.Time, error) {
+	return GetWithExpiry(t.Cache, key)
+}
+
+func (t *taggingCache) TTL(key interface{}) (time.Duration, error) {
+	return TTL(t.Cache, key)
+}
+
+// tag replaces the tags of the key.
+func (t *taggingCache) tag(key interface{}, tags []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.untag(key)
+	if len(tags) == 0 {
+		return
+	}
+	t.keys[key] = tags
+	for _, tag := range tags {
+		keys, found := t.tags[tag]
+		if !found {
+			keys = make(map[interface{}]struct{})
+			t.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// untag removes the key from the index. It must be called with the lock held.
+func (t *taggingCache) untag(key interface{}) {
+	for _, tag := range t.keys[key] {
+		if keys := t.tags[tag]; len(keys) > 1 {
+			delete(keys, key)
+		} else {
+			delete(t.tags, tag)
+		}
+	}
+	delete(t.keys, key)
+}
+
+func (t *taggingCache) Remove(key interface{}) bool {
+	removed := t.Cache.Remove(key)
+	t.mu.Lock()
+	t.untag(key)
+	t.mu.Unlock()
+	return removed
+}
+
+func (t *taggingCache) RemoveByTag(tag string) (n int) {
+	t.mu.Lock()
+	keys := make([]interface{}, 0, len(t.tags[tag]))
+	for key := range t.tags[tag] {
+		keys = append(keys, key)
+		t.untag(key)
+	}
+	t.mu.Unlock()
+	for _, key := range keys {
+		if t.Cache.Remove(key) {
+			n++
+		}
+	}
+	return
+}
+
+func (t *taggingCache) Clear() error {
+	err := Clear(t.Cache)
+	if err == nil {
+		t.mu.Lock()
+		t.tags = make(map[string]map[interface{}]struct{})
+		t.keys = make(map[interface{}][]string)
+		t.mu.Unlock()
+	}
+	return err
+}
+
+func (t *taggingCache) Range(f func(key, value interface{}) bool) error {
+	return Range(t.Cache, f)
+}
+
+func (t *taggingCache) notifyDrops(f dropFunc) {
+	notifyDrops(t.Cache, f)
+}
+
+func (t *taggingCache) String() string {
+	return fmt.Sprintf("Tagging(%s)", t.Cache)
+}
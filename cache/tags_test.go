@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTagging(t *testing.T) {
+
+	c := NewMemoryStorage(Tagging(), Spy(t.Logf), LRUEviction(4))
+
+	PutWithTags(c, "profile:42", 1, "user:42")
+	PutWithTags(c, "posts:42", 2, "user:42", "posts")
+	PutWithTags(c, "posts:43", 3, "user:43", "posts")
+	PutWithTags(c, "friends:42", 4, "user:42")
+	c.Put("friends:42", 40)
+	PutWithCost(c, "posts:43", 30, 1)
+
+	if n, err := RemoveByTag(c, "user:42"); n != 2 || err != nil {
+		t.Errorf("RemoveByTag: expected 2, <nil>, got %v, %v", n, err)
+	}
+	if v, err := c.Get("friends:42"); v != 40 || err != nil {
+		t.Errorf("Get: expected 40, <nil>, got %v, %v", v, err)
+	}
+	if v := c.Len(); v != 2 {
+		t.Errorf("Len: expected 2, got %d", v)
+	}
+	if n, _ := RemoveByTag(c, "posts"); n != 0 {
+		t.Errorf("RemoveByTag: expected 0, got %v", n)
+	}
+	if v, err := GetOrCompute(c, "posts:43", nil); v != 30 || err != nil {
+		t.Errorf("GetOrCompute: expected 30, <nil>, got %v, %v", v, err)
+	}
+
+	// Evicted entries leave the index.
+	for i := 0; i < 4; i++ {
+		c.Put(i, i)
+	}
+	if n, _ := RemoveByTag(c, "posts"); n != 0 {
+		t.Errorf("RemoveByTag: expected 0, got %v", n)
+	}
+	if v := c.(*taggingCache).tags; len(v) != 0 {
+		t.Errorf("tags: expected empty index, got %v", v)
+	}
+
+	if _, err := RemoveByTag(NewMemoryStorage(), "posts"); !errors.Is(err, ErrNotTaggable) {
+		t.Errorf("RemoveByTag: expected %v, got %v", ErrNotTaggable, err)
+	}
+}
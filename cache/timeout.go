@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is returned by the operations which exceed the delay of a Timeout layer.
+var ErrTimeout = errors.New("cache operation timed out")
+
+// Timeout adds a layer which bounds the duration of Get, Put and Remove operations on the underlying cache.
+// Get and Put return ErrTimeout when they exceed the delay, and Remove returns false. GetOrCompute is bounded too,
+// including the computation of the value.
+//
+// The underlying operation is not cancelled: it goes on in the background and its result is discarded.
+func Timeout(d time.Duration) Option {
+	return func(c Cache) Cache {
+		return &timeoutCache{c, d}
+	}
+}
+
+type timeoutCache struct {
+	Cache
+	d time.Duration
+}
+
+// withTimeout runs op in a goroutine, and returns its result unless it exceeds the delay.
+func withTimeout[T any](t *timeoutCache, key interface{}, op func() (T, error)) (value T, err error) {
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := op()
+		done <- result{value, err}
+	}()
+	timer := time.NewTimer(t.d)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		err = fmt.Errorf("%w: key %v in %s after %s", ErrTimeout, key, t.Cache, t.d)
+		return
+	}
+}
+
+func (t *timeoutCache) Put(key, value interface{}) error {
+	_, err := withTimeout(t, key, func() (struct{}, error) {
+		return struct{}{}, t.Cache.Put(key, value)
+	})
+	return err
+}
+
+func (t *timeoutCache) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	_, err := withTimeout(t, key, func() (struct{}, error) {
+		return struct{}{}, PutWithTTL(t.Cache, key, value, ttl)
+	})
+	return err
+}
+
+func (t *timeoutCache) PutWithCost(key, value interface{}, cost int64) error {
+	_, err := withTimeout(t, key, func() (struct{}, error) {
+		return struct{}{}, PutWithCost(t.Cache, key, value, cost)
+	})
+	return err
+}
+
+func (t *timeoutCache) GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error) {
+	return withTimeout(t, key, func() (interface{}, error) {
+		return GetOrCompute(t.Cache, key, f)
+	})
+}
+
+func (t *timeoutCache) Get(key interface{}) (interface{}, error) {
+	return withTimeout(t, key, func() (interface{}, error) {
+		return t.Cache.Get(key)
+	})
+}
+
+func (t *timeoutCache) Remove(key interface{}) bool {
+	removed, _ := withTimeout(t, key, func() (bool, error) {
+		return t.Cache.Remove(key), nil
+	})
+	return removed
+}
+
+func (t *timeoutCache) Clear() error {
+	return Clear(t.Cache)
+}
+
+func (t *timeoutCache) Range(f func(key, value interface{}) bool) error {
+	return Range(t.Cache, f)
+}
+
+func (t *timeoutCache) notifyDrops(f dropFunc) {
+	notifyDrops(t.Cache, f)
+}
+
+func (t *timeoutCache) String() string {
+	return fmt.Sprintf("Timeout(%s,%s)", t.Cache, t.d)
+}
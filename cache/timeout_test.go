@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+
+	g := &gatedCache{NewMemoryStorage(), make(chan struct{})}
+	c := options{Spy(t.Logf), Timeout(10 * time.Millisecond)}.applyTo(g)
+
+	if err := c.Put(1, 10); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Put: expected %v, got %v", ErrTimeout, err)
+	}
+
+	close(g.gate)
+	if err := c.Put(2, 20); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	if v, err := c.Get(2); v != 20 || err != nil {
+		t.Errorf("Get: expected 20, <nil>, got %v, %v", v, err)
+	}
+	if v, err := c.Get(3); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+	if !c.Remove(2) {
+		t.Error("Remove: expected true")
+	}
+
+	slow := func() (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return 40, nil
+	}
+	if _, err := GetOrCompute(c, 4, slow); !errors.Is(err, ErrTimeout) {
+		t.Errorf("GetOrCompute: expected %v, got %v", ErrTimeout, err)
+	}
+}
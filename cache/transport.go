@@ -0,0 +1,261 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TransportConfig holds the configuration of NewTransportWith.
+type TransportConfig struct {
+	// Inner performs the requests. It defaults to http.DefaultTransport.
+	Inner http.RoundTripper
+
+	// MaxBodySize is the size above which the responses are not cached. It defaults to 1 MiB.
+	MaxBodySize int
+}
+
+// NewTransport returns a http.RoundTripper which stores the responses to GET requests in c, keyed by URL.
+// inner performs the requests. It defaults to http.DefaultTransport.
+//
+// Cached responses are served as long as they are fresh, according to their Cache-Control max-age directive or
+// to their Expires header. Stale responses having an ETag or a Last-Modified header are revalidated using a
+// conditional request. The no-store, no-cache and private directives are honored, and the responses with a Vary
+// or a Set-Cookie header are not cached. Responses served from the cache have a X-From-Cache header.
+//
+// The responses are stored in their HTTP/1.1 wire format, using a Serialization layer, so c can be any storage.
+// The errors of the cache are ignored: the requests are then performed as if there was no cache.
+func NewTransport(c Cache, inner http.RoundTripper) http.RoundTripper {
+	return NewTransportWith(c, TransportConfig{Inner: inner})
+}
+
+// NewTransportWith returns a http.RoundTripper which stores the responses to GET requests in c, like NewTransport.
+func NewTransportWith(c Cache, conf TransportConfig) http.RoundTripper {
+	if conf.Inner == nil {
+		conf.Inner = http.DefaultTransport
+	}
+	if conf.MaxBodySize <= 0 {
+		conf.MaxBodySize = 1 << 20
+	}
+	return &cachingTransport{Serialization(responseSerializer{})(c), conf.Inner, int64(conf.MaxBodySize)}
+}
+
+type cachingTransport struct {
+	cache   Cache
+	inner   http.RoundTripper
+	maxSize int64
+}
+
+// storedResponse is a response, as stored in the cache.
+type storedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.inner.RoundTrip(req)
+	}
+	reqCC := cacheControl(req.Header)
+	if _, noStore := reqCC["no-store"]; noStore {
+		return t.inner.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	var stored *storedResponse
+	if _, noCache := reqCC["no-cache"]; !noCache {
+		if value, err := t.cache.Get(key); err == nil {
+			stored, _ = value.(*storedResponse)
+		}
+	}
+	if stored != nil && stored.fresh(time.Now()) {
+		return stored.response(req), nil
+	}
+
+	out := req
+	if stored != nil {
+		out = stored.revalidation(req)
+	}
+	resp, err := t.inner.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	if out != req && resp.StatusCode == http.StatusNotModified {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		for name, values := range resp.Header {
+			if name != "Content-Length" {
+				stored.Header[name] = values
+			}
+		}
+		t.cache.Put(key, stored)
+		return stored.response(req), nil
+	}
+	return t.store(key, resp)
+}
+
+// store puts the response in the cache, if it can be cached, and returns it with a readable body.
+func (t *cachingTransport) store(key string, resp *http.Response) (*http.Response, error) {
+	if !storable(resp) || resp.ContentLength > t.maxSize {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, t.maxSize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.maxSize {
+		// Too large: give back the body as read so far, followed by the rest.
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	t.cache.Put(key, &storedResponse{resp.StatusCode, resp.Header.Clone(), body})
+	return resp, nil
+}
+
+// storable tells whether a response can be stored, and later be served or revalidated.
+func storable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMovedPermanently, http.StatusNotFound,
+		http.StatusGone:
+	default:
+		return false
+	}
+	h := resp.Header
+	if h.Get("Vary") != "" || h.Get("Set-Cookie") != "" {
+		return false
+	}
+	cc := cacheControl(h)
+	for _, directive := range []string{"no-store", "private"} {
+		if _, found := cc[directive]; found {
+			return false
+		}
+	}
+	return h.Get("ETag") != "" || h.Get("Last-Modified") != "" || lifetime(h, cc) > 0
+}
+
+// fresh tells whether the response can be served without revalidation.
+func (s *storedResponse) fresh(now time.Time) bool {
+	cc := cacheControl(s.Header)
+	if _, noCache := cc["no-cache"]; noCache {
+		return false
+	}
+	date, err := http.ParseTime(s.Header.Get("Date"))
+	return err == nil && now.Before(date.Add(lifetime(s.Header, cc)))
+}
+
+// lifetime returns the freshness lifetime of a response.
+func lifetime(h http.Header, cc map[string]string) time.Duration {
+	if maxAge, found := cc["max-age"]; found {
+		seconds, _ := strconv.Atoi(maxAge)
+		return time.Duration(seconds) * time.Second
+	}
+	expires, err := http.ParseTime(h.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		return 0
+	}
+	return expires.Sub(date)
+}
+
+// revalidation returns a conditional copy of the request, or the request itself if the response has no validator
+// or if the request is already conditional.
+func (s *storedResponse) revalidation(req *http.Request) *http.Request {
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return req
+	}
+	etag, modified := s.Header.Get("ETag"), s.Header.Get("Last-Modified")
+	if etag == "" && modified == "" {
+		return req
+	}
+	out := req.Clone(req.Context())
+	if etag != "" {
+		out.Header.Set("If-None-Match", etag)
+	}
+	if modified != "" {
+		out.Header.Set("If-Modified-Since", modified)
+	}
+	return out
+}
+
+func (s *storedResponse) response(req *http.Request) *http.Response {
+	h := s.Header.Clone()
+	h.Set("X-From-Cache", "1")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", s.StatusCode, http.StatusText(s.StatusCode)),
+		StatusCode:    s.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          ioutil.NopCloser(bytes.NewReader(s.Body)),
+		ContentLength: int64(len(s.Body)),
+		Request:       req,
+	}
+}
+
+func cacheControl(h http.Header) map[string]string {
+	cc := make(map[string]string)
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+		name, arg := directive, ""
+		if eq := strings.IndexByte(directive, '='); eq >= 0 {
+			name, arg = directive[:eq], strings.Trim(directive[eq+1:], `"`)
+		}
+		cc[strings.ToLower(name)] = arg
+	}
+	return cc
+}
+
+// responseSerializer stores the responses in the HTTP/1.1 wire format.
+type responseSerializer struct{}
+
+func (responseSerializer) Serialize(value interface{}) ([]byte, error) {
+	s, ok := value.(*storedResponse)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedType, value)
+	}
+	var b bytes.Buffer
+	err := (&http.Response{
+		StatusCode:    s.StatusCode,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        s.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(s.Body)),
+		ContentLength: int64(len(s.Body)),
+	}).Write(&b)
+	return b.Bytes(), err
+}
+
+func (responseSerializer) Unserialize(data []byte) (interface{}, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &storedResponse{resp.StatusCode, resp.Header, body}, nil
+}
+
+func (responseSerializer) String() string { return "http" }
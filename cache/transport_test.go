@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+
+	hits := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.URL.Path]++
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		}
+		w.Write([]byte("hello " + r.URL.Path))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(NewMemoryStorage(Spy(t.Logf)), nil)}
+	get := func(path string, fromCache bool) {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: unexpected error %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != "hello "+path {
+			t.Errorf("GET %s: expected 200 hello %s, got %d %s", path, path, resp.StatusCode, body)
+		}
+		if v := resp.Header.Get("X-From-Cache") != ""; v != fromCache {
+			t.Errorf("GET %s: expected X-From-Cache to be %v, got %v", path, fromCache, v)
+		}
+	}
+
+	for _, path := range []string{"/fresh", "/etag", "/private"} {
+		get(path, false)
+	}
+	get("/fresh", true)
+	get("/etag", true)
+	get("/private", false)
+
+	if hits["/fresh"] != 1 || hits["/etag"] != 2 || hits["/private"] != 2 {
+		t.Errorf("hits: expected map[/etag:2 /fresh:1 /private:2], got %v", hits)
+	}
+}
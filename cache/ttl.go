@@ -0,0 +1,19 @@
+package cache
+
+import "time"
+
+// TTLCache is implemented by caches which can store entries with a specific lifetime.
+// Expiration implements it, and the other options of this package forward it to their underlying cache.
+type TTLCache interface {
+	// PutWithTTL stores an entry into the cache, which expires after the given delay.
+	PutWithTTL(key, value interface{}, ttl time.Duration) error
+}
+
+// PutWithTTL stores an entry into the cache, which expires after the given delay.
+// If the cache does not implement TTLCache, the entry is stored using Put, with the default lifetime, if any.
+func PutWithTTL(c Cache, key, value interface{}, ttl time.Duration) error {
+	if tc, ok := c.(TTLCache); ok {
+		return tc.PutWithTTL(key, value, ttl)
+	}
+	return c.Put(key, value)
+}
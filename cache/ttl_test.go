@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPutWithTTL(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewMemoryStorage(Spy(t.Logf), LRUEviction(10), ExpirationUsingClock(time.Second, &cl))
+
+	if err := PutWithTTL(c, 5, 6, 3*time.Second); err != nil {
+		t.Errorf("PutWithTTL: unexpected error %v", err)
+	}
+	c.Put(6, 7)
+
+	cl.Advance(2 * time.Second)
+
+	if v, err := c.Get(5); v != 6 || err != nil {
+		t.Error("Get: expected 6, <nil>")
+	}
+
+	if v, err := c.Get(6); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	cl.Advance(2 * time.Second)
+
+	if v, err := c.Get(5); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+}
+
+func TestPutWithTTLFallback(t *testing.T) {
+
+	c := NewMemoryStorage(Spy(t.Logf))
+
+	if err := PutWithTTL(c, 5, 6, time.Nanosecond); err != nil {
+		t.Errorf("PutWithTTL: unexpected error %v", err)
+	}
+
+	if v, err := c.Get(5); v != 6 || err != nil {
+		t.Error("Get: expected 6, <nil>")
+	}
+}
@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnexpectedType is returned by typed caches when a value does not have the expected type.
+var ErrUnexpectedType = errors.New("unexpected value type")
+
+// TypedCache is the type-safe counterpart of Cache.
+type TypedCache[K comparable, V any] interface {
+	fmt.Stringer
+
+	// Put stores an entry into the cache.
+	Put(key K, value V) error
+
+	// Get fetchs an entry from the cache.
+	// It returns the zero value and ErrKeyNotFound when the key is not present.
+	Get(key K) (value V, err error)
+
+	// Remove removes an entry from the cache.
+	// It returns whether the entry was actually found and removed.
+	Remove(key K) bool
+
+	// Flush instructs the cache to finish all pending operations, if any.
+	Flush() error
+
+	// Len returns the number of entries in the cache.
+	Len() int
+}
+
+// TypedIterable is the type-safe counterpart of Iterable.
+type TypedIterable[K comparable, V any] interface {
+	// Range calls f for each entry of the cache, until f returns false.
+	Range(f func(key K, value V) bool) error
+}
+
+// Typed wraps an untyped Cache into a TypedCache, which also implements TypedIterable.
+// Get returns an error wrapping ErrUnexpectedType if the value does not have type V.
+// Range skips the entries of unexpected types.
+func Typed[K comparable, V any](c Cache) TypedCache[K, V] {
+	if u, ok := c.(*untypedCache[K, V]); ok {
+		return u.c
+	}
+	return &typedCache[K, V]{c}
+}
+
+// Untyped wraps a TypedCache into an untyped Cache, e.g. to apply Options.
+// Put and Get return an error wrapping ErrUnexpectedType if the key or the value does not have the expected type.
+func Untyped[K comparable, V any](c TypedCache[K, V]) Cache {
+	if t, ok := c.(*typedCache[K, V]); ok {
+		return t.Cache
+	}
+	return &untypedCache[K, V]{c}
+}
+
+type typedCache[K comparable, V any] struct {
+	Cache
+}
+
+func (t *typedCache[K, V]) Put(key K, value V) error {
+	return t.Cache.Put(key, value)
+}
+
+func (t *typedCache[K, V]) Get(key K) (value V, err error) {
+	raw, err := t.Cache.Get(key)
+	if err != nil || raw == nil {
+		return
+	}
+	value, ok := raw.(V)
+	if !ok {
+		err = fmt.Errorf("%w: %T for key %v in %s", ErrUnexpectedType, raw, key, t.Cache)
+	}
+	return
+}
+
+func (t *typedCache[K, V]) Remove(key K) bool {
+	return t.Cache.Remove(key)
+}
+
+func (t *typedCache[K, V]) Clear() error {
+	return Clear(t.Cache)
+}
+
+func (t *typedCache[K, V]) Range(f func(key K, value V) bool) error {
+	return Range(t.Cache, func(key, raw interface{}) bool {
+		k, ok := key.(K)
+		if !ok {
+			return true
+		}
+		value, ok := raw.(V)
+		if !ok && raw != nil {
+			return true
+		}
+		return f(k, value)
+	})
+}
+
+type untypedCache[K comparable, V any] struct {
+	c TypedCache[K, V]
+}
+
+func (u *untypedCache[K, V]) Put(key, value interface{}) error {
+	k, ok := key.(K)
+	if !ok {
+		return fmt.Errorf("%w: key %T in %s", ErrUnexpectedType, key, u.c)
+	}
+	v, ok := value.(V)
+	if !ok && value != nil {
+		return fmt.Errorf("%w: %T for key %v in %s", ErrUnexpectedType, value, key, u.c)
+	}
+	return u.c.Put(k, v)
+}
+
+func (u *untypedCache[K, V]) Get(key interface{}) (interface{}, error) {
+	k, ok := key.(K)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	value, err := u.c.Get(k)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (u *untypedCache[K, V]) Remove(key interface{}) bool {
+	k, ok := key.(K)
+	return ok && u.c.Remove(k)
+}
+
+func (u *untypedCache[K, V]) Range(f func(key, value interface{}) bool) error {
+	if it, ok := u.c.(TypedIterable[K, V]); ok {
+		return it.Range(func(key K, value V) bool { return f(key, value) })
+	}
+	return fmt.Errorf("%w: %s", ErrNotIterable, u.c)
+}
+
+func (u *untypedCache[K, V]) Clear() error {
+	if cl, ok := u.c.(Clearer); ok {
+		return cl.Clear()
+	}
+	return fmt.Errorf("%w: %s", ErrNotClearable, u.c)
+}
+
+func (u *untypedCache[K, V]) Flush() error   { return u.c.Flush() }
+func (u *untypedCache[K, V]) Len() int       { return u.c.Len() }
+func (u *untypedCache[K, V]) String() string { return u.c.String() }
+
+// NewTypedMemoryStorage creates an empty typed cache using a map and a sync.RWMutex.
+//
+// Without options, the entries are stored without boxing. As options may wrap the values (e.g. Expiration),
+// they are otherwise applied to an untyped memory storage.
+func NewTypedMemoryStorage[K comparable, V any](opts ...Option) TypedCache[K, V] {
+	if len(opts) > 0 {
+		return Typed[K, V](NewMemoryStorage(opts...))
+	}
+	return &typedMemoryStorage[K, V]{items: make(map[K]V)}
+}
+
+type typedMemoryStorage[K comparable, V any] struct {
+	items map[K]V
+	mu    sync.RWMutex
+}
+
+func (s *typedMemoryStorage[K, V]) Put(key K, value V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = value
+	return nil
+}
+
+func (s *typedMemoryStorage[K, V]) Get(key K) (value V, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, found := s.items[key]
+	if !found {
+		err = ErrKeyNotFound
+	}
+	return
+}
+
+func (s *typedMemoryStorage[K, V]) Remove(key K) (removed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, removed = s.items[key]; removed {
+		delete(s.items, key)
+	}
+	return
+}
+
+func (s *typedMemoryStorage[K, V]) Flush() error {
+	return nil
+}
+
+func (s *typedMemoryStorage[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.items)
+}
+
+func (s *typedMemoryStorage[K, V]) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[K]V)
+	return nil
+}
+
+func (s *typedMemoryStorage[K, V]) Range(f func(key K, value V) bool) error {
+	s.mu.RLock()
+	keys := make([]K, 0, len(s.items))
+	values := make([]V, 0, len(s.items))
+	for key, value := range s.items {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	s.mu.RUnlock()
+	for i, key := range keys {
+		if !f(key, values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *typedMemoryStorage[K, V]) String() string {
+	return fmt.Sprintf("TypedMemory(%p)", s.items)
+}
+
+// TypedLoaderFunc is the type-safe counterpart of LoaderFunc.
+type TypedLoaderFunc[K comparable, V any] func(K) (V, error)
+
+// Untyped converts the TypedLoaderFunc into a LoaderFunc.
+func (f TypedLoaderFunc[K, V]) Untyped() LoaderFunc {
+	return func(key interface{}) (interface{}, error) {
+		k, ok := key.(K)
+		if !ok {
+			return nil, fmt.Errorf("%w: key %T", ErrUnexpectedType, key)
+		}
+		return f(k)
+	}
+}
+
+// NewTypedLoader creates a typed pseudo-cache from a TypedLoaderFunc.
+func NewTypedLoader[K comparable, V any](f TypedLoaderFunc[K, V], opts ...Option) TypedCache[K, V] {
+	return Typed[K, V](NewLoader(f.Untyped(), opts...))
+}
+
+// TypedLoader adds a layer to generate values on demand using a TypedLoaderFunc.
+func TypedLoader[K comparable, V any](f TypedLoaderFunc[K, V]) Option {
+	return Loader(f.Untyped())
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTypedMemoryStorage(t *testing.T) {
+
+	c := NewTypedMemoryStorage[string, int]()
+
+	if c.Put("a", 6) != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if v, err := c.Get("a"); v != 6 || err != nil {
+		t.Error("Get: expected 6, <nil>")
+	}
+
+	if v := c.Len(); v != 1 {
+		t.Error("Len: expected 1")
+	}
+
+	if !c.Remove("a") {
+		t.Error("Remove: expected true")
+	}
+
+	if v, err := c.Get("a"); v != 0 || err != ErrKeyNotFound {
+		t.Errorf("Get: expected 0, %v", ErrKeyNotFound)
+	}
+}
+
+func TestTypedMemoryStorageWithOptions(t *testing.T) {
+
+	cl := FakeClock(time.Unix(0, 0))
+	c := NewTypedMemoryStorage[string, int](Spy(t.Logf), ExpirationUsingClock(time.Second, &cl))
+
+	if c.Put("a", 6) != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if v, err := c.Get("a"); v != 6 || err != nil {
+		t.Error("Get: expected 6, <nil>")
+	}
+
+	cl.Advance(2 * time.Second)
+
+	if v, err := c.Get("a"); v != 0 || err != ErrKeyNotFound {
+		t.Errorf("Get: expected 0, %v", ErrKeyNotFound)
+	}
+}
+
+func TestTyped(t *testing.T) {
+
+	u := NewMemoryStorage()
+	c := Typed[int, string](u)
+
+	if c.Put(5, "five") != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if v, err := c.Get(5); v != "five" || err != nil {
+		t.Error("Get: expected five, <nil>")
+	}
+
+	u.Put(6, 6)
+
+	if _, err := c.Get(6); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("Get: expected %v, got %v", ErrUnexpectedType, err)
+	}
+
+	if Untyped(c) != u {
+		t.Error("Untyped: expected the wrapped cache")
+	}
+}
+
+func TestUntyped(t *testing.T) {
+
+	c := NewTypedMemoryStorage[int, string]()
+	u := Untyped(c)
+
+	if err := u.Put(5, 5); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("Put: expected %v, got %v", ErrUnexpectedType, err)
+	}
+
+	if err := u.Put(5, "five"); err != nil {
+		t.Error("Put: expected <nil>")
+	}
+
+	if v, err := u.Get(5); v != "five" || err != nil {
+		t.Error("Get: expected five, <nil>")
+	}
+
+	if v, err := u.Get("5"); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v", ErrKeyNotFound)
+	}
+
+	if Typed[int, string](u) != c {
+		t.Error("Typed: expected the wrapped cache")
+	}
+}
+
+func TestTypedLoader(t *testing.T) {
+
+	c := NewTypedMemoryStorage[int, string](
+		TypedLoader(TypedLoaderFunc[int, string](func(k int) (string, error) {
+			t.Logf("Load %v", k)
+			return strconv.Itoa(k), nil
+		})),
+		Spy(t.Logf),
+	)
+
+	if v, err := c.Get(5); v != "5" || err != nil {
+		t.Error("Get: expected 5, <nil>")
+	}
+
+	if v := c.Len(); v != 1 {
+		t.Error("Len: expected 1")
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Invalidator is implemented by the caches which can abandon all their entries at once, like Versioned.
+type Invalidator interface {
+	// Invalidate abandons all the current entries of the cache.
+	Invalidate() error
+}
+
+// Versioned adds a layer which mixes a generation number into every key. Invalidate bumps the generation, which
+// abandons all the previous entries at once, without scanning the underlying cache, unlike Clear.
+//
+// The generation is stored in the underlying cache, so it survives restarts of persistent storages. When it is
+// missing, e.g. after an eviction, a new one is derived from the current time. A failure to read or to store it is
+// reported by Flush. The abandoned entries are left to eviction or expiration: Len counts them, but Range skips
+// them. Versioned should be listed first, so the cache implements Invalidator.
+func Versioned() Option {
+	return func(c Cache) Cache {
+		v := &versionedCache{}
+		v.hashedKeys = hashedKeys{c, v.versionedKey}
+		gen, err := c.Get(generationKey)
+		if g, ok := gen.(uint64); ok && err == nil {
+			v.gen.Store(g)
+		} else if err == ErrKeyNotFound {
+			// The generation may have been evicted: seed it from the clock, so the abandoned generations are not
+			// reused.
+			v.gen.Store(uint64(time.Now().UnixNano()))
+			v.err = v.Cache.Put(generationKey, v.gen.Load())
+		} else {
+			if err == nil {
+				err = fmt.Errorf("%w: %T", ErrUnexpectedType, gen)
+			}
+			v.err = fmt.Errorf("cannot read generation of %s: %w", c, err)
+		}
+		return v
+	}
+}
+
+// versionedKey is the actual key of an entry in the underlying cache of a versioned layer.
+type versionedKey struct {
+	Generation uint64
+	Key        interface{}
+}
+
+// generationKey holds the current generation. The entries use positive generations.
+var generationKey = versionedKey{}
+
+func init() {
+	gob.Register(versionedKey{})
+}
+
+type versionedCache struct {
+	hashedKeys
+	gen atomic.Uint64
+	err error
+}
+
+func (v *versionedCache) versionedKey(key interface{}) (interface{}, error) {
+	return versionedKey{v.gen.Load(), key}, nil
+}
+
+func (v *versionedCache) Invalidate() error {
+	return v.Cache.Put(generationKey, v.gen.Add(1))
+}
+
+func (v *versionedCache) Clear() error {
+	if err := Clear(v.Cache); err != nil {
+		return err
+	}
+	return v.Cache.Put(generationKey, v.gen.Load())
+}
+
+func (v *versionedCache) Flush() error {
+	if v.err != nil {
+		return v.err
+	}
+	return v.Cache.Flush()
+}
+
+func (v *versionedCache) Range(f func(key, value interface{}) bool) error {
+	gen := v.gen.Load()
+	return Range(v.Cache, func(key, value interface{}) bool {
+		if k, ok := key.(versionedKey); ok && k.Generation == gen {
+			return f(k.Key, value)
+		}
+		return true
+	})
+}
+
+func (v *versionedCache) notifyDrops(f dropFunc) {
+	notifyDrops(v.Cache, func(t EventType, key, value interface{}) {
+		if k, ok := key.(versionedKey); ok && k.Generation != 0 {
+			f(t, k.Key, value)
+		}
+	})
+}
+
+func (v *versionedCache) String() string {
+	return fmt.Sprintf("Versioned(%s,%d)", v.Cache, v.gen.Load())
+}
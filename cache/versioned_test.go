@@ -0,0 +1,51 @@
+package cache
+
+import "testing"
+
+func TestVersioned(t *testing.T) {
+
+	s := NewMemoryStorage()
+	c := options{Versioned(), Spy(t.Logf)}.applyTo(s)
+
+	c.Put(1, 10)
+	c.Put(2, 20)
+	if v, err := c.Get(1); v != 10 || err != nil {
+		t.Errorf("Get: expected 10, <nil>, got %v, %v", v, err)
+	}
+
+	if err := c.(Invalidator).Invalidate(); err != nil {
+		t.Errorf("Invalidate: unexpected error %v", err)
+	}
+	if v, err := c.Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+
+	c.Put(2, 200)
+	keys, _ := Keys(c)
+	if len(keys) != 1 || keys[0] != 2 {
+		t.Errorf("Keys: expected [2], got %v", keys)
+	}
+
+	// The generation is read back from the storage.
+	c = Versioned()(s)
+	if v, err := c.Get(2); v != 200 || err != nil {
+		t.Errorf("Get: expected 200, <nil>, got %v, %v", v, err)
+	}
+	if err := c.Flush(); err != nil {
+		t.Errorf("Flush: unexpected error %v", err)
+	}
+}
+
+func TestVersionedLostGeneration(t *testing.T) {
+
+	s := NewMemoryStorage()
+	c := Versioned()(s)
+	c.Put(1, 10)
+
+	// The generation is evicted: the abandoned entries must not come back.
+	s.Remove(generationKey)
+	c = Versioned()(s)
+	if v, err := c.Get(1); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+}
@@ -0,0 +1,334 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned by write-behind caches using OverflowDrop when the write queue is full.
+var ErrQueueFull = errors.New("write queue full")
+
+// OverflowPolicy tells what a write-behind cache does when its write queue is full.
+type OverflowPolicy uint8
+
+// OverflowPolicy values
+const (
+	// OverflowBlock waits for room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowWriteThrough writes the entry synchronously.
+	OverflowWriteThrough
+	// OverflowDrop drops the write and returns ErrQueueFull.
+	OverflowDrop
+)
+
+// WriteBehindConfig holds the configuration of WriteBehindWith.
+type WriteBehindConfig struct {
+	// QueueSize is the maximum number of pending writes. It defaults to 1000.
+	QueueSize int
+	// Workers is the number of goroutines writing to the underlying cache. It defaults to 1.
+	Workers int
+	// Overflow tells what to do when the queue is full. It defaults to OverflowBlock.
+	Overflow OverflowPolicy
+	// OnError is called with the writes that failed, if not nil.
+	OnError func(key, value interface{}, err error)
+	// Context stops the workers when done, once the queued writes have been applied. It defaults to
+	// context.Background(), with which the workers run as long as the program.
+	Context context.Context
+}
+
+// WriteBehind adds a layer which acknowledges the writes immediately, and applies them asynchronously to the
+// underlying cache, using the given number of workers. It blocks when the queue is full.
+func WriteBehind(queueSize int, workers int) Option {
+	return WriteBehindWith(WriteBehindConfig{QueueSize: queueSize, Workers: workers})
+}
+
+// WriteBehindWith adds a layer which acknowledges the writes immediately, and applies them asynchronously to the
+// underlying cache.
+//
+// Successive writes to the same key are coalesced while they are queued, and are applied in order.
+// Get sees the pending writes, but Len and Range only see the ones which have been applied.
+// Flush waits for the queue to be drained, then returns the first write error since the previous Flush, if any.
+// Once the Context is done, the queues are closed and the writes are applied synchronously, so cancelling it releases
+// the workers of a discarded cache.
+func WriteBehindWith(conf WriteBehindConfig) Option {
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = 1000
+	}
+	if conf.Workers <= 0 {
+		conf.Workers = 1
+	}
+	if conf.Context == nil {
+		conf.Context = context.Background()
+	}
+	return func(c Cache) Cache {
+		w := &writeBehind{
+			Cache:   c,
+			conf:    conf,
+			pending: make(map[interface{}]*pendingWrite),
+			queues:  make([]chan interface{}, conf.Workers),
+			seed:    maphash.MakeSeed(),
+		}
+		w.drained = sync.NewCond(&w.mu)
+		size := (conf.QueueSize + conf.Workers - 1) / conf.Workers
+		w.workers.Add(len(w.queues))
+		for i := range w.queues {
+			w.queues[i] = make(chan interface{}, size)
+			go w.work(w.queues[i])
+		}
+		if done := conf.Context.Done(); done != nil {
+			go w.stopOn(done)
+		}
+		return w
+	}
+}
+
+type writeBehind struct {
+	Cache
+	conf WriteBehindConfig
+	// pending holds the last write of each key, until it has been applied.
+	pending map[interface{}]*pendingWrite
+	queues  []chan interface{}
+	seed    maphash.Seed
+	queued  int
+	err     error
+	mu      sync.Mutex
+	drained *sync.Cond
+	// stopped is set once the queues are closed, or about to be.
+	stopped bool
+	workers sync.WaitGroup
+}
+
+type pendingWrite struct {
+	value    interface{}
+	ttl      time.Duration
+	cost     int64
+	withCost bool
+	remove   bool
+	// taken is set once a worker is applying the write, so it cannot be updated anymore.
+	taken bool
+}
+
+func (w *writeBehind) Put(key, value interface{}) error {
+	return w.enqueue(key, pendingWrite{value: value})
+}
+
+func (w *writeBehind) PutWithTTL(key, value interface{}, ttl time.Duration) error {
+	return w.enqueue(key, pendingWrite{value: value, ttl: ttl})
+}
+
+func (w *writeBehind) PutWithCost(key, value interface{}, cost int64) error {
+	return w.enqueue(key, pendingWrite{value: value, cost: cost, withCost: true})
+}
+
+// GetOrCompute uses the pending write of the key, if any. Otherwise, it is forwarded to the underlying cache, so
+// the computed value is stored synchronously.
+func (w *writeBehind) GetOrCompute(key interface{}, f ComputeFunc) (interface{}, error) {
+	p, found := w.lookup(key)
+	switch {
+	case !found:
+		return GetOrCompute(w.Cache, key, f)
+	case !p.remove:
+		return p.value, nil
+	}
+	value, err := f()
+	if err != nil {
+		return nil, err
+	}
+	return value, w.Put(key, value)
+}
+
+func (w *writeBehind) Get(key interface{}) (interface{}, error) {
+	p, found := w.lookup(key)
+	if !found {
+		return w.Cache.Get(key)
+	}
+	if p.remove {
+		return nil, ErrKeyNotFound
+	}
+	return p.value, nil
+}
+
+// lookup returns a copy of the pending write of the key, as it may be coalesced with a newer one meanwhile.
+func (w *writeBehind) lookup(key interface{}) (write pendingWrite, found bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if p, found := w.pending[key]; found {
+		return *p, true
+	}
+	return
+}
+
+func (w *writeBehind) Remove(key interface{}) bool {
+	p, found := w.lookup(key)
+	if found {
+		found = !p.remove
+	} else {
+		_, err := w.Cache.Get(key)
+		found = err == nil
+	}
+	w.enqueue(key, pendingWrite{remove: true})
+	return found
+}
+
+func (w *writeBehind) enqueue(key interface{}, write pendingWrite) error {
+	w.mu.Lock()
+	if p, found := w.pending[key]; found && !p.taken {
+		// Coalesce with the queued write.
+		*p = write
+		w.mu.Unlock()
+		return nil
+	}
+	if w.stopped {
+		// Wait for the queued writes, so the writes of a key are applied in order.
+		w.drain()
+		w.mu.Unlock()
+		return w.write(key, write)
+	}
+	w.pending[key] = &write
+	w.queued++
+	w.mu.Unlock()
+
+	queue := w.queues[maphash.Comparable(w.seed, key)%uint64(len(w.queues))]
+	if w.conf.Overflow == OverflowBlock {
+		queue <- key
+		return nil
+	}
+	select {
+	case queue <- key:
+		return nil
+	default:
+	}
+	if w.conf.Overflow == OverflowWriteThrough {
+		return w.apply(key)
+	}
+	w.mu.Lock()
+	if w.pending[key] == &write {
+		delete(w.pending, key)
+	}
+	w.done()
+	w.mu.Unlock()
+	return fmt.Errorf("%w: key %v in %s", ErrQueueFull, key, w)
+}
+
+// stopOn closes the queues once done is closed and the queued writes have been applied, and waits for the workers.
+func (w *writeBehind) stopOn(done <-chan struct{}) {
+	<-done
+	w.mu.Lock()
+	w.stopped = true
+	w.drain()
+	w.mu.Unlock()
+	for _, queue := range w.queues {
+		close(queue)
+	}
+	w.workers.Wait()
+}
+
+func (w *writeBehind) work(queue <-chan interface{}) {
+	defer w.workers.Done()
+	for key := range queue {
+		if err := w.apply(key); err != nil {
+			w.mu.Lock()
+			if w.err == nil {
+				w.err = err
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// apply writes the pending write of the key to the underlying cache.
+func (w *writeBehind) apply(key interface{}) (err error) {
+	w.mu.Lock()
+	p, found := w.pending[key]
+	if !found {
+		// Cancelled by Clear.
+		w.done()
+		w.mu.Unlock()
+		return nil
+	}
+	p.taken = true
+	write := *p
+	w.mu.Unlock()
+
+	err = w.write(key, write)
+
+	w.mu.Lock()
+	if w.pending[key] == p {
+		delete(w.pending, key)
+	}
+	w.done()
+	w.mu.Unlock()
+	return
+}
+
+// write applies a write to the underlying cache.
+func (w *writeBehind) write(key interface{}, write pendingWrite) (err error) {
+	switch {
+	case write.remove:
+		w.Cache.Remove(key)
+	case write.ttl != 0:
+		err = PutWithTTL(w.Cache, key, write.value, write.ttl)
+	case write.withCost:
+		err = PutWithCost(w.Cache, key, write.value, write.cost)
+	default:
+		err = w.Cache.Put(key, write.value)
+	}
+	if err != nil && w.conf.OnError != nil {
+		w.conf.OnError(key, write.value, err)
+	}
+	return
+}
+
+// done accounts for an applied or dropped write. It must be called with the lock held.
+func (w *writeBehind) done() {
+	if w.queued--; w.queued == 0 {
+		w.drained.Broadcast()
+	}
+}
+
+// drain waits for all the queued writes to be applied. It must be called with the lock held.
+func (w *writeBehind) drain() {
+	for w.queued > 0 {
+		w.drained.Wait()
+	}
+}
+
+func (w *writeBehind) Flush() error {
+	w.mu.Lock()
+	w.drain()
+	err := w.err
+	w.err = nil
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return w.Cache.Flush()
+}
+
+func (w *writeBehind) Clear() error {
+	w.mu.Lock()
+	for key, p := range w.pending {
+		if !p.taken {
+			delete(w.pending, key)
+		}
+	}
+	w.drain()
+	w.mu.Unlock()
+	return Clear(w.Cache)
+}
+
+func (w *writeBehind) Range(f func(key, value interface{}) bool) error {
+	return Range(w.Cache, f)
+}
+
+func (w *writeBehind) notifyDrops(f dropFunc) {
+	notifyDrops(w.Cache, f)
+}
+
+func (w *writeBehind) String() string {
+	return fmt.Sprintf("WriteBehind(%s,%d,%d)", w.Cache, w.conf.QueueSize, w.conf.Workers)
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type gatedCache struct {
+	Cache
+	gate chan struct{}
+}
+
+func (g *gatedCache) Put(key, value interface{}) error {
+	<-g.gate
+	return g.Cache.Put(key, value)
+}
+
+func TestWriteBehind(t *testing.T) {
+
+	g := &gatedCache{NewMemoryStorage(), make(chan struct{})}
+	c := options{Spy(t.Logf), WriteBehind(10, 2)}.applyTo(g)
+
+	for i := 0; i < 5; i++ {
+		if err := c.Put(i, i*10); err != nil {
+			t.Fatalf("Put: unexpected error %v", err)
+		}
+	}
+	c.Put(3, 300)
+	c.Remove(4)
+
+	if v, err := c.Get(3); v != 300 || err != nil {
+		t.Errorf("Get: expected 300, <nil>, got %v, %v", v, err)
+	}
+	if v, err := c.Get(4); v != nil || err != ErrKeyNotFound {
+		t.Errorf("Get: expected <nil>, %v, got %v, %v", ErrKeyNotFound, v, err)
+	}
+	if v := c.Len(); v != 0 {
+		t.Errorf("Len: expected 0, got %d", v)
+	}
+
+	compute := func() (interface{}, error) { return 50, nil }
+	if v, err := GetOrCompute(c, 3, compute); v != 300 || err != nil {
+		t.Errorf("GetOrCompute: expected 300, <nil>, got %v, %v", v, err)
+	}
+	if v, err := GetOrCompute(c, 4, compute); v != 50 || err != nil {
+		t.Errorf("GetOrCompute: expected 50, <nil>, got %v, %v", v, err)
+	}
+
+	close(g.gate)
+	if err := c.Flush(); err != nil {
+		t.Errorf("Flush: unexpected error %v", err)
+	}
+
+	if v := g.Len(); v != 5 {
+		t.Errorf("Len: expected 5, got %d", v)
+	}
+	if v, err := g.Get(3); v != 300 || err != nil {
+		t.Errorf("Get: expected 300, <nil>, got %v, %v", v, err)
+	}
+	if v, err := GetOrCompute(c, 5, compute); v != 50 || err != nil {
+		t.Errorf("GetOrCompute: expected 50, <nil>, got %v, %v", v, err)
+	}
+	if v, err := g.Get(5); v != 50 || err != nil {
+		t.Errorf("Get: expected 50, <nil>, got %v, %v", v, err)
+	}
+}
+
+func TestWriteBehindOverflow(t *testing.T) {
+
+	g := &gatedCache{NewMemoryStorage(), make(chan struct{})}
+	c := WriteBehindWith(WriteBehindConfig{QueueSize: 1, Overflow: OverflowDrop})(g)
+
+	c.Put(1, 10)
+	var err error
+	for i := 2; i < 5 && err == nil; i++ {
+		err = c.Put(i, i*10)
+	}
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Put: expected %v, got %v", ErrQueueFull, err)
+	}
+
+	close(g.gate)
+	c.Flush()
+}
+
+func TestWriteBehindErrors(t *testing.T) {
+
+	var failed []interface{}
+	u := WriteBehindWith(WriteBehindConfig{
+		OnError: func(key, value interface{}, err error) {
+			t.Logf("%v, %v: %v", key, value, err)
+			failed = append(failed, key)
+		},
+	})(Untyped(NewTypedMemoryStorage[int, int]()))
+
+	u.Put(1, "one")
+	u.Put(2, 2)
+
+	if err := u.Flush(); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("Flush: expected %v, got %v", ErrUnexpectedType, err)
+	}
+	if len(failed) != 1 || failed[0] != 1 {
+		t.Errorf("OnError: expected [1], got %v", failed)
+	}
+	if err := u.Flush(); err != nil {
+		t.Errorf("Flush: unexpected error %v", err)
+	}
+}
+
+func TestWriteBehindStop(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &gatedCache{NewMemoryStorage(), make(chan struct{})}
+	w := WriteBehindWith(WriteBehindConfig{Workers: 2, Context: ctx})(g).(*writeBehind)
+
+	for i := 0; i < 3; i++ {
+		w.Put(i, i*10)
+	}
+	cancel()
+	close(g.gate)
+
+	// The workers exit once the queued writes have been applied.
+	w.workers.Wait()
+	if v := g.Len(); v != 3 {
+		t.Errorf("Len: expected 3, got %d", v)
+	}
+
+	if err := w.Put(3, 30); err != nil {
+		t.Errorf("Put: unexpected error %v", err)
+	}
+	if v, err := g.Get(3); v != 30 || err != nil {
+		t.Errorf("Get: expected 30, <nil> without Flush, got %v, %v", v, err)
+	}
+	if !w.Remove(0) || g.Len() != 3 {
+		t.Errorf("Remove: expected the entry to be removed synchronously, got %d entries", g.Len())
+	}
+}
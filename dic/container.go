@@ -27,6 +27,7 @@ type BaseContainer struct {
 	providers map[interface{}]Provider
 	path      []Provider
 	logger    *log.Logger
+	lifecycle
 }
 
 // New initializes new, empty Container, that logs to nothing.
@@ -113,7 +114,7 @@ func (c *BaseContainer) Fetch(target interface{}) (err error) {
 	defer done()
 
 	defer func() {
-		if rec := logging.RecoverError(); rec != nil {
+		if rec := logging.PanicError(recover()); rec != nil {
 			err = &BuildPanicError{provider, rec}
 		}
 	}()
@@ -122,6 +123,7 @@ func (c *BaseContainer) Fetch(target interface{}) (err error) {
 	if err == nil {
 		if ret.IsValid() {
 			value.Set(ret)
+			c.track(ret)
 		} else {
 			err = &BuildError{provider}
 		}
@@ -0,0 +1,98 @@
+package dic
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.uber.org/multierr"
+)
+
+// Starter is implemented by values that must be started once built, e.g. servers.
+// Start must not block; it returns once the value is ready or failed to start.
+type Starter interface {
+	Start(context.Context) error
+}
+
+// Stopper is implemented by values that must be stopped before the application exits.
+// Stop should return when the value is stopped or the context is done.
+type Stopper interface {
+	Stop(context.Context) error
+}
+
+// lifecycle tracks the values built by the container that implement Starter or Stopper, in build order.
+// As the dependencies of a value are built before it, they are started before it and stopped after it.
+// The values are tracked by instance, so each value built by a non-singleton provider is tracked.
+type lifecycle struct {
+	values  []interface{}
+	tracked map[interface{}]bool
+	started int
+}
+
+func (l *lifecycle) track(v reflect.Value) {
+	if !v.CanInterface() {
+		return
+	}
+	value := v.Interface()
+	_, isStarter := value.(Starter)
+	_, isStopper := value.(Stopper)
+	if !isStarter && !isStopper {
+		return
+	}
+	// Values that cannot be map keys are distinct copies, tracked each time.
+	if reflect.TypeOf(value).Comparable() {
+		if l.tracked[value] {
+			return
+		}
+		if l.tracked == nil {
+			l.tracked = make(map[interface{}]bool)
+		}
+		l.tracked[value] = true
+	}
+	l.values = append(l.values, value)
+}
+
+// Start starts the Starters built by the container and not started yet, dependencies first.
+// If one of them fails, the values built so far are stopped and the error is returned.
+func (c *BaseContainer) Start(ctx context.Context) error {
+	for ; c.started < len(c.values); c.started++ {
+		value := c.values[c.started]
+		s, ok := value.(Starter)
+		if !ok {
+			continue
+		}
+		c.logger.Printf("Starting %T", value)
+		if err := s.Start(ctx); err != nil {
+			err = fmt.Errorf("cannot start %T: %w", value, err)
+			return multierr.Append(err, c.Stop(ctx))
+		}
+	}
+	return nil
+}
+
+// Stop stops the Stoppers built by the container, in the reverse order of their build, including the ones built
+// after Start. The Starters that have not been started are skipped. All of them are stopped, even if some fail;
+// the errors are combined. The Starters can be started again, while the other Stoppers are forgotten.
+func (c *BaseContainer) Stop(ctx context.Context) (err error) {
+	for i := len(c.values) - 1; i >= 0; i-- {
+		value := c.values[i]
+		if _, isStarter := value.(Starter); isStarter && i >= c.started {
+			continue
+		}
+		if s, ok := value.(Stopper); ok {
+			c.logger.Printf("Stopping %T", value)
+			if stopErr := s.Stop(ctx); stopErr != nil {
+				err = multierr.Append(err, fmt.Errorf("cannot stop %T: %w", value, stopErr))
+			}
+		}
+	}
+	starters := c.values[:0]
+	for _, value := range c.values {
+		if _, isStarter := value.(Starter); isStarter {
+			starters = append(starters, value)
+		}
+	}
+	c.values = starters
+	c.started = 0
+	return
+}
@@ -0,0 +1,113 @@
+package dic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type server struct {
+	name string
+	fail bool
+}
+
+func (s *server) Start(context.Context) error {
+	if s.fail {
+		return errors.New("address in use")
+	}
+	fmt.Println("start", s.name)
+	return nil
+}
+
+func (s *server) Stop(context.Context) error {
+	fmt.Println("stop", s.name)
+	return nil
+}
+
+type database struct{ server }
+
+func ExampleBaseContainer_Start() {
+	// Container setup
+	ctn := New()
+	ctn.Register(Func(func() *database { return &database{server{name: "database"}} }))
+	ctn.Register(Func(func(*database) *server { return &server{name: "server"} }))
+
+	// Container use
+	var s *server
+	if err := ctn.Fetch(&s); err != nil {
+		panic(err)
+	}
+	if err := ctn.Start(context.Background()); err != nil {
+		panic(err)
+	}
+	if err := ctn.Stop(context.Background()); err != nil {
+		panic(err)
+	}
+	// Output:
+	// start database
+	// start server
+	// stop server
+	// stop database
+}
+
+func ExampleBaseContainer_Start_failure() {
+	// Container setup
+	ctn := New()
+	ctn.Register(Func(func() *database { return &database{server{name: "database"}} }))
+	ctn.Register(Func(func(*database) *server { return &server{name: "server", fail: true} }))
+
+	// Container use
+	var s *server
+	if err := ctn.Fetch(&s); err != nil {
+		panic(err)
+	}
+	fmt.Println(ctn.Start(context.Background()))
+	// Output:
+	// start database
+	// stop database
+	// cannot start *dic.server: address in use
+}
+
+type closer struct{ name string }
+
+func (c *closer) Stop(context.Context) error {
+	fmt.Println("close", c.name)
+	return nil
+}
+
+func ExampleBaseContainer_Stop() {
+	// Container setup
+	ctn := New()
+	n := 0
+	// Not a singleton: each Fetch builds a new server.
+	ctn.Register(Func(func() *server {
+		n++
+		return &server{name: fmt.Sprintf("server%d", n)}
+	}).(*Singleton).Provider)
+	ctn.Register(Func(func() *closer { return &closer{name: "logs"} }))
+
+	// Container use
+	var s1, s2 *server
+	if err := ctn.Fetch(&s1); err != nil {
+		panic(err)
+	}
+	if err := ctn.Fetch(&s2); err != nil {
+		panic(err)
+	}
+	if err := ctn.Start(context.Background()); err != nil {
+		panic(err)
+	}
+	var c *closer
+	if err := ctn.Fetch(&c); err != nil {
+		panic(err)
+	}
+	if err := ctn.Stop(context.Background()); err != nil {
+		panic(err)
+	}
+	// Output:
+	// start server1
+	// start server2
+	// close logs
+	// stop server2
+	// stop server1
+}
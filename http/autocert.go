@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/Adirelle/go-libs/cache"
+)
+
+// NewAutoCertManager creates an autocert.Manager that accepts the Terms of Service,
+// stores the certificates in the given cache and only requests certificates for the given domains.
+func NewAutoCertManager(c autocert.Cache, domains ...string) *autocert.Manager {
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      c,
+		HostPolicy: autocert.HostWhitelist(domains...),
+	}
+}
+
+// CertCache adapts a cache.Cache to be used as an autocert.Cache.
+// The cache must be persistent for the certificates to survive restarts.
+func CertCache(c cache.Cache) autocert.Cache {
+	return certCache{c}
+}
+
+type certCache struct {
+	c cache.Cache
+}
+
+func (c certCache) Get(_ context.Context, key string) ([]byte, error) {
+	value, err := c.c.Get(key)
+	if err == cache.ErrKeyNotFound {
+		return nil, autocert.ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+	data, ok := value.([]byte)
+	if !ok {
+		return nil, autocert.ErrCacheMiss
+	}
+	return data, nil
+}
+
+func (c certCache) Put(_ context.Context, key string, data []byte) error {
+	return c.c.Put(key, data)
+}
+
+func (c certCache) Delete(_ context.Context, key string) error {
+	c.c.Remove(key)
+	return nil
+}
+
+func (w *Service) startChallenge() {
+	w.TLSConfig = w.AutoCert.TLSConfig()
+
+	addr := w.ChallengeAddr
+	if addr == "" {
+		addr = ":http"
+	}
+	challenge := &http.Server{Addr: addr, Handler: w.AutoCert.HTTPHandler(nil)}
+	w.mu.Lock()
+	w.challenge = challenge
+	w.mu.Unlock()
+
+	go func() {
+		w.Infof("serving ACME challenges on %s", addr)
+		err := challenge.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			w.Error(err)
+		}
+	}()
+}
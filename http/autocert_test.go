@@ -0,0 +1,44 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/Adirelle/go-libs/cache"
+)
+
+func TestCertCache(t *testing.T) {
+
+	ctx := context.Background()
+	c := CertCache(cache.NewMemoryStorage())
+
+	if _, err := c.Get(ctx, "example.com"); err != autocert.ErrCacheMiss {
+		t.Errorf("missing key: expected ErrCacheMiss, got %v", err)
+	}
+	if err := c.Put(ctx, "example.com", []byte("cert")); err != nil {
+		t.Fatalf("Put: unexpected error %v", err)
+	}
+	if data, err := c.Get(ctx, "example.com"); err != nil || string(data) != "cert" {
+		t.Errorf("stored key: expected cert, got %q, %v", data, err)
+	}
+	if err := c.Delete(ctx, "example.com"); err != nil {
+		t.Fatalf("Delete: unexpected error %v", err)
+	}
+	if _, err := c.Get(ctx, "example.com"); err != autocert.ErrCacheMiss {
+		t.Errorf("deleted key: expected ErrCacheMiss, got %v", err)
+	}
+}
+
+func TestNewAutoCertManager(t *testing.T) {
+
+	m := NewAutoCertManager(CertCache(cache.NewMemoryStorage()), "example.com")
+
+	if err := m.HostPolicy(context.Background(), "example.com"); err != nil {
+		t.Errorf("allowed domain: unexpected error %v", err)
+	}
+	if err := m.HostPolicy(context.Background(), "evil.com"); err == nil {
+		t.Errorf("other domain: expected an error")
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+const (
+	userKey = contextKey(3)
+)
+
+// CredentialValidator checks the password of an user.
+type CredentialValidator func(user, password string) bool
+
+// StaticCredentials returns a CredentialValidator that checks credentials against a map of user passwords.
+// The comparisons are done in constant time.
+func StaticCredentials(passwords map[string]string) CredentialValidator {
+	hashes := make(map[string][sha256.Size]byte, len(passwords))
+	for user, password := range passwords {
+		hashes[user] = sha256.Sum256([]byte(password))
+	}
+	return func(user, password string) bool {
+		expected, found := hashes[user]
+		actual := sha256.Sum256([]byte(password))
+		return subtle.ConstantTimeCompare(expected[:], actual[:]) == 1 && found
+	}
+}
+
+// BasicAuth returns a middleware that requires HTTP Basic authentication.
+// The user name is added to the Request Context and to any associated Logger.
+func BasicAuth(realm string, validate CredentialValidator) func(http.Handler) http.Handler {
+	challenge := fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", realm)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+			if !ok || !validate(user, password) {
+				if ok {
+					if logger := logging.FromContext(r.Context(), nil); logger != nil {
+						logger.Infow("authentication failed", "user", user)
+					}
+				}
+				w.Header().Set("WWW-Authenticate", challenge)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			ctx := r.Context()
+			if logger := logging.FromContext(ctx, nil); logger != nil {
+				ctx = logging.WithLogger(ctx, logger.With("user", user))
+			}
+			ctx = context.WithValue(ctx, userKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// UserFromContext retrieves the authenticated user name from the Context, or an empty string.
+func UserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey).(string)
+	return user
+}
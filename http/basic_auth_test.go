@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+
+	var user string
+	handler := BasicAuth("test", StaticCredentials(map[string]string{"alice": "secret"}))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user = UserFromContext(r.Context())
+		}))
+
+	get := func(user, password string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if user != "" {
+			r.SetBasicAuth(user, password)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get("", ""); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Basic realm="test", charset="UTF-8"` {
+		t.Errorf("anonymous: expected 401 with a challenge, got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	if w := get("alice", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password: expected 401, got %d", w.Code)
+	}
+	if w := get("bob", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown user: expected 401, got %d", w.Code)
+	}
+	if w := get("alice", "secret"); w.Code != http.StatusOK || user != "alice" {
+		t.Errorf("valid credentials: expected 200 for alice, got %d for %q", w.Code, user)
+	}
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Middleware wraps a http.Handler.
+type Middleware = func(http.Handler) http.Handler
+
+// Chain is an immutable list of middlewares, the first one being the outermost.
+//
+// Chains can be composed, so common groups can be defined once and reused:
+//
+//	base := NewChain(UniqueID, DebugRequest)
+//	api := base.Use(JWTAuth(conf))
+//	router.Handle("/api/items", api.Then(itemsHandler))
+type Chain struct {
+	middlewares []Middleware
+}
+
+// NewChain creates a Chain of the given middlewares.
+func NewChain(middlewares ...Middleware) Chain {
+	return Chain{}.Use(middlewares...)
+}
+
+// Use returns a new Chain with the given middlewares appended. The receiver is left unchanged.
+func (c Chain) Use(middlewares ...Middleware) Chain {
+	mws := make([]Middleware, 0, len(c.middlewares)+len(middlewares))
+	mws = append(mws, c.middlewares...)
+	for _, mw := range middlewares {
+		if mw != nil {
+			mws = append(mws, mw)
+		}
+	}
+	return Chain{mws}
+}
+
+// Extend returns a new Chain with the middlewares of the other chains appended.
+func (c Chain) Extend(others ...Chain) Chain {
+	for _, other := range others {
+		c = c.Use(other.middlewares...)
+	}
+	return c
+}
+
+// Len returns the number of middlewares in the Chain.
+func (c Chain) Len() int {
+	return len(c.middlewares)
+}
+
+// Then wraps the handler with the middlewares. A nil handler is replaced by http.DefaultServeMux.
+func (c Chain) Then(h http.Handler) http.Handler {
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
+
+// ThenFunc wraps the handler function with the middlewares.
+func (c Chain) ThenFunc(f http.HandlerFunc) http.Handler {
+	if f == nil {
+		return c.Then(nil)
+	}
+	return c.Then(f)
+}
+
+// Middleware returns the Chain as a single middleware.
+func (c Chain) Middleware() Middleware {
+	return c.Then
+}
+
+// ApplyTo registers the middlewares on the router, e.g. a subrouter of a group of routes.
+func (c Chain) ApplyTo(router *mux.Router) {
+	for _, mw := range c.middlewares {
+		router.Use(mw)
+	}
+}
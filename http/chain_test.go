@@ -0,0 +1,33 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+
+	var calls []string
+	named := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	base := NewChain(named("a"), nil, named("b"))
+	extended := base.Use(named("c")).Extend(NewChain(named("d")))
+	if base.Len() != 2 || extended.Len() != 4 {
+		t.Errorf("Len: expected 2 and 4, got %d and %d", base.Len(), extended.Len())
+	}
+
+	extended.ThenFunc(func(http.ResponseWriter, *http.Request) { calls = append(calls, "handler") }).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if order := strings.Join(calls, ","); order != "a,b,c,d,handler" {
+		t.Errorf("expected a,b,c,d,handler, got %s", order)
+	}
+}
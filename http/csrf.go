@@ -0,0 +1,164 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+const (
+	csrfKey = contextKey(5)
+)
+
+const csrfSecretLength = 32
+
+// CSRFConfig configures the CSRF middleware.
+type CSRFConfig struct {
+	// CookieName is the name of the cookie holding the secret. It defaults to "csrf_secret".
+	CookieName string
+
+	// HeaderName is the request header that can hold the token. It defaults to "X-CSRF-Token".
+	HeaderName string
+
+	// FieldName is the form field that can hold the token. It defaults to "csrf_token".
+	FieldName string
+
+	// Secure restricts the cookie to HTTPS.
+	Secure bool
+
+	// ExemptPrefixes lists path prefixes of requests that are not checked, e.g. token-authenticated APIs.
+	ExemptPrefixes []string
+
+	// Exempt, if not nil, tells whether a request should not be checked.
+	Exempt func(*http.Request) bool
+}
+
+func (c *CSRFConfig) setDefaults() {
+	if c.CookieName == "" {
+		c.CookieName = "csrf_secret"
+	}
+	if c.HeaderName == "" {
+		c.HeaderName = "X-CSRF-Token"
+	}
+	if c.FieldName == "" {
+		c.FieldName = "csrf_token"
+	}
+}
+
+func (c *CSRFConfig) exempts(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	for _, prefix := range c.ExemptPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return c.Exempt != nil && c.Exempt(r)
+}
+
+type csrfState struct {
+	secret    []byte
+	fieldName string
+}
+
+// CSRF returns a middleware protecting against cross-site request forgery, using the double-submit cookie pattern.
+//
+// A secret is stored in a cookie, and unsafe requests must provide a token derived from it, either in a header or
+// a form field. Tokens are masked with a random value, so they differ on each request. They are available to
+// handlers and templates through CSRFToken and CSRFField.
+func CSRF(conf CSRFConfig) func(http.Handler) http.Handler {
+	conf.setDefaults()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var secret []byte
+			if cookie, err := r.Cookie(conf.CookieName); err == nil {
+				secret, _ = base64.RawURLEncoding.DecodeString(cookie.Value)
+			}
+			if len(secret) != csrfSecretLength {
+				secret = make([]byte, csrfSecretLength)
+				if _, err := rand.Read(secret); err != nil {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+				http.SetCookie(w, &http.Cookie{
+					Name:     conf.CookieName,
+					Value:    base64.RawURLEncoding.EncodeToString(secret),
+					Path:     "/",
+					Secure:   conf.Secure,
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+			w.Header().Add("Vary", "Cookie")
+
+			if !conf.exempts(r) {
+				token := r.Header.Get(conf.HeaderName)
+				if token == "" {
+					token = r.PostFormValue(conf.FieldName)
+				}
+				if !validCSRFToken(token, secret) {
+					if logger := logging.FromContext(r.Context(), nil); logger != nil {
+						logger.Infow("invalid CSRF token", logging.RequestFields(r)...)
+					}
+					http.Error(w, "invalid CSRF token", http.StatusForbidden)
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), csrfKey, &csrfState{secret, conf.FieldName})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// CSRFToken returns a new token for the request, to be sent back in a header or a form field.
+// It returns an empty string if the CSRF middleware is not in use.
+func CSRFToken(r *http.Request) string {
+	state, found := r.Context().Value(csrfKey).(*csrfState)
+	if !found {
+		return ""
+	}
+	token := make([]byte, 2*csrfSecretLength)
+	mask := token[:csrfSecretLength]
+	if _, err := rand.Read(mask); err != nil {
+		return ""
+	}
+	for i, b := range state.secret {
+		token[csrfSecretLength+i] = b ^ mask[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// CSRFField returns a hidden form field holding a new token, for use in HTML templates.
+func CSRFField(r *http.Request) template.HTML {
+	state, found := r.Context().Value(csrfKey).(*csrfState)
+	if !found {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(
+		`<input type="hidden" name="%s" value="%s">`,
+		template.HTMLEscapeString(state.fieldName),
+		CSRFToken(r),
+	))
+}
+
+func validCSRFToken(token string, secret []byte) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != 2*csrfSecretLength {
+		return false
+	}
+	unmasked := make([]byte, csrfSecretLength)
+	for i := range unmasked {
+		unmasked[i] = raw[i] ^ raw[csrfSecretLength+i]
+	}
+	return subtle.ConstantTimeCompare(unmasked, secret) == 1
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+
+	var token string
+	handler := CSRF(CSRFConfig{ExemptPrefixes: []string{"/api/"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = CSRFToken(r)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusOK || len(cookies) != 1 || token == "" {
+		t.Fatalf("GET: expected 200 with a secret cookie and a token, got %d %v %q", w.Code, cookies, token)
+	}
+	getToken := token
+
+	post := func(path, header string, form url.Values) int {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(cookies[0])
+		if header != "" {
+			r.Header.Set("X-CSRF-Token", header)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := post("/form", "", nil); code != http.StatusForbidden {
+		t.Errorf("without token: expected 403, got %d", code)
+	}
+	if code := post("/form", "", url.Values{"csrf_token": {getToken}}); code != http.StatusOK {
+		t.Errorf("form token: expected 200, got %d", code)
+	}
+	if token == getToken {
+		t.Errorf("CSRFToken: expected tokens to differ on each request")
+	}
+	if code := post("/form", token, nil); code != http.StatusOK {
+		t.Errorf("header token: expected 200, got %d", code)
+	}
+	if code := post("/form", strings.Repeat("A", len(token)), nil); code != http.StatusForbidden {
+		t.Errorf("forged token: expected 403, got %d", code)
+	}
+	if code := post("/api/users", "", nil); code != http.StatusOK {
+		t.Errorf("exempted: expected 200, got %d", code)
+	}
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Adirelle/go-libs/http/httperr"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// DeadlineConfig configures the Deadline middleware.
+type DeadlineConfig struct {
+	// Default is the time budget of requests without inbound timeout. Zero means no deadline.
+	Default time.Duration
+
+	// Max caps the timeouts, if not zero.
+	Max time.Duration
+
+	// Header is the request header holding the timeout requested by the caller, either as a Go duration
+	// (e.g. "1.5s") or as a number of milliseconds. Inbound timeouts are ignored when it is empty.
+	Header string
+
+	// GRPCTimeout enables the grpc-timeout header (e.g. "100m" for 100 milliseconds).
+	GRPCTimeout bool
+}
+
+// DefaultDeadlineConfig returns a configuration honoring the X-Request-Timeout and grpc-timeout headers,
+// up to 1 minute, and without default deadline.
+func DefaultDeadlineConfig() DeadlineConfig {
+	return DeadlineConfig{
+		Max:         time.Minute,
+		Header:      "X-Request-Timeout",
+		GRPCTimeout: true,
+	}
+}
+
+// Deadline returns a middleware that sets a deadline to the Request Context, from the timeout requested by
+// the caller or the configured default. The handlers, and the cache or client calls they make with the
+// Request Context, can use Remaining to respect the time budget.
+//
+// If the deadline is exceeded before anything is written, the middleware sends httperr.ErrTimeout.
+func Deadline(conf DeadlineConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := conf.timeout(r)
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			sw := &statusWriter{writerBase: writerBase{w}}
+			next.ServeHTTP(wrapWriter(sw), r)
+
+			if sw.status == 0 && ctx.Err() == context.DeadlineExceeded {
+				err := httperr.ErrTimeout.Wrap(ctx.Err())
+				if logger := logging.FromContext(ctx, nil); logger != nil {
+					logger.Debugw("request deadline exceeded", "timeout", timeout.String())
+				}
+				WriteError(w, r, err)
+			}
+		})
+	}
+}
+
+func (c DeadlineConfig) timeout(r *http.Request) (timeout time.Duration) {
+	timeout = c.Default
+	if c.Header != "" {
+		if value := r.Header.Get(c.Header); value != "" {
+			if d, ok := parseTimeout(value); ok {
+				timeout = d
+			}
+		}
+	}
+	if c.GRPCTimeout {
+		if value := r.Header.Get("grpc-timeout"); value != "" {
+			if d, ok := parseGRPCTimeout(value); ok && (timeout <= 0 || d < timeout) {
+				timeout = d
+			}
+		}
+	}
+	if c.Max > 0 && timeout > c.Max {
+		timeout = c.Max
+	}
+	return
+}
+
+// parseTimeout parses a Go duration or a number of milliseconds.
+func parseTimeout(value string) (time.Duration, bool) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, ms > 0
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil && d > 0
+}
+
+var grpcTimeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseGRPCTimeout parses a grpc-timeout value: up to 8 digits followed by an unit.
+func parseGRPCTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, false
+	}
+	unit, found := grpcTimeoutUnits[value[len(value)-1]]
+	if !found || strings.TrimLeft(value[:len(value)-1], "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	return time.Duration(n) * unit, err == nil && n > 0
+}
+
+// Remaining returns the time left before the deadline of the context, and whether it has one.
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// RemainingOr returns the time left before the deadline of the context, or def if it has none
+// or if def is shorter.
+func RemainingOr(ctx context.Context, def time.Duration) time.Duration {
+	if remaining, ok := Remaining(ctx); ok && remaining < def {
+		return remaining
+	}
+	return def
+}
+
+// Expired returns whether the deadline of the context has passed, or is closer than the margin.
+func Expired(ctx context.Context, margin time.Duration) bool {
+	remaining, ok := Remaining(ctx)
+	return ok && remaining <= margin
+}
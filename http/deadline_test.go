@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeadline(t *testing.T) {
+
+	var remaining time.Duration
+	var hasDeadline bool
+	handler := Deadline(DefaultDeadlineConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining, hasDeadline = Remaining(r.Context())
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+		}
+	}))
+
+	serve := func(path, header, value string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if serve("/", "", ""); hasDeadline {
+		t.Errorf("no timeout: expected no deadline, got %s", remaining)
+	}
+	if serve("/", "X-Request-Timeout", "1500"); !hasDeadline || remaining > 1500*time.Millisecond || remaining < time.Second {
+		t.Errorf("X-Request-Timeout: expected about 1.5s, got %s", remaining)
+	}
+	if serve("/", "grpc-timeout", "2S"); !hasDeadline || remaining > 2*time.Second || remaining < time.Second {
+		t.Errorf("grpc-timeout: expected about 2s, got %s", remaining)
+	}
+	if serve("/", "X-Request-Timeout", "1h"); remaining > time.Minute {
+		t.Errorf("Max: expected at most 1m, got %s", remaining)
+	}
+	if serve("/", "X-Request-Timeout", "soon"); hasDeadline {
+		t.Errorf("invalid timeout: expected no deadline, got %s", remaining)
+	}
+
+	if w := serve("/slow", "X-Request-Timeout", "10ms"); w.Code != http.StatusGatewayTimeout {
+		t.Errorf("exceeded: expected 504, got %d", w.Code)
+	}
+}
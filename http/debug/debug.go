@@ -0,0 +1,74 @@
+// Package debug mounts debug endpoints on a router.
+//
+// Importing it registers the net/http/pprof and expvar handlers on http.DefaultServeMux, as a side effect of
+// importing these packages, so it is kept apart from the http package.
+package debug
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/gorilla/mux"
+
+	libhttp "github.com/Adirelle/go-libs/http"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// Options configures Mount.
+type Options struct {
+	// Prefix is the path prefix of the debug endpoints. It defaults to "/debug".
+	Prefix string
+
+	// Auth, if not nil, is a middleware protecting the debug endpoints, e.g. BasicAuth.
+	Auth func(http.Handler) http.Handler
+
+	// Factory, if not nil, is used to list the loggers and their levels, and the recent log entries.
+	Factory *logging.Factory
+}
+
+// Mount mounts debug endpoints on the router, under the configured prefix:
+//
+//	/pprof/    net/http/pprof profiles
+//	/vars      expvar variables
+//	/routes    the router routes, see RouterDebug
+//	/loggers   the loggers and their levels, if a Factory is configured
+//	/logs      the recent log entries, oldest first, if a Factory is configured (see logging.Config.Recent)
+func Mount(router *mux.Router, opts Options) {
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = "/debug"
+	}
+	sub := router.PathPrefix(prefix).Subrouter()
+	if opts.Auth != nil {
+		sub.Use(mux.MiddlewareFunc(opts.Auth))
+	}
+
+	sub.HandleFunc("/pprof/", pprof.Index)
+	sub.HandleFunc("/pprof/cmdline", pprof.Cmdline)
+	sub.HandleFunc("/pprof/profile", pprof.Profile)
+	sub.HandleFunc("/pprof/symbol", pprof.Symbol)
+	sub.HandleFunc("/pprof/trace", pprof.Trace)
+	sub.HandleFunc("/pprof/{profile}", func(w http.ResponseWriter, r *http.Request) {
+		pprof.Handler(mux.Vars(r)["profile"]).ServeHTTP(w, r)
+	})
+
+	sub.Handle("/vars", expvar.Handler())
+	sub.Handle("/routes", &libhttp.RouterDebug{Router: router})
+
+	if opts.Factory != nil {
+		sub.HandleFunc("/loggers", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			json.NewEncoder(w).Encode(opts.Factory.Loggers())
+		})
+		sub.HandleFunc("/logs", func(w http.ResponseWriter, _ *http.Request) {
+			entries := opts.Factory.Recent()
+			if entries == nil {
+				entries = []json.RawMessage{}
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			json.NewEncoder(w).Encode(entries)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+func TestMount(t *testing.T) {
+
+	c := logging.DefaultConfig()
+	c.Outputs = []logging.OutputConfig{{Path: filepath.Join(t.TempDir(), "test.log")}}
+	c.Recent = 10
+	f := c.Build()
+	defer f.Close()
+	f.Get("test").Info("hello")
+
+	router := mux.NewRouter()
+	Mount(router, Options{
+		Factory: f,
+		Auth: func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Admin") == "" {
+					http.Error(w, "forbidden", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		},
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("X-Admin", "yes")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("/debug/logs")
+	var entries []map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil || len(entries) != 1 || entries[0]["msg"] != "hello" {
+		t.Errorf("/logs: expected the hello entry, got %d %v, %v", w.Code, entries, err)
+	}
+	for _, path := range []string{"/debug/loggers", "/debug/vars", "/debug/routes", "/debug/pprof/"} {
+		if w := get(path); w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", path, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("unauthenticated: expected 403, got %d", w.Code)
+	}
+}
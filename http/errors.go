@@ -0,0 +1,58 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Adirelle/go-libs/http/httperr"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// ProblemContentType is the media type of problem details responses.
+const ProblemContentType = "application/problem+json"
+
+// ErrorHandlerFunc is a handler that returns an error instead of writing an error response itself.
+// Errors are rendered by WriteError. The handler must not return an error once it has written the response.
+type ErrorHandlerFunc func(http.ResponseWriter, *http.Request) error
+
+// ServeHTTP implements http.Handler.
+func (f ErrorHandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteError(w, r, err)
+	}
+}
+
+// HandleErrors returns a http.Handler that renders the errors returned by the handler.
+func HandleErrors(f func(http.ResponseWriter, *http.Request) error) http.Handler {
+	return ErrorHandlerFunc(f)
+}
+
+// WriteError logs the error and sends it as a JSON problem details response.
+//
+// Errors that are not *httperr.Error are sent as httperr.ErrInternal, without disclosing their message.
+// Server errors (5xx) are logged at error level, client errors at info level. The unique ID of the request,
+// if any, is included in the response.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	e := httperr.From(err)
+	problem := e.Problem()
+	problem.Instance = r.URL.RequestURI()
+	problem.RequestID, _ = r.Context().Value(uniqueIDKey).(string)
+
+	if logger := logging.FromContext(r.Context(), nil); logger != nil {
+		fields := append(logging.RequestFields(r), "status", e.Status, "code", e.Code)
+		if e.Status >= http.StatusInternalServerError {
+			logger.ErrorE(err, e.Message, fields...)
+		} else {
+			logger.Infow(e.Message, append(fields, logging.ErrorKey, err)...)
+		}
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", ProblemContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Del("Content-Length")
+	w.WriteHeader(e.Status)
+	if r.Method != http.MethodHead {
+		json.NewEncoder(w).Encode(problem)
+	}
+}
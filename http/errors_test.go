@@ -0,0 +1,53 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Adirelle/go-libs/http/httperr"
+)
+
+func TestHandleErrors(t *testing.T) {
+
+	var err error
+	handler := HandleErrors(func(w http.ResponseWriter, r *http.Request) error { return err })
+
+	serve := func(method string) (*httptest.ResponseRecorder, httperr.Problem) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/users/1", nil))
+		var p httperr.Problem
+		if w.Body.Len() > 0 {
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatalf("invalid problem: %v", err)
+			}
+		}
+		return w, p
+	}
+
+	err = httperr.ErrNotFound.Wrap(errors.New("no such row"))
+	w, p := serve(http.MethodGet)
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Errorf("expected 404 %s, got %d %s", ProblemContentType, w.Code, w.Header().Get("Content-Type"))
+	}
+	if p.Code != "not_found" || p.Instance != "/users/1" || p.Detail != httperr.ErrNotFound.Message {
+		t.Errorf("unexpected problem %+v", p)
+	}
+
+	// The message of other errors is not disclosed.
+	err = errors.New("secret")
+	if w, p = serve(http.MethodGet); w.Code != http.StatusInternalServerError || p.Detail != httperr.ErrInternal.Message {
+		t.Errorf("expected 500 %q, got %d %+v", httperr.ErrInternal.Message, w.Code, p)
+	}
+
+	if w, _ = serve(http.MethodHead); w.Code != http.StatusInternalServerError || w.Body.Len() != 0 {
+		t.Errorf("HEAD: expected 500 without body, got %d %q", w.Code, w.Body.String())
+	}
+
+	err = nil
+	if w, _ = serve(http.MethodGet); w.Code != http.StatusOK {
+		t.Errorf("no error: expected 200, got %d", w.Code)
+	}
+}
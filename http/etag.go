@@ -0,0 +1,180 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETagConfig configures the ETag middleware.
+type ETagConfig struct {
+	// Weak generates weak ETags, which only assert semantic equivalence.
+	Weak bool
+
+	// MaxBodySize is the size above which responses are passed through without ETag.
+	// It defaults to DefaultMaxCachedBodySize.
+	MaxBodySize int
+}
+
+// ETag returns a middleware that buffers successful responses to GET and HEAD requests,
+// computes the ETag of the GET ones, unless the handler has set one, and answers conditional requests
+// (If-None-Match, If-Modified-Since) with 304 Not Modified.
+func ETag(conf ETagConfig) func(http.Handler) http.Handler {
+	if conf.MaxBodySize <= 0 {
+		conf.MaxBodySize = DefaultMaxCachedBodySize
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			bw := &bufferedWriter{writerBase: writerBase{w}, maxSize: conf.MaxBodySize}
+			next.ServeHTTP(wrapWriter(bw), r)
+			if bw.passthrough {
+				return
+			}
+			status := bw.Status()
+			// The body of a response to HEAD is usually empty, so its ETag would not match the GET one.
+			if status == http.StatusOK && r.Method == http.MethodGet && w.Header().Get("ETag") == "" {
+				w.Header().Set("ETag", ComputeETag(bw.buf.Bytes(), conf.Weak))
+			}
+			if status == http.StatusOK && CheckNotModified(w, r) {
+				return
+			}
+			w.WriteHeader(status)
+			w.Write(bw.buf.Bytes())
+		})
+	}
+}
+
+// ComputeETag returns a quoted ETag for the given content.
+func ComputeETag(content []byte, weak bool) string {
+	sum := sha256.Sum256(content)
+	tag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	if weak {
+		tag = "W/" + tag
+	}
+	return tag
+}
+
+// SetValidators sets the ETag and Last-Modified headers of the response, ignoring empty values.
+// Handlers can use it, followed by CheckNotModified, to avoid building the response body.
+func SetValidators(w http.ResponseWriter, etag string, modified time.Time) {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// CheckNotModified compares the validators of the response (ETag and Last-Modified headers)
+// with the conditional headers of the request. If the client already has the current representation,
+// it sends a 304 Not Modified response and returns true.
+func CheckNotModified(w http.ResponseWriter, r *http.Request) bool {
+	h := w.Header()
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etag := h.Get("ETag"); etag == "" || !etagMatches(inm, etag) {
+			return false
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		modified, err := http.ParseTime(h.Get("Last-Modified"))
+		if err != nil || modified.After(since) {
+			return false
+		}
+	} else {
+		return false
+	}
+	for _, name := range []string{"Content-Type", "Content-Length", "Content-Encoding"} {
+		h.Del(name)
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches implements the weak comparison of If-None-Match.
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter buffers the response, up to maxSize bytes. Beyond that, it switches to pass-through.
+type bufferedWriter struct {
+	writerBase
+	status      int
+	buf         bytes.Buffer
+	maxSize     int
+	passthrough bool
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	if b.passthrough {
+		return b.ResponseWriter.Write(p)
+	}
+	if b.buf.Len()+len(p) > b.maxSize {
+		if err := b.startPassthrough(); err != nil {
+			return 0, err
+		}
+		return b.ResponseWriter.Write(p)
+	}
+	return b.buf.Write(p)
+}
+
+// startPassthrough sends the status and the buffered content, and stops buffering.
+func (b *bufferedWriter) startPassthrough() error {
+	b.passthrough = true
+	b.ResponseWriter.WriteHeader(b.Status())
+	_, err := b.ResponseWriter.Write(b.buf.Bytes())
+	b.buf.Reset()
+	return err
+}
+
+// Flush sends the response as is, without ETag, since streamed responses cannot be buffered.
+func (b *bufferedWriter) Flush() {
+	if !b.passthrough {
+		b.startPassthrough()
+	}
+	b.writerBase.Flush()
+}
+
+// Hijack gives up on the response, as the connection is taken over.
+func (b *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	b.passthrough = true
+	return b.writerBase.Hijack()
+}
+
+func (b *bufferedWriter) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(writerOnly{b}, r)
+}
+
+// Status returns the response status, or 200 if none has been set.
+func (b *bufferedWriter) Status() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+	return b.status
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETag(t *testing.T) {
+
+	handler := ETag(ETagConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte("hello"))
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag != ComputeETag([]byte("hello"), false) || w.Body.String() != "hello" {
+		t.Errorf("GET: expected 200 with ETag %s, got %d with %q", ComputeETag([]byte("hello"), false), w.Code, etag)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", `"other", W/`+etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("conditional GET: expected 304, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/", nil))
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Errorf("HEAD: expected 200 without ETag, got %d with %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestCheckNotModified(t *testing.T) {
+
+	modified := time.Date(2020, 5, 17, 12, 0, 0, 0, time.UTC)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetValidators(w, "", modified)
+		if !CheckNotModified(w, r) {
+			w.Write([]byte("hello"))
+		}
+	})
+
+	get := func(since time.Time) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("If-Modified-Since", since.Format(http.TimeFormat))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := get(modified); code != http.StatusNotModified {
+		t.Errorf("not modified since: expected 304, got %d", code)
+	}
+	if code := get(modified.Add(-time.Hour)); code != http.StatusOK {
+		t.Errorf("modified since: expected 200, got %d", code)
+	}
+}
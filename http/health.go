@@ -0,0 +1,236 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Adirelle/go-libs/cache"
+)
+
+// Conventional paths of the health endpoints.
+const (
+	HealthPath    = "/healthz"
+	LivenessPath  = "/livez"
+	ReadinessPath = "/readyz"
+)
+
+// DefaultHealthCheckTimeout is the timeout of checks registered without one.
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// Health check statuses.
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDraining = "draining"
+)
+
+// HealthCheck checks a component. It must return an error if the component is unhealthy.
+type HealthCheck func(ctx context.Context) error
+
+// Health is a registry of health checks. It implements http.Handler, serving the aggregated status as JSON.
+//
+// Liveness checks tell whether the process is working at all, while readiness checks tell whether
+// it can serve requests. The readiness also fails while the registry is draining, e.g. before shutdown.
+type Health struct {
+	checks   map[string]registeredCheck
+	draining int32
+	mu       sync.RWMutex
+}
+
+type registeredCheck struct {
+	check     HealthCheck
+	timeout   time.Duration
+	readiness bool
+}
+
+// NewHealth creates an empty Health registry.
+func NewHealth() *Health {
+	return &Health{checks: make(map[string]registeredCheck)}
+}
+
+// Register adds a named liveness check. A zero timeout means DefaultHealthCheckTimeout.
+func (h *Health) Register(name string, timeout time.Duration, check HealthCheck) {
+	h.register(name, timeout, check, false)
+}
+
+// RegisterReadiness adds a named readiness check. A zero timeout means DefaultHealthCheckTimeout.
+func (h *Health) RegisterReadiness(name string, timeout time.Duration, check HealthCheck) {
+	h.register(name, timeout, check, true)
+}
+
+func (h *Health) register(name string, timeout time.Duration, check HealthCheck, readiness bool) {
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = registeredCheck{check, timeout, readiness}
+}
+
+// Drain flips the readiness to down, so load balancers stop sending new requests.
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// Resume cancels Drain.
+func (h *Health) Resume() {
+	atomic.StoreInt32(&h.draining, 0)
+}
+
+// Draining indicates whether Drain has been called.
+func (h *Health) Draining() bool {
+	return atomic.LoadInt32(&h.draining) != 0
+}
+
+// Unregister removes a named check.
+func (h *Health) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.checks, name)
+}
+
+// HealthReport is the aggregated result of the checks.
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the result of one check.
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Check runs all the checks concurrently and returns the aggregated report.
+func (h *Health) Check(ctx context.Context) HealthReport {
+	return h.check(ctx, func(registeredCheck) bool { return true })
+}
+
+// CheckLiveness runs the liveness checks concurrently and returns the aggregated report.
+func (h *Health) CheckLiveness(ctx context.Context) HealthReport {
+	return h.check(ctx, func(c registeredCheck) bool { return !c.readiness })
+}
+
+// CheckReadiness runs all the checks concurrently and returns the aggregated report.
+// The status is "draining" after Drain has been called.
+func (h *Health) CheckReadiness(ctx context.Context) HealthReport {
+	report := h.Check(ctx)
+	if h.Draining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+func (h *Health) check(ctx context.Context, filter func(registeredCheck) bool) HealthReport {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checks))
+	checks := make([]registeredCheck, 0, len(h.checks))
+	for name, c := range h.checks {
+		if filter(c) {
+			names = append(names, name)
+			checks = append(checks, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	wg.Add(len(checks))
+	for i, c := range checks {
+		go func(i int, c registeredCheck) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: StatusUp, Checks: make(map[string]CheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (c registeredCheck) run(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.check(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timeout after %s", c.timeout)
+	}
+	res := CheckResult{Status: StatusUp, Duration: time.Since(started).String()}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Names returns the names of the registered checks, sorted.
+func (h *Health) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP serves the report as JSON, with a 503 status if any check failed.
+func (h *Health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, h.Check(r.Context()))
+}
+
+// LivenessHandler serves the liveness report as JSON, with a 503 status if any check failed.
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthReport(w, h.CheckLiveness(r.Context()))
+	})
+}
+
+// ReadinessHandler serves the readiness report as JSON, with a 503 status if any check failed
+// or the registry is draining.
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthReport(w, h.CheckReadiness(r.Context()))
+	})
+}
+
+func writeHealthReport(w http.ResponseWriter, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusUp {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// CacheHealthCheck returns a HealthCheck that fetches a probe key from the cache,
+// failing if the cache returns any error but cache.ErrKeyNotFound, e.g. when a backend is unreachable.
+func CacheHealthCheck(c cache.Cache) HealthCheck {
+	return func(context.Context) error {
+		if _, err := c.Get("__health_probe__"); err != nil && err != cache.ErrKeyNotFound {
+			return err
+		}
+		return nil
+	}
+}
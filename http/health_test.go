@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Adirelle/go-libs/cache"
+)
+
+func TestHealth(t *testing.T) {
+
+	h := NewHealth()
+	var dbErr error
+	h.Register("process", 0, func(context.Context) error { return nil })
+	h.RegisterReadiness("db", 0, func(context.Context) error { return dbErr })
+	h.RegisterReadiness("slow", 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	get := func(handler http.Handler) (int, HealthReport) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+		var report HealthReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("invalid report: %v", err)
+		}
+		return w.Code, report
+	}
+
+	if code, report := get(h.LivenessHandler()); code != http.StatusOK || len(report.Checks) != 1 {
+		t.Errorf("liveness: expected 200 with 1 check, got %d %+v", code, report)
+	}
+
+	code, report := get(h.ReadinessHandler())
+	if code != http.StatusServiceUnavailable || report.Checks["slow"].Error != "timeout after 10ms" || report.Checks["db"].Status != StatusUp {
+		t.Errorf("readiness: expected 503 with slow timed out, got %d %+v", code, report)
+	}
+
+	h.Unregister("slow")
+	dbErr = errors.New("connection refused")
+	if code, report := get(h); code != http.StatusServiceUnavailable || report.Checks["db"].Error != "connection refused" {
+		t.Errorf("health: expected 503 with db down, got %d %+v", code, report)
+	}
+
+	dbErr = nil
+	h.Drain()
+	if code, report := get(h.ReadinessHandler()); code != http.StatusServiceUnavailable || report.Status != StatusDraining {
+		t.Errorf("draining: expected 503 %s, got %d %+v", StatusDraining, code, report)
+	}
+	h.Resume()
+	if code, _ := get(h.ReadinessHandler()); code != http.StatusOK {
+		t.Errorf("resumed: expected 200, got %d", code)
+	}
+}
+
+func TestCacheHealthCheck(t *testing.T) {
+	if err := CacheHealthCheck(cache.NewMemoryStorage())(context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
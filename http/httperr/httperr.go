@@ -0,0 +1,102 @@
+// Package httperr defines errors that carry an HTTP status and a machine-readable code,
+// to be rendered as problem details (RFC 7807) responses.
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error is an error to be sent to the client.
+type Error struct {
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Code is a machine-readable identifier of the error, e.g. "invalid_parameter".
+	Code string
+
+	// Message is the human-readable description of the error, sent to the client.
+	Message string
+
+	// Cause is the underlying error, which is logged but never sent to the client.
+	Cause error
+}
+
+// New creates an Error.
+func New(status int, code, msg string) *Error {
+	return &Error{Status: status, Code: code, Message: msg}
+}
+
+// Newf creates an Error with a formatted message.
+func Newf(status int, code, format string, args ...interface{}) *Error {
+	return New(status, code, fmt.Sprintf(format, args...))
+}
+
+// Wrap returns a copy of the Error with the given cause.
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Cause = cause
+	return &wrapped
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%d %s: %s: %s", e.Status, e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the target is an Error with the same status and code,
+// so errors.Is(err, ErrNotFound) matches wrapped copies.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Status == e.Status && t.Code == e.Code
+}
+
+// Common errors.
+var (
+	ErrBadRequest       = New(http.StatusBadRequest, "bad_request", "the request is invalid")
+	ErrUnauthorized     = New(http.StatusUnauthorized, "unauthorized", "authentication is required")
+	ErrForbidden        = New(http.StatusForbidden, "forbidden", "access is denied")
+	ErrNotFound         = New(http.StatusNotFound, "not_found", "the resource does not exist")
+	ErrMethodNotAllowed = New(http.StatusMethodNotAllowed, "method_not_allowed", "the method is not allowed")
+	ErrConflict         = New(http.StatusConflict, "conflict", "the request conflicts with the current state")
+	ErrInternal         = New(http.StatusInternalServerError, "internal_error", "an internal error occurred")
+	ErrUnavailable      = New(http.StatusServiceUnavailable, "unavailable", "the service is temporarily unavailable")
+	ErrTimeout          = New(http.StatusGatewayTimeout, "timeout", "the request deadline was exceeded")
+)
+
+// From returns the Error in the chain of err, or ErrInternal wrapping err.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// Problem is a problem details object, as defined by RFC 7807.
+type Problem struct {
+	Type      string `json:"type,omitempty" xml:"type,omitempty"`
+	Title     string `json:"title" xml:"title"`
+	Status    int    `json:"status" xml:"status"`
+	Detail    string `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty" xml:"instance,omitempty"`
+	Code      string `json:"code,omitempty" xml:"code,omitempty"`
+	RequestID string `json:"requestId,omitempty" xml:"requestId,omitempty"`
+}
+
+// Problem returns the problem details of the Error.
+func (e *Error) Problem() Problem {
+	return Problem{
+		Title:  http.StatusText(e.Status),
+		Status: e.Status,
+		Detail: e.Message,
+		Code:   e.Code,
+	}
+}
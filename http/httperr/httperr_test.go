@@ -0,0 +1,36 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+
+	cause := errors.New("no such row")
+	err := fmt.Errorf("loading user: %w", ErrNotFound.Wrap(cause))
+
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) {
+		t.Errorf("Is: expected %v to match ErrNotFound only", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("Is: expected %v to wrap %v", err, cause)
+	}
+	if ErrNotFound.Cause != nil {
+		t.Errorf("Wrap: ErrNotFound must not be modified, got cause %v", ErrNotFound.Cause)
+	}
+
+	if e := From(err); e.Status != http.StatusNotFound || e.Cause != cause {
+		t.Errorf("From: expected the wrapped ErrNotFound, got %v", e)
+	}
+	if e := From(cause); e.Status != http.StatusInternalServerError || e.Cause != cause {
+		t.Errorf("From: expected ErrInternal wrapping the cause, got %v", e)
+	}
+
+	p := Newf(http.StatusBadRequest, "invalid_parameter", "invalid %s", "id").Problem()
+	if p.Status != http.StatusBadRequest || p.Title != "Bad Request" || p.Detail != "invalid id" || p.Code != "invalid_parameter" {
+		t.Errorf("Problem: unexpected %+v", p)
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+const (
+	claimsKey = contextKey(4)
+)
+
+// JWTConfig configures the JWTAuth middleware.
+type JWTConfig struct {
+	// KeyFunc provides the key used to verify the tokens, see JWKSKeyFunc.
+	KeyFunc jwt.Keyfunc
+
+	// Methods lists the accepted signing methods, e.g. "RS256". It is required, so tokens signed with another
+	// algorithm than the one of the keys, e.g. HS256 using an RSA public key as secret, are rejected.
+	Methods []string
+
+	// Audience, if not empty, is the expected "aud" claim.
+	Audience string
+
+	// Issuer, if not empty, is the expected "iss" claim.
+	Issuer string
+
+	// Authorize, if not nil, checks the claims of valid tokens. Requests are forbidden if it returns false.
+	Authorize func(r *http.Request, claims jwt.MapClaims) bool
+}
+
+// JWKSKeyFunc returns a jwt.Keyfunc that fetches the keys from the given JWKS URLs and refreshes them in background.
+func JWKSKeyFunc(urls ...string) (jwt.Keyfunc, error) {
+	k, err := keyfunc.NewDefault(urls)
+	if err != nil {
+		return nil, err
+	}
+	return k.Keyfunc, nil
+}
+
+// JWTAuth returns a middleware that requires a valid JWT in the Authorization header, using the Bearer scheme.
+// The claims are added to the Request Context, and the subject to any associated Logger.
+// It panics if no signing methods are listed.
+func JWTAuth(conf JWTConfig) func(http.Handler) http.Handler {
+	if len(conf.Methods) == 0 {
+		panic("JWTAuth: the accepted signing methods must be listed")
+	}
+	opts := []jwt.ParserOption{jwt.WithValidMethods(conf.Methods)}
+	if conf.Audience != "" {
+		opts = append(opts, jwt.WithAudience(conf.Audience))
+	}
+	if conf.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(conf.Issuer))
+	}
+	parser := jwt.NewParser(opts...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			logger := logging.FromContext(ctx, nil)
+
+			raw, found := bearerToken(r)
+			if !found {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				writeAuthError(w, http.StatusUnauthorized, "missing_token", "missing bearer token")
+				return
+			}
+
+			claims := jwt.MapClaims{}
+			if _, err := parser.ParseWithClaims(raw, claims, conf.KeyFunc); err != nil {
+				if logger != nil {
+					logger.Infow("invalid token", logging.ErrorKey, err)
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeAuthError(w, http.StatusUnauthorized, "invalid_token", err.Error())
+				return
+			}
+
+			subject, _ := claims.GetSubject()
+			if conf.Authorize != nil && !conf.Authorize(r, claims) {
+				if logger != nil {
+					logger.Infow("access denied", "subject", subject)
+				}
+				writeAuthError(w, http.StatusForbidden, "insufficient_scope", "access denied")
+				return
+			}
+
+			if logger != nil {
+				ctx = logging.WithLogger(ctx, logger.With("subject", subject))
+			}
+			ctx = context.WithValue(ctx, claimsKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClaimsFromContext retrieves the JWT claims from the Context, or nil.
+func ClaimsFromContext(ctx context.Context) jwt.MapClaims {
+	claims, _ := ctx.Value(claimsKey).(jwt.MapClaims)
+	return claims
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(auth[7:])
+	return token, token != ""
+}
+
+func writeAuthError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}{code, msg})
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTAuth(t *testing.T) {
+
+	key := []byte("secret")
+	sign := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	var claims jwt.MapClaims
+	handler := JWTAuth(JWTConfig{
+		KeyFunc:  func(*jwt.Token) (interface{}, error) { return key, nil },
+		Methods:  []string{"HS256"},
+		Audience: "api",
+		Authorize: func(r *http.Request, claims jwt.MapClaims) bool {
+			return claims["scope"] == "admin"
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = ClaimsFromContext(r.Context())
+	}))
+
+	get := func(auth string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get(""); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Errorf("no token: expected 401 with a challenge, got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	expired := sign(jwt.MapClaims{"sub": "alice", "aud": "api", "exp": time.Now().Add(-time.Minute).Unix()})
+	if w := get("Bearer " + expired); w.Code != http.StatusUnauthorized {
+		t.Errorf("expired token: expected 401, got %d", w.Code)
+	}
+	if w := get("Bearer " + sign(jwt.MapClaims{"sub": "alice", "aud": "other"})); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong audience: expected 401, got %d", w.Code)
+	}
+	if w := get("Bearer " + sign(jwt.MapClaims{"sub": "alice", "aud": "api"})); w.Code != http.StatusForbidden {
+		t.Errorf("unauthorized: expected 403, got %d", w.Code)
+	}
+	if w := get("bearer " + sign(jwt.MapClaims{"sub": "alice", "aud": "api", "scope": "admin"})); w.Code != http.StatusOK || claims["sub"] != "alice" {
+		t.Errorf("valid token: expected 200 for alice, got %d %v", w.Code, claims)
+	}
+}
+
+func TestJWTAuthMethods(t *testing.T) {
+
+	key := []byte("secret")
+	handler := JWTAuth(JWTConfig{
+		KeyFunc: func(*jwt.Token) (interface{}, error) { return key, nil },
+		Methods: []string{"HS256"},
+	})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS384, jwt.MapClaims{"sub": "alice"}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("other method: expected 401, got %d", w.Code)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no methods: expected a panic")
+		}
+	}()
+	JWTAuth(JWTConfig{KeyFunc: func(*jwt.Token) (interface{}, error) { return key, nil }})
+}
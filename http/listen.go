@@ -0,0 +1,54 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// UnixPrefix is the prefix of addresses designating Unix domain sockets, e.g. "unix:/run/app.sock".
+const UnixPrefix = "unix:"
+
+func (w *Service) listen(addr string) (net.Listener, error) {
+	if path := strings.TrimPrefix(addr, UnixPrefix); path != addr {
+		return listenUnix(path, w.SocketMode)
+	}
+	return net.Listen("tcp", addr)
+}
+
+// listenUnix listens on a Unix domain socket, removing any stale socket file
+// and setting the socket permissions if mode is not zero.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func removeStaleSocket(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is already in use", path)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+func TestServeUnixSocket(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "app.sock")
+
+	// Leave a stale socket file behind.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("cannot listen on a Unix socket: %s", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	w := &Service{Logger: logging.NewTesting(t), Addrs: []string{UnixPrefix + path}, SocketMode: 0600}
+	w.Addr = freeAddr(t)
+	w.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, "hello")
+	})
+	startService(t, w, w.Addr)
+	waitListening(t, "unix", path)
+
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("expected a socket with mode 0600, got %v, %v", fi, err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://app/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello" {
+		t.Errorf("expected hello, got %q", body)
+	}
+}
+
+func TestListenUnixErrors(t *testing.T) {
+
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "regular")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenUnix(file, 0); err == nil {
+		t.Errorf("regular file: expected an error")
+	}
+
+	path := filepath.Join(dir, "app.sock")
+	l, err := listenUnix(path, 0)
+	if err != nil {
+		t.Skipf("cannot listen on a Unix socket: %s", err)
+	}
+	defer l.Close()
+	if _, err := listenUnix(path, 0); err == nil {
+		t.Errorf("socket in use: expected an error")
+	}
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrMaintenance is the error of the readiness check while the maintenance mode is enabled.
+var ErrMaintenance = errors.New("maintenance mode enabled")
+
+// MaintenanceConfig configures a Maintenance switch.
+type MaintenanceConfig struct {
+	// RetryAfter is sent in the Retry-After header, if not zero.
+	RetryAfter time.Duration
+
+	// Body is the response body. It defaults to the status text.
+	Body []byte
+
+	// ContentType is the type of Body. It defaults to "text/plain; charset=utf-8".
+	ContentType string
+
+	// ExemptPrefixes lists path prefixes of requests that are served anyway.
+	// It defaults to the health endpoints.
+	ExemptPrefixes []string
+
+	// Exempt, if not nil, tells whether a request should be served anyway, e.g. for administrators.
+	Exempt func(*http.Request) bool
+
+	// File, if not empty, enables the maintenance mode while it exists. See Maintenance.Watch.
+	File string
+
+	// EnvVar, if not empty, enables the maintenance mode on startup if it is set to a true value.
+	EnvVar string
+}
+
+// Maintenance is a toggleable maintenance mode. While enabled, its middleware replies to all the non-exempt
+// requests with 503 Service Unavailable.
+type Maintenance struct {
+	conf    MaintenanceConfig
+	enabled int32
+	file    int32
+}
+
+// NewMaintenance creates a Maintenance switch, enabled if the environment variable says so.
+func NewMaintenance(conf MaintenanceConfig) *Maintenance {
+	if len(conf.Body) == 0 {
+		conf.Body = []byte(http.StatusText(http.StatusServiceUnavailable) + "\n")
+		conf.ContentType = ""
+	}
+	if conf.ContentType == "" {
+		conf.ContentType = "text/plain; charset=utf-8"
+	}
+	if conf.ExemptPrefixes == nil {
+		conf.ExemptPrefixes = []string{HealthPath, LivenessPath, ReadinessPath}
+	}
+	m := &Maintenance{conf: conf}
+	if conf.EnvVar != "" {
+		if enabled, err := strconv.ParseBool(os.Getenv(conf.EnvVar)); err == nil && enabled {
+			m.Enable()
+		}
+	}
+	m.checkFile()
+	return m
+}
+
+// Enable enables the maintenance mode.
+func (m *Maintenance) Enable() {
+	atomic.StoreInt32(&m.enabled, 1)
+}
+
+// Disable disables the maintenance mode. It stays enabled while the trigger file exists.
+func (m *Maintenance) Disable() {
+	atomic.StoreInt32(&m.enabled, 0)
+}
+
+// Enabled indicates whether the maintenance mode is enabled.
+func (m *Maintenance) Enabled() bool {
+	return atomic.LoadInt32(&m.enabled) != 0 || atomic.LoadInt32(&m.file) != 0
+}
+
+// Watch checks the existence of the trigger file at the given interval, until stop is called.
+func (m *Maintenance) Watch(interval time.Duration) (stop func()) {
+	if m.conf.File == "" {
+		return func() {}
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.checkFile()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (m *Maintenance) checkFile() {
+	if m.conf.File == "" {
+		return
+	}
+	var exists int32
+	if _, err := os.Stat(m.conf.File); err == nil {
+		exists = 1
+	}
+	atomic.StoreInt32(&m.file, exists)
+}
+
+// ReadinessCheck returns a HealthCheck that fails while the maintenance mode is enabled,
+// to be registered with Health.RegisterReadiness.
+func (m *Maintenance) ReadinessCheck() HealthCheck {
+	return func(context.Context) error {
+		if m.Enabled() {
+			return ErrMaintenance
+		}
+		return nil
+	}
+}
+
+// Middleware returns 503 Service Unavailable to all the non-exempt requests while the maintenance mode is enabled.
+func (m *Maintenance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.Enabled() || m.exempts(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		h := w.Header()
+		h.Set("Content-Type", m.conf.ContentType)
+		h.Set("Cache-Control", "no-store")
+		if m.conf.RetryAfter > 0 {
+			h.Set("Retry-After", strconv.Itoa(int((m.conf.RetryAfter+time.Second-1)/time.Second)))
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		if r.Method != http.MethodHead {
+			w.Write(m.conf.Body)
+		}
+	})
+}
+
+func (m *Maintenance) exempts(r *http.Request) bool {
+	for _, prefix := range m.conf.ExemptPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return m.conf.Exempt != nil && m.conf.Exempt(r)
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "maintenance")
+	m := NewMaintenance(MaintenanceConfig{
+		RetryAfter: 1500 * time.Millisecond,
+		File:       file,
+		Exempt:     func(r *http.Request) bool { return r.Header.Get("X-Admin") != "" },
+	})
+	handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	get := func(path string, admin bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if admin {
+			r.Header.Set("X-Admin", "yes")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get("/", false); w.Code != http.StatusOK {
+		t.Errorf("disabled: expected 200, got %d", w.Code)
+	}
+
+	m.Enable()
+	if w := get("/", false); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" {
+		t.Errorf("enabled: expected 503 with Retry-After: 2, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := get(ReadinessPath, false); w.Code != http.StatusOK {
+		t.Errorf("health endpoint: expected 200, got %d", w.Code)
+	}
+	if w := get("/", true); w.Code != http.StatusOK {
+		t.Errorf("exempted: expected 200, got %d", w.Code)
+	}
+	if err := m.ReadinessCheck()(context.Background()); err != ErrMaintenance {
+		t.Errorf("ReadinessCheck: expected %v, got %v", ErrMaintenance, err)
+	}
+
+	m.Disable()
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stop := m.Watch(time.Millisecond)
+	defer stop()
+	time.Sleep(20 * time.Millisecond)
+	if w := get("/", false); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("file: expected 503, got %d", w.Code)
+	}
+	os.Remove(file)
+	time.Sleep(20 * time.Millisecond)
+	if w := get("/", false); w.Code != http.StatusOK {
+		t.Errorf("file removed: expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"github.com/gorilla/mux"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// ListenAddresses lists the addresses the Service provided by Module listens on.
+type ListenAddresses []string
+
+// Module provides the HTTP stack to a dic container. Register it using dic.BaseContainer.RegisterFrom:
+//
+//	c.RegisterFrom(&http.Module{
+//		Logging:     logging.Config{Level: levels},
+//		Middlewares: http.NewChain(http.UniqueID, http.DebugRequest),
+//		Addresses:   http.ListenAddresses{":8080"},
+//	})
+//
+// The fields are provided as constants, and the methods as singletons. Routes are added to the *mux.Router,
+// then the *Service is fetched, and started and stopped with the container:
+//
+//	var service *http.Service
+//	if err := c.Fetch(&service); err != nil { ... }
+//	if err := c.Start(ctx); err != nil { ... }
+//	defer c.Stop(ctx)
+type Module struct {
+	// Logging configures the logging Factory.
+	Logging logging.Config
+
+	// Middlewares wraps the router.
+	Middlewares Chain
+
+	// Addresses lists the addresses to listen on. It defaults to ":http".
+	Addresses ListenAddresses
+}
+
+// LoggerFactory builds the logging Factory, which is closed when the container is stopped.
+func (*Module) LoggerFactory(conf logging.Config) *logging.Factory {
+	return conf.Build()
+}
+
+// Router creates the router, with an URLGenerator in the request contexts.
+func (*Module) Router() *mux.Router {
+	router := mux.NewRouter()
+	router.Use(AddURLGenerator(router))
+	return router
+}
+
+// Service creates the Service, with the default ServiceConfig, serving the router wrapped in the middlewares,
+// and setting the request loggers from the "http" logger.
+func (*Module) Service(router *mux.Router, middlewares Chain, factory *logging.Factory, addrs ListenAddresses) (*Service, error) {
+	logger := factory.Get("http")
+	handler := NewChain(logging.AddLogger(logger)).Extend(middlewares).Then(router)
+	return NewService(ServiceConfig{Addrs: addrs}, handler, logger)
+}
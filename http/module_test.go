@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Adirelle/go-libs/dic"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+func TestModule(t *testing.T) {
+
+	var logger logging.Logger
+	logFile := filepath.Join(t.TempDir(), "test.log")
+	c := dic.New()
+	c.RegisterFrom(&Module{
+		Logging: logging.Config{
+			Level:       logging.LoggerLevels{logging.RootLoggerName: logging.InfoLevel},
+			Outputs:     []logging.OutputConfig{{Path: logFile}},
+			Aggregation: time.Hour,
+		},
+		Middlewares: NewChain(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logger = logging.FromContext(r.Context(), nil)
+				next.ServeHTTP(w, r)
+			})
+		}, UniqueID),
+		Addresses: ListenAddresses{freeAddr(t)},
+	})
+
+	var router *mux.Router
+	if err := c.Fetch(&router); err != nil {
+		t.Fatalf("Fetch: unexpected error %v", err)
+	}
+	var factory *logging.Factory
+	if err := c.Fetch(&factory); err != nil {
+		t.Fatalf("Fetch: unexpected error %v", err)
+	}
+	router.Path("/hello").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		factory.Get("app").Error("boom")
+		w.Write([]byte("hello"))
+	})
+
+	var service *Service
+	if err := c.Fetch(&service); err != nil {
+		t.Fatalf("Fetch: unexpected error %v", err)
+	}
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start: unexpected error %v", err)
+	}
+	stopped := false
+	defer func() {
+		if !stopped {
+			c.Stop(ctx)
+		}
+	}()
+
+	resp, err := http.Get("http://" + service.Addrs[0] + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || resp.Header.Get("X-UniqueID") == "" {
+		t.Errorf("expected a 404 from the router, through the middlewares, got %d", resp.StatusCode)
+	}
+	if logger == nil || logger != service.Logger {
+		t.Errorf("expected the http Logger in the request context, got %v", logger)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get("http://" + service.Addrs[0] + "/hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "hello" {
+			t.Errorf("expected hello from the fetched router, got %d %q", resp.StatusCode, body)
+		}
+	}
+
+	// Stopping the container closes the logging Factory, which writes the pending summaries.
+	stopped = true
+	if err := c.Stop(ctx); err != nil {
+		t.Errorf("Stop: unexpected error %v", err)
+	}
+	if content, _ := ioutil.ReadFile(logFile); !strings.Contains(string(content), "boom (repeated 1 times in 1h0m0s)") {
+		t.Errorf("expected the error summary in the logs, got %q", content)
+	}
+}
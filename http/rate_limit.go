@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/gob"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Adirelle/go-libs/cache"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// RateLimitConfig configures the RateLimit middleware.
+type RateLimitConfig struct {
+	// Rate is the number of requests per second allowed in the long run.
+	Rate float64
+
+	// Burst is the number of requests that can be made at once.
+	Burst int
+
+	// Key extracts the client key from the request. It defaults to ClientIP.
+	Key func(*http.Request) string
+
+	// Cache stores the state of the clients. It defaults to a memory storage limited to 10000 clients.
+	// A shared cache lets several instances share the buckets of the clients. However, the buckets are only locked
+	// within an instance, so concurrent requests reaching different instances may exceed the limit slightly.
+	Cache cache.Cache
+}
+
+// TokenBucket is the state of a client, as stored in the cache.
+type TokenBucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+func init() {
+	gob.Register(TokenBucket{})
+}
+
+// ClientIP returns the IP address of the client, without the port.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimit returns a middleware that limits the rate of requests per client, using a token bucket.
+// Requests above the limit get a 429 response with a Retry-After header. It panics if the rate is not positive.
+func RateLimit(conf RateLimitConfig) func(http.Handler) http.Handler {
+	if !(conf.Rate > 0) {
+		panic("RateLimit: rate must be positive")
+	}
+	if conf.Key == nil {
+		conf.Key = ClientIP
+	}
+	if conf.Burst < 1 {
+		conf.Burst = 1
+	}
+	if conf.Cache == nil {
+		refill := time.Duration(float64(conf.Burst) / conf.Rate * float64(time.Second))
+		conf.Cache = cache.NewMemoryStorage(cache.LRUEviction(10000), cache.Expiration(refill))
+	}
+	l := &rateLimiter{conf: conf}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := conf.Key(r)
+			if wait := l.take(key, time.Now()); wait > 0 {
+				if logger := logging.FromContext(r.Context(), nil); logger != nil {
+					logger.Infow("rate limit exceeded", "client", key)
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type rateLimiter struct {
+	conf RateLimitConfig
+	mu   sync.Mutex
+}
+
+// take consumes a token from the client bucket. If there is none, it returns how long to wait for the next one.
+func (l *rateLimiter) take(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := TokenBucket{Tokens: float64(l.conf.Burst), Updated: now}
+	if value, err := l.conf.Cache.Get(key); err == nil {
+		if stored, ok := value.(TokenBucket); ok {
+			b = stored
+			b.Tokens = math.Min(float64(l.conf.Burst), b.Tokens+now.Sub(b.Updated).Seconds()*l.conf.Rate)
+			b.Updated = now
+		}
+	}
+
+	var wait time.Duration
+	if b.Tokens >= 1 {
+		b.Tokens--
+	} else {
+		wait = time.Duration((1 - b.Tokens) / l.conf.Rate * float64(time.Second))
+	}
+	l.conf.Cache.Put(key, b)
+	return wait
+}
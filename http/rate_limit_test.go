@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimit(t *testing.T) {
+
+	handler := RateLimit(RateLimitConfig{Rate: 1, Burst: 2})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	get := func(addr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := get("10.0.0.1:1234"); w.Code != http.StatusOK {
+			t.Errorf("request %d: expected 200, got %d", i, w.Code)
+		}
+	}
+	if w := get("10.0.0.1:5678"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected 429 with Retry-After: 1, got %d with %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := get("10.0.0.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("other client: expected 200, got %d", w.Code)
+	}
+}
+
+func TestRateLimitInvalidRate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RateLimit: expected a panic")
+		}
+	}()
+	RateLimit(RateLimitConfig{})
+}
@@ -1,39 +1,162 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"go.uber.org/zap/zapcore"
+
 	"github.com/Adirelle/go-libs/logging"
 )
 
+// AccessLogConfig configures the AccessLog middleware.
+type AccessLogConfig struct {
+	// Levels maps status classes (e.g. 4 for 4xx) to the level used to log the requests.
+	// Missing classes are logged at Error level.
+	Levels map[int]zapcore.Level
+
+	// Skip lists path prefixes of requests that are not logged, e.g. health checks.
+	Skip []string
+
+	// SampleRate is the fraction of requests that are logged, between 0 and 1.
+	// Requests logged at Warn level or above are always logged. Zero means all requests are logged.
+	SampleRate float64
+
+	// Fields, if not nil, returns additional fields to log at the end of the request.
+	Fields func(r *http.Request, status int) []interface{}
+
+	// SlowThreshold, if not zero, is the duration above which requests are logged at Warn level, at least.
+	SlowThreshold time.Duration
+
+	// LogStart enables logging of the start of requests, at Debug level.
+	LogStart bool
+
+	// Capture, if not nil, enables logging of the request and response bodies, at Debug level.
+	Capture *BodyCaptureConfig
+}
+
+// BodyCaptureConfig configures the capture of request and response bodies by AccessLog.
+type BodyCaptureConfig struct {
+	// MaxSize is the maximum number of bytes captured from each body. It defaults to 4096.
+	MaxSize int
+
+	// ContentTypes lists the prefixes of the content types of captured bodies.
+	// It defaults to JSON, XML, form and text contents.
+	ContentTypes []string
+
+	// Redact, if not nil, is called to hide sensitive data before the bodies are logged.
+	Redact func(body []byte, contentType string) []byte
+}
+
+func (c *BodyCaptureConfig) setDefaults() {
+	if c.MaxSize <= 0 {
+		c.MaxSize = 4096
+	}
+	if c.ContentTypes == nil {
+		c.ContentTypes = []string{"application/json", "application/xml", "application/x-www-form-urlencoded", "text/"}
+	}
+}
+
+func (c *BodyCaptureConfig) accepts(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range c.ContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultAccessLogConfig returns the configuration used by DebugRequest.
+func DefaultAccessLogConfig() AccessLogConfig {
+	return AccessLogConfig{
+		Levels: map[int]zapcore.Level{
+			1: logging.DebugLevel,
+			2: logging.DebugLevel,
+			3: logging.DebugLevel,
+			4: logging.InfoLevel,
+			5: logging.ErrorLevel,
+		},
+		LogStart: true,
+	}
+}
+
+// AccessLog returns a middleware that logs requests to their associated logger, if any.
+func AccessLog(conf AccessLogConfig) func(http.Handler) http.Handler {
+	if conf.Capture != nil {
+		capture := *conf.Capture
+		capture.setDefaults()
+		conf.Capture = &capture
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if conf.skips(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			drw := &debugResponseWriter{writerBase: writerBase{w}, l: logging.MustFromContext(r.Context()), conf: &conf}
+			if conf.Capture != nil && r.Body != nil && conf.Capture.accepts(r.Header.Get("Content-Type")) {
+				drw.reqBody = &bodyCapture{max: conf.Capture.MaxSize}
+				r.Body = &captureReader{r.Body, drw.reqBody}
+			}
+			drw.Starts(r)
+			defer drw.Ends(r)
+			next.ServeHTTP(wrapWriter(drw), r)
+		})
+	}
+}
+
+var debugRequest = AccessLog(DefaultAccessLogConfig())
+
 // DebugRequest logs request start, status to its associated logger, if any
 func DebugRequest(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		drw := &debugResponseWriter{w: w, l: logging.MustFromContext(r.Context())}
-		drw.Starts(r)
-		defer drw.Ends(r)
-		next.ServeHTTP(drw, r)
-	})
+	return debugRequest(next)
+}
+
+func (c *AccessLogConfig) skips(r *http.Request) bool {
+	for _, prefix := range c.Skip {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *AccessLogConfig) level(status int, elapsed time.Duration) zapcore.Level {
+	level, found := c.Levels[status/100]
+	if !found {
+		level = logging.ErrorLevel
+	}
+	if c.SlowThreshold > 0 && elapsed > c.SlowThreshold && level < logging.WarnLevel {
+		level = logging.WarnLevel
+	}
+	return level
 }
 
 type debugResponseWriter struct {
-	w       http.ResponseWriter
+	writerBase
 	l       logging.Logger
+	conf    *AccessLogConfig
 	size    int
 	started time.Time
 	status  int
+	reqBody *bodyCapture
+	resBody *bodyCapture
 }
 
 func (d *debugResponseWriter) Starts(r *http.Request) {
 	d.started = time.Now()
-	args := []interface{}{
-		"remote", r.RemoteAddr,
-		"host", r.Host,
-		"method", r.Method,
-		"url", r.URL,
+	if !d.conf.LogStart {
+		return
 	}
+	args := logging.RequestFields(r)
 	if cType := r.Header.Get("Content-Type"); cType != "" {
 		args = append(args, "content-type", cType)
 	}
@@ -41,36 +164,69 @@ func (d *debugResponseWriter) Starts(r *http.Request) {
 }
 
 func (d *debugResponseWriter) Ends(r *http.Request) {
-	args := []interface{}{
-		"remote", r.RemoteAddr,
-		"host", r.Host,
-		"method", r.Method,
-		"url", r.URL,
-		"status", d.status,
-		"elapsed", time.Since(d.started).String(),
-		"content-length", d.size,
+	status := d.status
+	if status == 0 {
+		// Nothing has been written, net/http sends an empty 200 response.
+		status = http.StatusOK
+	}
+	elapsed := time.Since(d.started)
+	level := d.conf.level(status, elapsed)
+	if level < logging.WarnLevel && d.conf.SampleRate > 0 && rand.Float64() >= d.conf.SampleRate {
+		return
 	}
-	if cType := d.w.Header().Get("Content-Type"); cType != "" {
+
+	args := append(
+		logging.RequestFields(r),
+		"status", status,
+		logging.DurationKey, elapsed.String(),
+		"content-length", d.size,
+	)
+	if cType := d.Header().Get("Content-Type"); cType != "" {
 		args = append(args, "content-type", cType)
 	}
-	msg := fmt.Sprintf("request: %d %s", d.status, http.StatusText(d.status))
-	if d.status < 100 || d.status >= 500 {
-		d.l.Errorw(msg, args...)
-	} else if d.status >= 400 {
-		d.l.Infow(msg, args...)
-	} else {
-		d.l.Debugw(msg, args...)
+	if d.conf.Fields != nil {
+		args = append(args, d.conf.Fields(r, status)...)
+	}
+	msg := fmt.Sprintf("request: %d %s", status, http.StatusText(status))
+	logw(d.l, level, msg, args...)
+
+	if d.reqBody != nil || d.resBody != nil {
+		d.logBodies(r)
+	}
+}
+
+func (d *debugResponseWriter) logBodies(r *http.Request) {
+	var args []interface{}
+	if d.reqBody != nil {
+		args = append(args, d.reqBody.fields("requestBody", r.Header.Get("Content-Type"), d.conf.Capture)...)
+	}
+	if d.resBody != nil {
+		args = append(args, d.resBody.fields("responseBody", d.Header().Get("Content-Type"), d.conf.Capture)...)
 	}
+	d.l.Debugw("request bodies", args...)
 }
 
-func (d *debugResponseWriter) Header() http.Header {
-	return d.w.Header()
+// logw logs a message with fields at the given level.
+func logw(l logging.Logger, level zapcore.Level, msg string, args ...interface{}) {
+	switch {
+	case level <= logging.DebugLevel:
+		l.Debugw(msg, args...)
+	case level == logging.InfoLevel:
+		l.Infow(msg, args...)
+	case level == logging.WarnLevel:
+		l.Warnw(msg, args...)
+	default:
+		l.Errorw(msg, args...)
+	}
 }
 
 func (d *debugResponseWriter) Write(b []byte) (n int, err error) {
 	d.WriteHeader(http.StatusOK)
-	n, err = d.w.Write(b)
+	n, err = d.ResponseWriter.Write(b)
 	d.size += n
+	if d.resBody != nil {
+		d.resBody.Write(b[:n])
+	}
 	return
 }
 
@@ -79,18 +235,71 @@ func (d *debugResponseWriter) WriteHeader(statusCode int) {
 		return
 	}
 	d.status = statusCode
-	d.w.WriteHeader(statusCode)
+	if d.conf.Capture != nil && d.conf.Capture.accepts(d.Header().Get("Content-Type")) {
+		d.resBody = &bodyCapture{max: d.conf.Capture.MaxSize}
+	}
+	d.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (d *debugResponseWriter) Flush() {
+	d.WriteHeader(http.StatusOK)
+	d.writerBase.Flush()
 }
 
-func (d *debugResponseWriter) CloseNotify() <-chan bool {
-	if cn, isCloseNotifier := d.w.(http.CloseNotifier); isCloseNotifier {
-		return cn.CloseNotify()
+func (d *debugResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if d.status == 0 {
+		d.status = http.StatusSwitchingProtocols
 	}
-	return nil
+	return d.writerBase.Hijack()
 }
 
-func (d *debugResponseWriter) Flush() {
-	if f, isFlusher := d.w.(http.Flusher); isFlusher {
-		f.Flush()
+func (d *debugResponseWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	d.WriteHeader(http.StatusOK)
+	if d.resBody != nil {
+		return io.Copy(writerOnly{d}, r)
 	}
+	n, err = d.writerBase.ReadFrom(r)
+	d.size += int(n)
+	return
+}
+
+// bodyCapture keeps the first bytes of a body.
+type bodyCapture struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (c *bodyCapture) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := c.max - c.buf.Len(); n > room {
+		c.truncated = true
+		p = p[:room]
+	}
+	c.buf.Write(p)
+	return n, nil
+}
+
+func (c *bodyCapture) fields(key, contentType string, conf *BodyCaptureConfig) []interface{} {
+	body := c.buf.Bytes()
+	if conf.Redact != nil {
+		body = conf.Redact(body, contentType)
+	}
+	fields := []interface{}{key, string(body)}
+	if c.truncated {
+		fields = append(fields, key+"Truncated", true)
+	}
+	return fields
+}
+
+// captureReader captures what is read from a request body.
+type captureReader struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (r *captureReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	r.capture.Write(p[:n])
+	return
 }
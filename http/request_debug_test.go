@@ -0,0 +1,150 @@
+package http
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// recordingLogger records the structured entries logged by the middlewares.
+type recordingLogger struct {
+	logging.Logger
+	entries []string
+	fields  [][]interface{}
+}
+
+func (l *recordingLogger) record(level, msg string, args []interface{}) {
+	l.entries = append(l.entries, level+" "+msg)
+	l.fields = append(l.fields, args)
+}
+
+func (l *recordingLogger) Debugw(msg string, args ...interface{}) { l.record("DEBUG", msg, args) }
+func (l *recordingLogger) Infow(msg string, args ...interface{})  { l.record("INFO", msg, args) }
+func (l *recordingLogger) Warnw(msg string, args ...interface{})  { l.record("WARN", msg, args) }
+func (l *recordingLogger) Errorw(msg string, args ...interface{}) { l.record("ERROR", msg, args) }
+
+func TestAccessLog(t *testing.T) {
+
+	conf := DefaultAccessLogConfig()
+	conf.LogStart = false
+	conf.Skip = []string{"/health"}
+	conf.SlowThreshold = 20 * time.Millisecond
+	conf.Fields = func(r *http.Request, status int) []interface{} {
+		return []interface{}{"user", "alice"}
+	}
+	l := &recordingLogger{Logger: logging.NewTesting(t)}
+	handler := logging.AddLogger(l)(AccessLog(conf)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/slow":
+			time.Sleep(30 * time.Millisecond)
+		case "/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})))
+
+	for _, path := range []string{"/", "/health/live", "/missing", "/slow", "/broken"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := []string{
+		"DEBUG request: 200 OK",
+		"INFO request: 404 Not Found",
+		"WARN request: 200 OK",
+		"ERROR request: 502 Bad Gateway",
+	}
+	if !reflect.DeepEqual(l.entries, expected) {
+		t.Errorf("expected %q, got %q", expected, l.entries)
+	}
+	if fields := l.fields[0]; len(fields) < 2 || fields[len(fields)-2] != "user" || fields[len(fields)-1] != "alice" {
+		t.Errorf("expected the additional fields, got %v", fields)
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+
+	conf := DefaultAccessLogConfig()
+	conf.LogStart = false
+	conf.SampleRate = 1e-12
+	l := &recordingLogger{Logger: logging.NewTesting(t)}
+	status := http.StatusOK
+	handler := logging.AddLogger(l)(AccessLog(conf)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})))
+
+	for i := 0; i < 10; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	status = http.StatusInternalServerError
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// Errors are never sampled out.
+	expected := []string{"ERROR request: 500 Internal Server Error"}
+	if !reflect.DeepEqual(l.entries, expected) {
+		t.Errorf("expected %q, got %q", expected, l.entries)
+	}
+}
+
+func TestDebugRequest(t *testing.T) {
+
+	l := &recordingLogger{Logger: logging.NewTesting(t)}
+	handler := logging.AddLogger(l)(DebugRequest(http.NotFoundHandler()))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	expected := []string{"DEBUG handling request", "INFO request: 404 Not Found"}
+	if !reflect.DeepEqual(l.entries, expected) {
+		t.Errorf("expected %q, got %q", expected, l.entries)
+	}
+}
+
+func TestAccessLogCapture(t *testing.T) {
+
+	conf := DefaultAccessLogConfig()
+	conf.LogStart = false
+	conf.Capture = &BodyCaptureConfig{
+		MaxSize: 20,
+		Redact: func(body []byte, contentType string) []byte {
+			return bytes.ReplaceAll(body, []byte("secret"), []byte("***"))
+		},
+	}
+	l := &recordingLogger{Logger: logging.NewTesting(t)}
+	handler := logging.AddLogger(l)(AccessLog(conf)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"logged in","token":"abcdef"}`))
+	})))
+
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"pass":"secret"}`))
+	r.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	expected := []string{"DEBUG request: 200 OK", "DEBUG request bodies"}
+	if !reflect.DeepEqual(l.entries, expected) {
+		t.Fatalf("expected %q, got %q", expected, l.entries)
+	}
+	expectedFields := []interface{}{
+		"requestBody", `{"pass":"***"}`,
+		"responseBody", `{"status":"logged in`,
+		"responseBodyTruncated", true,
+	}
+	if fields := l.fields[1]; !reflect.DeepEqual(fields, expectedFields) {
+		t.Errorf("expected %q, got %q", expectedFields, fields)
+	}
+
+	// Other content types are not captured.
+	l.entries = nil
+	r = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("binary"))
+	r.Header.Set("Content-Type", "application/octet-stream")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if len(l.entries) != 2 {
+		t.Errorf("expected only the response body to be captured, got %q", l.entries)
+	}
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// Media types supported by Respond.
+const (
+	MediaTypeJSON  = "application/json"
+	MediaTypeXML   = "application/xml"
+	MediaTypePlain = "text/plain"
+)
+
+// Encoder encodes values in a given format.
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// Streamer is implemented by values that are too large to be buffered. Respond calls Stream with an encoder
+// writing directly to the response, in the negotiated format, and does not set Content-Length.
+type Streamer interface {
+	Stream(enc Encoder) error
+}
+
+// Renderer encodes response values in the format negotiated from the Accept header of the request.
+type Renderer struct {
+	// Indent, if not empty, is used to indent JSON and XML documents.
+	Indent string
+
+	// EscapeHTML escapes the HTML characters in JSON strings.
+	EscapeHTML bool
+
+	// Offers lists the media types the Renderer can produce, in order of preference.
+	// It defaults to JSON, XML and plain text.
+	Offers []string
+}
+
+// DefaultRenderer is the Renderer used by Respond.
+var DefaultRenderer = &Renderer{}
+
+// Respond writes the value with the given status, using DefaultRenderer.
+func Respond(w http.ResponseWriter, r *http.Request, status int, value interface{}) error {
+	return DefaultRenderer.Respond(w, r, status, value)
+}
+
+// Respond writes the value with the given status, encoded in the media type that best matches the Accept header
+// of the request. It falls back to the first offer if none is acceptable. Vary: Accept is set if there are several
+// offers, so caches keep the renderings apart.
+//
+// Values are buffered so Content-Length can be set and encoding errors reported with a 500 Internal Server Error,
+// unless they implement Streamer. Plain text uses the default format of fmt.
+func (rd *Renderer) Respond(w http.ResponseWriter, r *http.Request, status int, value interface{}) (err error) {
+	mediaType := rd.Negotiate(r)
+	h := w.Header()
+	h.Set("Content-Type", mediaType+"; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	if len(rd.offers()) > 1 {
+		addVary(h, "Accept")
+	}
+
+	if s, isStreamer := value.(Streamer); isStreamer {
+		h.Del("Content-Length")
+		w.WriteHeader(status)
+		if r.Method == http.MethodHead {
+			return nil
+		}
+		enc := rd.encoder(mediaType, w)
+		err = s.Stream(enc)
+		if f, isFlusher := enc.(flusher); isFlusher && err == nil {
+			err = f.Flush()
+		}
+		if err != nil {
+			rd.logError(r, err)
+		}
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	enc := rd.encoder(mediaType, buf)
+	err = enc.Encode(value)
+	if f, isFlusher := enc.(flusher); isFlusher && err == nil {
+		err = f.Flush()
+	}
+	if err != nil {
+		rd.logError(r, err)
+		h.Del("Content-Type")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_, err = w.Write(buf.Bytes())
+	}
+	return
+}
+
+// Negotiate returns the offered media type that best matches the Accept header of the request.
+func (rd *Renderer) Negotiate(r *http.Request) string {
+	offers := rd.offers()
+	best, bestQ := offers[0], 0.0
+	for _, accepted := range parseAccept(r.Header.Get("Accept")) {
+		if accepted.q <= bestQ {
+			break
+		}
+		for _, offer := range offers {
+			if accepted.matches(offer) {
+				best, bestQ = offer, accepted.q
+				break
+			}
+		}
+	}
+	return best
+}
+
+func (rd *Renderer) offers() []string {
+	if len(rd.Offers) == 0 {
+		return []string{MediaTypeJSON, MediaTypeXML, MediaTypePlain}
+	}
+	return rd.Offers
+}
+
+// addVary adds the name of a request header to the Vary header, unless it is already listed.
+func addVary(h http.Header, name string) {
+	names, ok := varyNames(h)
+	for _, n := range names {
+		if n == name {
+			return
+		}
+	}
+	if ok {
+		h.Add("Vary", name)
+	}
+}
+
+func (rd *Renderer) encoder(mediaType string, w io.Writer) Encoder {
+	switch mediaType {
+	case MediaTypeXML, "text/xml":
+		enc := xml.NewEncoder(w)
+		enc.Indent("", rd.Indent)
+		return enc
+	case MediaTypePlain:
+		return plainEncoder{w}
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", rd.Indent)
+		enc.SetEscapeHTML(rd.EscapeHTML)
+		return enc
+	}
+}
+
+func (rd *Renderer) logError(r *http.Request, err error) {
+	if logger := logging.FromContext(r.Context(), nil); logger != nil {
+		logger.Errorw("cannot encode response", logging.ErrorKey, err)
+	}
+}
+
+// flusher is implemented by encoders that buffer their output, like xml.Encoder.
+type flusher interface {
+	Flush() error
+}
+
+// plainEncoder writes the values using the default format of fmt, one per line.
+type plainEncoder struct {
+	w io.Writer
+}
+
+func (e plainEncoder) Encode(v interface{}) error {
+	_, err := fmt.Fprintln(e.w, v)
+	return err
+}
+
+type acceptedRange struct {
+	mediaType string
+	q         float64
+}
+
+func (a acceptedRange) matches(mediaType string) bool {
+	if a.mediaType == "*/*" || a.mediaType == mediaType {
+		return true
+	}
+	if strings.HasSuffix(a.mediaType, "/*") {
+		return strings.HasPrefix(mediaType, a.mediaType[:len(a.mediaType)-1])
+	}
+	return false
+}
+
+// parseAccept parses an Accept header, returning the media ranges in decreasing order of quality.
+// An empty header accepts anything.
+func parseAccept(header string) []acceptedRange {
+	if strings.TrimSpace(header) == "" {
+		return []acceptedRange{{"*/*", 1}}
+	}
+	var ranges []acceptedRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		a := acceptedRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		for _, param := range params[1:] {
+			if param = strings.TrimSpace(param); strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					a.q = q
+				}
+			}
+		}
+		if a.mediaType != "" && a.q > 0 {
+			ranges = append(ranges, a)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	return ranges
+}
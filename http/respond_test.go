@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Adirelle/go-libs/cache"
+)
+
+type item struct {
+	ID   int    `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+func (i item) String() string { return i.Name }
+
+type items []item
+
+func (s items) Stream(enc Encoder) error {
+	for _, i := range s {
+		if err := enc.Encode(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRespond(t *testing.T) {
+
+	respond := func(method, accept string, value interface{}) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		if err := Respond(w, r, http.StatusCreated, value); err != nil {
+			t.Errorf("Respond: unexpected error %v", err)
+		}
+		return w
+	}
+
+	for accept, expected := range map[string]string{
+		"": "{\"id\":1,\"name\":\"foo\"}\n",
+		"application/xml, application/json;q=0.5": "<item><id>1</id><name>foo</name></item>",
+		"text/*":    "foo\n",
+		"image/png": "{\"id\":1,\"name\":\"foo\"}\n",
+	} {
+		w := respond(http.MethodGet, accept, item{1, "foo"})
+		if w.Code != http.StatusCreated || w.Body.String() != expected || w.Header().Get("Content-Length") == "" {
+			t.Errorf("Accept %q: expected 201 %q, got %d %q", accept, expected, w.Code, w.Body.String())
+		}
+	}
+
+	if w := respond(http.MethodHead, "", item{1, "foo"}); w.Body.Len() != 0 || w.Header().Get("Content-Length") != "22" {
+		t.Errorf("HEAD: expected no body and Content-Length: 22, got %q %q", w.Body.String(), w.Header().Get("Content-Length"))
+	}
+
+	w := respond(http.MethodGet, "text/plain", items{{1, "foo"}, {2, "bar"}})
+	if w.Body.String() != "foo\nbar\n" || w.Header().Get("Content-Length") != "" {
+		t.Errorf("Streamer: expected foo bar without Content-Length, got %q %q", w.Body.String(), w.Header().Get("Content-Length"))
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	if err := Respond(w, r, http.StatusOK, func() {}); err == nil || w.Code != http.StatusInternalServerError {
+		t.Errorf("encoding error: expected 500 and an error, got %d, %v", w.Code, err)
+	}
+}
+
+func TestRespondVary(t *testing.T) {
+
+	handler := func(rd *Renderer) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rd.Respond(w, r, http.StatusOK, item{1, "foo"})
+		})
+	}
+	get := func(h http.Handler, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get(handler(&Renderer{Offers: []string{MediaTypeJSON}}), ""); w.Header().Get("Vary") != "" {
+		t.Errorf("single offer: expected no Vary header, got %q", w.Header().Get("Vary"))
+	}
+
+	cached := ResponseCache(ResponseCacheConfig{Cache: cache.NewMemoryStorage()})(handler(&Renderer{}))
+	if w := get(cached, MediaTypeJSON); w.Header().Get("Vary") != "Accept" {
+		t.Errorf("several offers: expected Vary: Accept, got %q", w.Header().Get("Vary"))
+	}
+	if w := get(cached, MediaTypeXML); w.Body.String() != "<item><id>1</id><name>foo</name></item>" {
+		t.Errorf("cached: expected the XML rendering, got %q", w.Body.String())
+	}
+}
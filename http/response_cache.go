@@ -0,0 +1,409 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/Adirelle/go-libs/cache"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// DefaultMaxCachedBodySize is the default maximum size of cached response bodies.
+const DefaultMaxCachedBodySize = 1 << 20
+
+// cacheStatusHeader tells whether a response has been served from the cache.
+const cacheStatusHeader = "X-Cache"
+
+// CachedResponse is a response, as stored in the cache.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	// Vary lists the request headers the response depends on. A response with only Vary set
+	// is a placeholder telling which headers select the actual response.
+	Vary []string
+
+	// Generation is mixed into the keys of the variants of a placeholder, so removing the placeholder abandons all
+	// of them at once.
+	Generation uint64
+}
+
+func init() {
+	gob.Register(&CachedResponse{})
+}
+
+// ResponseCacheConfig configures the ResponseCache middleware.
+type ResponseCacheConfig struct {
+	// Cache stores the responses. Use the Expiration option to set their lifetime.
+	Cache cache.Cache
+
+	// MaxBodySize is the size above which responses are not cached. It defaults to DefaultMaxCachedBodySize.
+	MaxBodySize int
+
+	// TTL is the lifetime of the responses without a max-age directive, if the cache supports it.
+	// Zero leaves it to the cache.
+	TTL time.Duration
+
+	// Key returns the key of the response to a request. It defaults to the method followed by the host and the URL.
+	Key func(r *http.Request) string
+
+	// Invalidate returns the keys of the responses to remove after a request using another method than GET or HEAD.
+	// It defaults to the keys of the GET and HEAD requests of the same URL.
+	Invalidate func(r *http.Request) []string
+}
+
+// CacheResponses returns a middleware that caches the responses of GET and HEAD requests, keyed by keyFunc,
+// with the given lifetime. A nil keyFunc uses the default key of ResponseCache, which includes the host.
+// See ResponseCache.
+func CacheResponses(c cache.Cache, ttl time.Duration, keyFunc func(r *http.Request) string) func(http.Handler) http.Handler {
+	return ResponseCache(ResponseCacheConfig{Cache: c, TTL: ttl, Key: keyFunc})
+}
+
+// ResponseCache returns a middleware that caches the responses of GET and HEAD requests.
+//
+// The responses are keyed by the Key function, and by the request headers listed in their Vary header.
+// The Cache-Control directives of requests (no-cache, no-store) and responses (no-cache, no-store, private, max-age)
+// are honored, as well as the presence of Set-Cookie. The max-age directive is used as TTL if the cache supports it.
+// As required from shared caches, the requests with an Authorization header are only served from, and stored in,
+// the cache if the response has a public, s-maxage or must-revalidate directive.
+// Concurrent requests of the same uncached response are served by a single call to the next handler.
+// The X-Cache header of the responses tells whether they have been served from the cache (HIT) or not (MISS).
+//
+// The requests using other methods are passed to the next handler, then the responses they may have changed are
+// removed from the cache, whatever the outcome of the request. Removing a response which varies on request headers
+// abandons all its variants.
+func ResponseCache(conf ResponseCacheConfig) func(http.Handler) http.Handler {
+	if conf.MaxBodySize <= 0 {
+		conf.MaxBodySize = DefaultMaxCachedBodySize
+	}
+	if conf.Key == nil {
+		conf.Key = func(r *http.Request) string {
+			return r.Method + " " + r.Host + r.URL.RequestURI()
+		}
+	}
+	rc := &responseCache{conf: conf}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc.serve(next, w, r)
+		})
+	}
+}
+
+type responseCache struct {
+	conf  ResponseCacheConfig
+	group singleflight.Group
+}
+
+// flight is the result of the next handler, shared with the concurrent requests.
+type flight struct {
+	resp *CachedResponse
+	r    *http.Request
+}
+
+func (rc *responseCache) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		next.ServeHTTP(w, r)
+		rc.invalidate(r)
+		return
+	}
+	reqCC := parseCacheControl(r.Header.Get("Cache-Control"))
+	if _, noStore := reqCC["no-store"]; noStore {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	key := rc.conf.Key(r)
+	flightKey := key
+	if _, noCache := reqCC["no-cache"]; !noCache {
+		var resp *CachedResponse
+		if resp, flightKey = rc.lookup(key, r); resp != nil && resp.usableFor(r) {
+			w.Header().Set(cacheStatusHeader, "HIT")
+			resp.writeTo(w)
+			return
+		}
+	}
+
+	leader := false
+	value, _, _ := rc.group.Do(flightKey, func() (interface{}, error) {
+		leader = true
+		w.Header().Set(cacheStatusHeader, "MISS")
+		rec := &responseRecorder{writerBase: writerBase{w}, maxSize: rc.conf.MaxBodySize, outer: w.Header().Clone()}
+		next.ServeHTTP(wrapWriter(rec), r)
+		resp := rec.response()
+		if resp != nil && resp.usableFor(r) {
+			rc.store(key, r, resp)
+		} else {
+			resp = nil
+		}
+		return &flight{resp, r}, nil
+	})
+	if leader {
+		return
+	}
+	// The variants of an uncached response are not known beforehand: check the request matches the leader's one.
+	if f := value.(*flight); f.resp != nil && f.resp.usableFor(r) && f.resp.sameVariant(f.r, r) {
+		w.Header().Set(cacheStatusHeader, "HIT")
+		f.resp.writeTo(w)
+	} else {
+		w.Header().Set(cacheStatusHeader, "MISS")
+		next.ServeHTTP(w, r)
+	}
+}
+
+// invalidate removes the responses which may have been changed by the request.
+func (rc *responseCache) invalidate(r *http.Request) {
+	var keys []string
+	if rc.conf.Invalidate != nil {
+		keys = rc.conf.Invalidate(r)
+	} else {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			read := r.WithContext(r.Context())
+			read.Method = method
+			keys = append(keys, rc.conf.Key(read))
+		}
+	}
+	for _, key := range keys {
+		rc.conf.Cache.Remove(key)
+	}
+}
+
+// lookup returns the cached response to the request, if any, and the key of its variant, if known.
+func (rc *responseCache) lookup(key string, r *http.Request) (*CachedResponse, string) {
+	for i := 0; i < 2; i++ {
+		value, err := rc.conf.Cache.Get(key)
+		if err != nil {
+			if logger := logging.FromContext(r.Context(), nil); logger != nil && err != cache.ErrKeyNotFound {
+				logger.Warnw("cannot fetch cached response", logging.ErrorKey, err)
+			}
+			return nil, key
+		}
+		resp, ok := value.(*CachedResponse)
+		if !ok {
+			return nil, key
+		}
+		if resp.Vary == nil || i > 0 {
+			return resp, key
+		}
+		key = resp.variantKey(key, r)
+	}
+	return nil, key
+}
+
+func (rc *responseCache) store(key string, r *http.Request, resp *CachedResponse) {
+	if names, ok := varyNames(resp.Header); !ok {
+		return
+	} else if len(names) > 0 {
+		placeholder := rc.placeholder(key, names)
+		rc.put(key, placeholder, resp)
+		key = placeholder.variantKey(key, r)
+	}
+	rc.put(key, resp, resp)
+}
+
+// placeholder returns the stored placeholder of the key if it has the same Vary list, so the other variants remain
+// reachable, or a new one, with a new generation.
+func (rc *responseCache) placeholder(key string, vary []string) *CachedResponse {
+	if value, err := rc.conf.Cache.Get(key); err == nil {
+		if p, ok := value.(*CachedResponse); ok && strings.Join(p.Vary, ",") == strings.Join(vary, ",") {
+			return p
+		}
+	}
+	return &CachedResponse{Vary: vary, Generation: rand.Uint64()}
+}
+
+func (rc *responseCache) put(key string, value, resp *CachedResponse) {
+	putResponse(rc.conf.Cache, key, value, resp, rc.conf.TTL)
+}
+
+// putResponse stores the value in the cache, using the max-age directive of the response as TTL if possible,
+// else the default TTL, if not zero.
+func putResponse(c cache.Cache, key string, value, resp *CachedResponse, ttl time.Duration) error {
+	cc := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if maxAge, found := cc["max-age"]; found {
+		if seconds, err := strconv.Atoi(maxAge); err == nil {
+			ttl = time.Duration(seconds) * time.Second
+		}
+	}
+	if ttl != 0 {
+		return cache.PutWithTTL(c, key, value, ttl)
+	}
+	return c.Put(key, value)
+}
+
+// varyNames returns the names of the request headers listed in the Vary header, or false if it contains "*".
+func varyNames(h http.Header) (names []string, ok bool) {
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name == "*" {
+				return nil, false
+			} else if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names, true
+}
+
+// usableFor tells whether the response can be stored for, or served to, the request. A shared cache must not use
+// the response to a request with an Authorization header, unless it is explicitly allowed to (RFC 7234, section 3.2).
+func (c *CachedResponse) usableFor(r *http.Request) bool {
+	if r.Header.Get("Authorization") == "" {
+		return true
+	}
+	cc := parseCacheControl(c.Header.Get("Cache-Control"))
+	for _, directive := range []string{"public", "s-maxage", "must-revalidate"} {
+		if _, found := cc[directive]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// sameVariant tells whether the response to r1 can be used for r2.
+func (c *CachedResponse) sameVariant(r1, r2 *http.Request) bool {
+	names, ok := varyNames(c.Header)
+	for _, name := range names {
+		if strings.Join(r1.Header.Values(name), ",") != strings.Join(r2.Header.Values(name), ",") {
+			return false
+		}
+	}
+	return ok
+}
+
+// variantKey returns the key of the variant of a placeholder matching the request.
+func (c *CachedResponse) variantKey(key string, r *http.Request) string {
+	b := &strings.Builder{}
+	b.WriteString(key)
+	b.WriteString("\x00")
+	b.WriteString(strconv.FormatUint(c.Generation, 16))
+	for _, name := range c.Vary {
+		b.WriteString("\x00")
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+func parseCacheControl(value string) map[string]string {
+	cc := make(map[string]string)
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+		name, arg := directive, ""
+		if eq := strings.IndexByte(directive, '='); eq >= 0 {
+			name, arg = directive[:eq], strings.Trim(directive[eq+1:], `"`)
+		}
+		cc[strings.ToLower(name)] = arg
+	}
+	return cc
+}
+
+func (c *CachedResponse) writeTo(w http.ResponseWriter) {
+	h := w.Header()
+	for k, v := range c.Header {
+		h[k] = append([]string(nil), v...)
+	}
+	w.WriteHeader(c.Status)
+	w.Write(c.Body)
+}
+
+// cacheableStatus lists the status codes of cacheable responses.
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+// cacheableResponse tells whether a response can be stored in a shared cache.
+func cacheableResponse(status int, h http.Header) bool {
+	if !cacheableStatus[status] || h.Get("Set-Cookie") != "" {
+		return false
+	}
+	cc := parseCacheControl(h.Get("Cache-Control"))
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, found := cc[directive]; found {
+			return false
+		}
+	}
+	maxAge, found := cc["max-age"]
+	return !found || maxAge != "0"
+}
+
+// responseRecorder writes the response and records it, up to maxSize bytes of body.
+type responseRecorder struct {
+	writerBase
+	status   int
+	body     bytes.Buffer
+	maxSize  int
+	overflow bool
+	// outer holds the headers set before calling the handler, e.g. by other middlewares, which are not recorded.
+	outer http.Header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+		rec.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	if !rec.overflow {
+		if rec.body.Len()+len(b) > rec.maxSize {
+			rec.overflow = true
+			rec.body.Reset()
+		} else {
+			rec.body.Write(b)
+		}
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *responseRecorder) Flush() {
+	rec.WriteHeader(http.StatusOK)
+	rec.writerBase.Flush()
+}
+
+// Hijack prevents caching the response, as the connection is taken over.
+func (rec *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	rec.overflow = true
+	return rec.writerBase.Hijack()
+}
+
+func (rec *responseRecorder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(writerOnly{rec}, r)
+}
+
+// response returns the recorded response if it can be cached, or nil.
+func (rec *responseRecorder) response() *CachedResponse {
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	h := make(http.Header)
+	for name, values := range rec.Header() {
+		if strings.Join(values, "\x00") != strings.Join(rec.outer[name], "\x00") {
+			h[name] = append([]string(nil), values...)
+		}
+	}
+	if rec.overflow || !cacheableResponse(status, h) {
+		return nil
+	}
+	h.Del(cacheStatusHeader)
+	return &CachedResponse{Status: status, Header: h, Body: append([]byte(nil), rec.body.Bytes()...)}
+}
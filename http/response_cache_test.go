@@ -0,0 +1,286 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Adirelle/go-libs/cache"
+)
+
+func TestResponseCache(t *testing.T) {
+
+	calls := 0
+	handler := ResponseCache(ResponseCacheConfig{Cache: cache.NewMemoryStorage()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.URL.Path == "/private" {
+				w.Header().Set("Cache-Control", "private")
+			}
+			w.Header().Set("Vary", "Accept-Language")
+			w.Write([]byte(r.URL.Path + " " + r.Header.Get("Accept-Language")))
+		}))
+
+	get := func(path, lang, cacheControl string) string {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Language", lang)
+		if cacheControl != "" {
+			r.Header.Set("Cache-Control", cacheControl)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+
+	cases := []struct {
+		path, lang, cacheControl string
+		expectedCalls            int
+	}{
+		{"/page", "en", "", 1},
+		{"/page", "en", "", 1},
+		{"/page", "fr", "", 2},
+		{"/page", "fr", "", 2},
+		{"/page", "en", "no-cache", 3},
+		{"/page", "en", "no-store", 4},
+		{"/private", "en", "", 5},
+		{"/private", "en", "", 6},
+	}
+	for i, c := range cases {
+		if body := get(c.path, c.lang, c.cacheControl); body != c.path+" "+c.lang {
+			t.Errorf("request %d: expected %q, got %q", i, c.path+" "+c.lang, body)
+		}
+		if calls != c.expectedCalls {
+			t.Errorf("request %d: expected %d handler calls, got %d", i, c.expectedCalls, calls)
+		}
+	}
+}
+
+func TestCacheResponses(t *testing.T) {
+
+	version := "v1"
+	c := cache.NewMemoryStorage(cache.Expiration(time.Hour))
+	handler := CacheResponses(c, time.Minute, func(r *http.Request) string { return r.Method + " " + r.URL.Path })(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				w.Write([]byte(version))
+			}
+		}))
+
+	get := func(url string) (string, string) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w.Body.String(), w.Header().Get(cacheStatusHeader)
+	}
+
+	if body, status := get("/page"); body != "v1" || status != "MISS" {
+		t.Errorf("first GET: expected v1 MISS, got %s %s", body, status)
+	}
+	if body, status := get("/page?utm=x"); body != "v1" || status != "HIT" {
+		t.Errorf("second GET: expected v1 HIT, got %s %s", body, status)
+	}
+	if ttl, err := cache.TTL(c, "GET /page"); err != nil || ttl > time.Minute {
+		t.Errorf("TTL: expected at most 1m0s, got %s, %v", ttl, err)
+	}
+
+	version = "v2"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/page", nil))
+	if status := w.Header().Get(cacheStatusHeader); status != "" {
+		t.Errorf("POST: expected no %s header, got %s", cacheStatusHeader, status)
+	}
+
+	if body, status := get("/page"); body != "v2" || status != "MISS" {
+		t.Errorf("GET after POST: expected v2 MISS, got %s %s", body, status)
+	}
+}
+
+func TestResponseCacheVaryInvalidation(t *testing.T) {
+
+	version := "v1"
+	handler := ResponseCache(ResponseCacheConfig{Cache: cache.NewMemoryStorage()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				w.Header().Set("Vary", "Accept-Language")
+				w.Write([]byte(version + " " + r.Header.Get("Accept-Language")))
+			}
+		}))
+
+	get := func(lang string) (string, string) {
+		r := httptest.NewRequest(http.MethodGet, "/page", nil)
+		r.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Body.String(), w.Header().Get(cacheStatusHeader)
+	}
+
+	for _, lang := range []string{"en", "fr"} {
+		get(lang)
+		if body, status := get(lang); body != "v1 "+lang || status != "HIT" {
+			t.Errorf("GET %s: expected %q from cache, got %q, %s", lang, "v1 "+lang, body, status)
+		}
+	}
+
+	version = "v2"
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/page", nil))
+
+	for _, lang := range []string{"en", "fr"} {
+		if body, status := get(lang); body != "v2 "+lang || status != "MISS" {
+			t.Errorf("GET %s: expected %q after invalidation, got %q, %s", lang, "v2 "+lang, body, status)
+		}
+	}
+}
+
+func TestResponseCacheConcurrentVariants(t *testing.T) {
+
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	handler := ResponseCache(ResponseCacheConfig{Cache: cache.NewMemoryStorage()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			w.Header().Set("Vary", "Accept-Language")
+			w.Write([]byte(r.Header.Get("Accept-Language")))
+		}))
+
+	get := func(lang string, done chan<- string) {
+		r := httptest.NewRequest(http.MethodGet, "/page", nil)
+		r.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		done <- w.Body.String()
+	}
+
+	en, fr := make(chan string), make(chan string)
+	go get("en", en)
+	<-started
+	go get("fr", fr)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	if body := <-en; body != "en" {
+		t.Errorf("GET en: expected %q, got %q", "en", body)
+	}
+	if body := <-fr; body != "fr" {
+		t.Errorf("GET fr: expected %q, got %q", "fr", body)
+	}
+}
+
+func TestResponseCacheOuterHeaders(t *testing.T) {
+
+	id := 0
+	cached := ResponseCache(ResponseCacheConfig{Cache: cache.NewMemoryStorage()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("hello"))
+		}))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id++
+		w.Header().Set("X-Request-Id", strconv.Itoa(id))
+		cached.ServeHTTP(w, r)
+	})
+
+	for i := 1; i <= 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if v := w.Header().Get("X-Request-Id"); v != strconv.Itoa(i) {
+			t.Errorf("X-Request-Id: expected %d, got %s", i, v)
+		}
+		if v := w.Header().Get("Content-Type"); v != "text/plain" {
+			t.Errorf("Content-Type: expected text/plain, got %s", v)
+		}
+	}
+}
+
+func TestResponseCacheAuthorization(t *testing.T) {
+
+	calls := 0
+	handler := ResponseCache(ResponseCacheConfig{Cache: cache.NewMemoryStorage()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.URL.Path == "/public" {
+				w.Header().Set("Cache-Control", "public, max-age=60")
+			}
+			w.Write([]byte("for " + r.Header.Get("Authorization")))
+		}))
+
+	get := func(path, auth string) string {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+
+	get("/private", "alice")
+	if body := get("/private", "bob"); body != "for bob" || calls != 2 {
+		t.Errorf("authorized GET: expected %q from the handler, got %q after %d calls", "for bob", body, calls)
+	}
+	get("/private", "")
+	if body := get("/private", "bob"); body != "for bob" || calls != 4 {
+		t.Errorf("authorized GET after anonymous one: expected %q from the handler, got %q after %d calls", "for bob", body, calls)
+	}
+	if body := get("/private", ""); body != "for " || calls != 4 {
+		t.Errorf("anonymous GET: expected %q from the cache, got %q after %d calls", "for ", body, calls)
+	}
+
+	calls = 0
+	get("/public", "alice")
+	if body := get("/public", "bob"); body != "for alice" || calls != 1 {
+		t.Errorf("public GET: expected %q from the cache, got %q after %d calls", "for alice", body, calls)
+	}
+}
+
+func TestResponseCacheHost(t *testing.T) {
+
+	handler := ResponseCache(ResponseCacheConfig{Cache: cache.NewMemoryStorage()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Host))
+		}))
+
+	for i := 0; i < 2; i++ {
+		for _, host := range []string{"a.example.com", "b.example.com"} {
+			r := httptest.NewRequest(http.MethodGet, "/page", nil)
+			r.Host = host
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if body := w.Body.String(); body != host {
+				t.Errorf("GET %s: expected %q, got %q", host, host, body)
+			}
+		}
+	}
+}
+
+func TestCacheResponsesSharing(t *testing.T) {
+
+	calls := 0
+	handler := CacheResponses(cache.NewMemoryStorage(), time.Minute, nil)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Write([]byte(r.Host + " " + r.Header.Get("Authorization")))
+		}))
+
+	get := func(host, auth string) (string, string) {
+		r := httptest.NewRequest(http.MethodGet, "/page", nil)
+		r.Host = host
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Body.String(), w.Header().Get(cacheStatusHeader)
+	}
+
+	get("a.example.com", "")
+	if body, status := get("b.example.com", ""); body != "b.example.com " || status != "MISS" {
+		t.Errorf("other host: expected %q MISS, got %q %s", "b.example.com ", body, status)
+	}
+	if body, status := get("a.example.com", "alice"); body != "a.example.com alice" || status != "MISS" {
+		t.Errorf("authorized: expected %q MISS, got %q %s", "a.example.com alice", body, status)
+	}
+	if body, status := get("a.example.com", ""); body != "a.example.com " || status != "HIT" || calls != 3 {
+		t.Errorf("anonymous: expected %q HIT, got %q %s after %d calls", "a.example.com ", body, status, calls)
+	}
+}
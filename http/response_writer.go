@@ -0,0 +1,205 @@
+package http
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// writerWrapper is implemented by the ResponseWriter wrappers of the middlewares.
+type writerWrapper interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	http.Pusher
+	io.ReaderFrom
+	Unwrap() http.ResponseWriter
+}
+
+type unwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// wrapWriter returns a ResponseWriter that only exposes the optional interfaces (http.Flusher, http.Hijacker,
+// http.Pusher and io.ReaderFrom) that are implemented by the ResponseWriter the wrapper wraps, so the handlers
+// can detect the actual features, e.g. to upgrade to WebSocket or use sendfile.
+func wrapWriter(w writerWrapper) http.ResponseWriter {
+	inner := w.Unwrap()
+	_, isFlusher := inner.(http.Flusher)
+	_, isHijacker := inner.(http.Hijacker)
+	_, isPusher := inner.(http.Pusher)
+	_, isReaderFrom := inner.(io.ReaderFrom)
+
+	var features int
+	if isFlusher {
+		features |= 1
+	}
+	if isHijacker {
+		features |= 2
+	}
+	if isPusher {
+		features |= 4
+	}
+	if isReaderFrom {
+		features |= 8
+	}
+
+	switch features {
+	case 0:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+		}{w, w}
+	case 1:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+		}{w, w, w}
+	case 2:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+		}{w, w, w}
+	case 3:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+		}{w, w, w, w}
+	case 4:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Pusher
+		}{w, w, w}
+	case 5:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Pusher
+		}{w, w, w, w}
+	case 6:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+			http.Pusher
+		}{w, w, w, w}
+	case 7:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{w, w, w, w, w}
+	case 8:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			io.ReaderFrom
+		}{w, w, w}
+	case 9:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			io.ReaderFrom
+		}{w, w, w, w}
+	case 10:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+			io.ReaderFrom
+		}{w, w, w, w}
+	case 11:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{w, w, w, w, w}
+	case 12:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w}
+	case 13:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w, w}
+	case 14:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w, w}
+	default:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w, w, w}
+	}
+}
+
+// writerBase implements the optional interfaces of writerWrapper by delegating to the wrapped ResponseWriter.
+// Wrappers embed it and override the methods that must go through their own logic.
+type writerBase struct {
+	http.ResponseWriter
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (b writerBase) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
+
+func (b writerBase) Flush() {
+	if f, isFlusher := b.ResponseWriter.(http.Flusher); isFlusher {
+		f.Flush()
+	}
+}
+
+func (b writerBase) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, isHijacker := b.ResponseWriter.(http.Hijacker); isHijacker {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+func (b writerBase) Push(target string, opts *http.PushOptions) error {
+	if p, isPusher := b.ResponseWriter.(http.Pusher); isPusher {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (b writerBase) ReadFrom(r io.Reader) (int64, error) {
+	if rf, isReaderFrom := b.ResponseWriter.(io.ReaderFrom); isReaderFrom {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(writerOnly{b.ResponseWriter}, r)
+}
+
+// writerOnly hides the optional interfaces of a writer, so io.Copy does not call ReadFrom recursively.
+type writerOnly struct {
+	io.Writer
+}
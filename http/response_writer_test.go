@@ -0,0 +1,87 @@
+package http
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// fullWriter implements all the optional ResponseWriter interfaces.
+type fullWriter struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *fullWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func (w *fullWriter) Push(string, *http.PushOptions) error {
+	return nil
+}
+
+func (w *fullWriter) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(w.ResponseRecorder, r)
+}
+
+func TestWrapWriter(t *testing.T) {
+
+	check := func(name string, w http.ResponseWriter, expected [4]bool) {
+		_, isFlusher := w.(http.Flusher)
+		_, isHijacker := w.(http.Hijacker)
+		_, isPusher := w.(http.Pusher)
+		_, isReaderFrom := w.(io.ReaderFrom)
+		if actual := [4]bool{isFlusher, isHijacker, isPusher, isReaderFrom}; actual != expected {
+			t.Errorf("%s: expected Flusher, Hijacker, Pusher, ReaderFrom to be %v, got %v", name, expected, actual)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	check("recorder", wrapWriter(&statusWriter{writerBase: writerBase{rec}}), [4]bool{true, false, false, false})
+
+	full := &fullWriter{ResponseRecorder: httptest.NewRecorder()}
+	sw := &statusWriter{writerBase: writerBase{full}}
+	w := wrapWriter(sw)
+	check("full", w, [4]bool{true, true, true, true})
+
+	if u, ok := w.(interface{ Unwrap() http.ResponseWriter }); !ok || u.Unwrap() != full {
+		t.Errorf("Unwrap: expected the wrapped ResponseWriter")
+	}
+	if err := http.NewResponseController(w).Flush(); err != nil || !full.Flushed {
+		t.Errorf("ResponseController.Flush: expected the wrapped writer to be flushed, got %v", err)
+	}
+
+	sw = &statusWriter{writerBase: writerBase{full}}
+	wrapWriter(sw).(http.Hijacker).Hijack()
+	if !full.hijacked || sw.Status() != http.StatusSwitchingProtocols {
+		t.Errorf("Hijack: expected a hijacked connection with status 101, got %d", sw.Status())
+	}
+}
+
+func TestWrappedWebSocket(t *testing.T) {
+
+	hub := NewWebSocketHub(WebSocketConfig{})
+	defer hub.Shutdown()
+	handler := NewChain(logging.AddLogger(logging.NewTesting(t)), DebugRequest, ETag(ETagConfig{})).Then(
+		hub.Handler(func(c *WebSocketConn, messageType int, data []byte) {
+			c.Send(messageType, data)
+		}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// The upgrade requires http.Hijacker, through the wrappers of the middlewares.
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("cannot upgrade through the middlewares: %v", err)
+	}
+	ws.Close()
+}
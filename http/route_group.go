@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteGroup registers routes on a mux.Router, wrapping their handlers with a middleware Chain.
+// Nested groups inherit the middlewares of their parent, so they can be attached per route or per subtree:
+//
+//	root := NewRouteGroup(router, UniqueID, DebugRequest)
+//	root.Handle(HealthPath, health)
+//	api := root.PathPrefix("/api", JWTAuth(conf))
+//	api.HandleFunc("/items", listItems).Methods("GET")
+//	api.HandleFunc("/items", createItem, CSRF(csrfConf)).Methods("POST")
+//
+// Unlike mux.Router.Use, the middlewares only run for the requests matching the routes of the group.
+type RouteGroup struct {
+	router *mux.Router
+	chain  Chain
+}
+
+// NewRouteGroup creates a RouteGroup registering routes on the router.
+func NewRouteGroup(router *mux.Router, middlewares ...Middleware) *RouteGroup {
+	return &RouteGroup{router, NewChain(middlewares...)}
+}
+
+// Router returns the router the routes are registered on.
+func (g *RouteGroup) Router() *mux.Router {
+	return g.router
+}
+
+// Chain returns the middlewares of the group.
+func (g *RouteGroup) Chain() Chain {
+	return g.chain
+}
+
+// Use adds middlewares to the group. They only apply to the routes registered afterward.
+func (g *RouteGroup) Use(middlewares ...Middleware) {
+	g.chain = g.chain.Use(middlewares...)
+}
+
+// Group returns a child group sharing the same router, with additional middlewares.
+func (g *RouteGroup) Group(middlewares ...Middleware) *RouteGroup {
+	return &RouteGroup{g.router, g.chain.Use(middlewares...)}
+}
+
+// PathPrefix returns a child group registering its routes in a subrouter matching the prefix,
+// with additional middlewares.
+func (g *RouteGroup) PathPrefix(prefix string, middlewares ...Middleware) *RouteGroup {
+	return &RouteGroup{g.router.PathPrefix(prefix).Subrouter(), g.chain.Use(middlewares...)}
+}
+
+// Host returns a child group registering its routes in a subrouter matching the host,
+// with additional middlewares.
+func (g *RouteGroup) Host(host string, middlewares ...Middleware) *RouteGroup {
+	return &RouteGroup{g.router.Host(host).Subrouter(), g.chain.Use(middlewares...)}
+}
+
+// Handle registers a route for the path, with the middlewares of the group followed by the given ones.
+func (g *RouteGroup) Handle(path string, h http.Handler, middlewares ...Middleware) *mux.Route {
+	return g.router.Handle(path, g.chain.Use(middlewares...).Then(h))
+}
+
+// HandleFunc registers a route for the path, with the middlewares of the group followed by the given ones.
+func (g *RouteGroup) HandleFunc(path string, f http.HandlerFunc, middlewares ...Middleware) *mux.Route {
+	return g.Handle(path, f, middlewares...)
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRouteGroup(t *testing.T) {
+
+	tag := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middlewares", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	router := mux.NewRouter()
+	root := NewRouteGroup(router, tag("root"))
+	root.HandleFunc("/health", ok)
+	api := root.PathPrefix("/api", tag("api"))
+	api.HandleFunc("/items", ok, tag("items")).Methods(http.MethodPost)
+	api.Use(tag("late"))
+	api.HandleFunc("/users", ok)
+
+	for path, expected := range map[string]string{
+		"/health":    "root",
+		"/api/items": "root,api,items",
+		"/api/users": "root,api,late",
+		"/unknown":   "",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if actual := strings.Join(w.Header()["X-Middlewares"], ","); actual != expected {
+			t.Errorf("%s: expected middlewares %q, got %q", path, expected, actual)
+		}
+	}
+}
@@ -2,60 +2,220 @@ package http
 
 import (
 	"fmt"
+	"html/template"
 	"net/http"
 	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
 
 	"go.uber.org/zap/buffer"
 
 	"github.com/gorilla/mux"
 )
 
+// RouterDebug lists the routes of the router, as plain text, JSON or an HTML table depending on the Accept header.
+//
+// The routes can be filtered with the "path" query parameter, which must be contained in the path template,
+// and the "method" query parameter, which must be accepted by the route.
 type RouterDebug struct{ *mux.Router }
 
-type dumper struct {
-	*buffer.Buffer
+// RouteInfo describes a route.
+type RouteInfo struct {
+	Name         string   `json:"name,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	HostTemplate string   `json:"hostTemplate,omitempty"`
+	Methods      []string `json:"methods,omitempty"`
+	PathTemplate string   `json:"pathTemplate,omitempty"`
+	PathRegexp   string   `json:"pathRegexp,omitempty"`
+	Queries      []string `json:"queries,omitempty"`
+	Middlewares  []string `json:"middlewares,omitempty"`
+	Handler      string   `json:"handler,omitempty"`
 }
 
+var routerDebugRenderer = &Renderer{Indent: "  ", Offers: []string{MediaTypePlain, MediaTypeJSON, "text/html"}}
+
 var bufferPool = buffer.NewPool()
 
-func (d *RouterDebug) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
-	b := bufferPool.Get()
-	defer b.Free()
-	err := d.Walk(dumper{b}.dumpRoute)
+func (d *RouterDebug) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	routes, err := d.Routes()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", `text/plain; encoding="utf-8"`)
-	w.Write(b.Bytes())
+	routes = filterRoutes(routes, r.URL.Query().Get("path"), r.URL.Query().Get("method"))
+
+	switch routerDebugRenderer.Negotiate(r) {
+	case MediaTypeJSON:
+		routerDebugRenderer.Respond(w, r, http.StatusOK, routes)
+	case "text/html":
+		b := bufferPool.Get()
+		defer b.Free()
+		if err := routeTableTemplate.Execute(b, routes); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(b.Bytes())
+	default:
+		b := bufferPool.Get()
+		defer b.Free()
+		for _, route := range routes {
+			dumper{b}.dumpRoute(route)
+		}
+		w.Header().Set("Content-Type", `text/plain; encoding="utf-8"`)
+		w.Write(b.Bytes())
+	}
+}
+
+// Routes describes all the routes of the router.
+func (d *RouterDebug) Routes() (routes []RouteInfo, err error) {
+	subrouters := make(map[*mux.Route]*mux.Router)
+	err = d.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		info := RouteInfo{
+			Name:        route.GetName(),
+			Middlewares: middlewareNames(d.Router),
+			Handler:     handlerName(route.GetHandler()),
+		}
+		if err := route.GetError(); err != nil {
+			info.Error = err.Error()
+		}
+		info.HostTemplate, _ = route.GetHostTemplate()
+		info.Methods, _ = route.GetMethods()
+		info.PathTemplate, _ = route.GetPathTemplate()
+		info.PathRegexp, _ = route.GetPathRegexp()
+		info.Queries, _ = route.GetQueriesTemplates()
+		if n := len(ancestors); n > 0 {
+			// The routes of a subrouter are walked right after the route it matches.
+			subrouters[ancestors[n-1]] = router
+		}
+		for _, ancestor := range ancestors {
+			info.Middlewares = append(info.Middlewares, middlewareNames(subrouters[ancestor])...)
+		}
+		routes = append(routes, info)
+		return nil
+	})
+	return
+}
+
+func filterRoutes(routes []RouteInfo, path, method string) []RouteInfo {
+	if path == "" && method == "" {
+		return routes
+	}
+	filtered := make([]RouteInfo, 0, len(routes))
+	for _, route := range routes {
+		if path != "" && !strings.Contains(route.PathTemplate, path) {
+			continue
+		}
+		if method != "" && len(route.Methods) > 0 && !containsFold(route.Methods, method) {
+			continue
+		}
+		filtered = append(filtered, route)
+	}
+	return filtered
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
 }
 
-func (d dumper) dumpRoute(r *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+// middlewareNames returns the names of the middlewares of the router.
+// mux does not expose them, so they are read by reflection; nothing is returned if that fails.
+func middlewareNames(router *mux.Router) (names []string) {
+	if router == nil {
+		return
+	}
+	field := reflect.ValueOf(router).Elem().FieldByName("middlewares")
+	if field.Kind() != reflect.Slice {
+		return
+	}
+	for i := 0; i < field.Len(); i++ {
+		mw := field.Index(i)
+		if mw.Kind() == reflect.Interface {
+			mw = mw.Elem()
+		}
+		if mw.Kind() == reflect.Func {
+			names = append(names, funcName(mw.Pointer()))
+		} else {
+			names = append(names, mw.Type().String())
+		}
+	}
+	return
+}
+
+// handlerName returns the function name of a http.HandlerFunc, or the type of other handlers.
+func handlerName(h http.Handler) string {
+	if h == nil {
+		return ""
+	}
+	v := reflect.ValueOf(h)
+	if v.Kind() == reflect.Func {
+		return funcName(v.Pointer())
+	}
+	return v.Type().String()
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// funcName returns the name of the function, without the suffixes of closures.
+func funcName(pc uintptr) string {
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return fmt.Sprintf("func@%#x", pc)
+	}
+	return closureSuffix.ReplaceAllString(f.Name(), "")
+}
+
+type dumper struct {
+	*buffer.Buffer
+}
+
+func (d dumper) dumpRoute(r RouteInfo) {
 	fmt.Fprintln(d, "-")
-	if name := r.GetName(); name != "" {
-		fmt.Fprintf(d, "\tname: %s\n", r.GetName())
+	if r.Name != "" {
+		fmt.Fprintf(d, "\tname: %s\n", r.Name)
 	}
-	if err := r.GetError(); err != nil {
-		fmt.Fprintf(d, "\terror: %s\n", err)
+	if r.Error != "" {
+		fmt.Fprintf(d, "\terror: %s\n", r.Error)
 	}
-	if v, err := r.GetHostTemplate(); err == nil {
-		fmt.Fprintf(d, "\thostT: %s\n", v)
+	if r.HostTemplate != "" {
+		fmt.Fprintf(d, "\thostT: %s\n", r.HostTemplate)
 	}
-	if v, err := r.GetMethods(); err == nil {
-		fmt.Fprintf(d, "\tmethods: %s\n", v)
+	if len(r.Methods) > 0 {
+		fmt.Fprintf(d, "\tmethods: %s\n", r.Methods)
 	}
-	if v, err := r.GetPathTemplate(); err == nil {
-		fmt.Fprintf(d, "\tpathT: %s\n", v)
+	if r.PathTemplate != "" {
+		fmt.Fprintf(d, "\tpathT: %s\n", r.PathTemplate)
 	}
-	if v, err := r.GetPathRegexp(); err == nil {
-		fmt.Fprintf(d, "\tpathR: %s\n", v)
+	if r.PathRegexp != "" {
+		fmt.Fprintf(d, "\tpathR: %s\n", r.PathRegexp)
 	}
-	if v, err := r.GetQueriesTemplates(); err == nil {
-		fmt.Fprintf(d, "\tqueryT: %s\n", v)
+	if len(r.Queries) > 0 {
+		fmt.Fprintf(d, "\tqueryT: %s\n", r.Queries)
 	}
-	if v, err := r.GetQueriesRegexp(); err == nil {
-		fmt.Fprintf(d, "\tqueryR: %s\n", v)
+	if len(r.Middlewares) > 0 {
+		fmt.Fprintf(d, "\tmiddlewares: %s\n", r.Middlewares)
+	}
+	if r.Handler != "" {
+		fmt.Fprintf(d, "\thandler: %s\n", r.Handler)
 	}
-	fmt.Fprintf(d, "\thandler: %v\n", reflect.ValueOf(r.GetHandler()).String())
-	return nil
 }
+
+var routeTableTemplate = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Routes</title></head>
+<body>
+<table border="1">
+<tr><th>Name</th><th>Methods</th><th>Host</th><th>Path</th><th>Queries</th><th>Middlewares</th><th>Handler</th><th>Error</th></tr>
+{{- range . }}
+<tr><td>{{ .Name }}</td><td>{{ range .Methods }}{{ . }} {{ end }}</td><td>{{ .HostTemplate }}</td><td>{{ .PathTemplate }}</td><td>{{ range .Queries }}{{ . }} {{ end }}</td><td>{{ range .Middlewares }}{{ . }}<br>{{ end }}</td><td>{{ .Handler }}</td><td>{{ .Error }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
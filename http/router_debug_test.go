@@ -0,0 +1,64 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func listArticles(http.ResponseWriter, *http.Request) {}
+
+func TestRouterDebug(t *testing.T) {
+
+	router := mux.NewRouter()
+	router.Use(UniqueID)
+	router.HandleFunc("/articles", listArticles).Methods(http.MethodGet).Name("articles")
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(DebugRequest)
+	admin.Handle("/users", http.NotFoundHandler()).Methods(http.MethodPost).Name("users")
+	d := &RouterDebug{router}
+
+	get := func(query, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/debug/routes"+query, nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, r)
+		return w
+	}
+	getRoutes := func(query string) (routes []RouteInfo) {
+		if err := json.NewDecoder(get(query, MediaTypeJSON).Body).Decode(&routes); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		return
+	}
+
+	routes := getRoutes("")
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got %+v", routes)
+	}
+	articles, users := routes[0], routes[2]
+	if articles.Name != "articles" || articles.PathTemplate != "/articles" || !strings.HasSuffix(articles.Handler, ".listArticles") {
+		t.Errorf("articles: unexpected %+v", articles)
+	}
+	if len(users.Middlewares) != 2 || !strings.HasSuffix(users.Middlewares[0], ".UniqueID") || !strings.HasSuffix(users.Middlewares[1], ".DebugRequest") {
+		t.Errorf("users: expected the middlewares of both routers, got %v", users.Middlewares)
+	}
+
+	if routes := getRoutes("?path=admin&method=post"); len(routes) != 2 || routes[1].Name != "users" {
+		t.Errorf("filtered: expected the admin routes, got %+v", routes)
+	}
+	if routes := getRoutes("?method=DELETE"); len(routes) != 1 || routes[0].PathTemplate != "/admin" {
+		t.Errorf("filtered: expected the routes without methods, got %+v", routes)
+	}
+
+	if body := get("", "text/html").Body.String(); !strings.Contains(body, "<td>articles</td>") {
+		t.Errorf("HTML: expected a table row, got %s", body)
+	}
+	if body := get("", "text/plain").Body.String(); !strings.Contains(body, "\tname: users\n") {
+		t.Errorf("text: expected the route names, got %s", body)
+	}
+}
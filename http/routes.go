@@ -0,0 +1,133 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteLookup builds the path of named routes, so URLs can be generated regardless of the router.
+type RouteLookup interface {
+	// RoutePath returns the path of the route, with the parameters given as name/value pairs.
+	RoutePath(name string, pairs ...string) (*url.URL, error)
+}
+
+// MuxRoutes returns a RouteLookup using the named routes of a mux.Router.
+func MuxRoutes(router *mux.Router) RouteLookup {
+	return muxRoutes{router}
+}
+
+type muxRoutes struct {
+	router *mux.Router
+}
+
+func (m muxRoutes) RoutePath(name string, pairs ...string) (*url.URL, error) {
+	route := m.router.Get(name)
+	if route == nil {
+		return nil, fmt.Errorf("unknown route %q", name)
+	}
+	return route.URLPath(pairs...)
+}
+
+// PatternRoutes is a RouteLookup for routers without named routes, like chi or the http.ServeMux of Go 1.22.
+// Routes are registered by name along with their pattern:
+//
+//	routes := NewPatternRoutes()
+//	mux.Handle(routes.Register("article", "GET /articles/{id}"), articleHandler)
+//	chiRouter.Get(routes.Register("file", "/files/*"), fileHandler)
+//
+// Patterns may start with a method and a host, which are ignored. Parameters are written {name},
+// with an optional regular expression ({id:[0-9]+}); {name...} and a trailing * match the rest of the path,
+// with "*" as parameter name for the latter.
+type PatternRoutes struct {
+	patterns map[string]string
+	mu       sync.RWMutex
+}
+
+// NewPatternRoutes creates an empty PatternRoutes.
+func NewPatternRoutes() *PatternRoutes {
+	return &PatternRoutes{patterns: make(map[string]string)}
+}
+
+// Register names a route pattern and returns the pattern, to be passed to the router.
+func (p *PatternRoutes) Register(name, pattern string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.patterns[name] = pattern
+	return pattern
+}
+
+// RoutePath implements RouteLookup.
+func (p *PatternRoutes) RoutePath(name string, pairs ...string) (*url.URL, error) {
+	p.mu.RLock()
+	pattern, found := p.patterns[name]
+	p.mu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown route %q", name)
+	}
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of route parameters for %q: %v", name, pairs)
+	}
+	values := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		values[pairs[i]] = pairs[i+1]
+	}
+
+	if sp := strings.IndexByte(pattern, ' '); sp >= 0 {
+		pattern = strings.TrimSpace(pattern[sp+1:])
+	}
+	if slash := strings.IndexByte(pattern, '/'); slash > 0 {
+		pattern = pattern[slash:]
+	}
+
+	var path, rawPath strings.Builder
+	for len(pattern) > 0 {
+		open := strings.IndexByte(pattern, '{')
+		if open < 0 {
+			if strings.HasSuffix(pattern, "*") {
+				path.WriteString(pattern[:len(pattern)-1])
+				rawPath.WriteString(pattern[:len(pattern)-1])
+				pattern = "{*...}"
+				continue
+			}
+			path.WriteString(pattern)
+			rawPath.WriteString(pattern)
+			break
+		}
+		end := strings.IndexByte(pattern[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid pattern for route %q: unclosed parameter", name)
+		}
+		path.WriteString(pattern[:open])
+		rawPath.WriteString(pattern[:open])
+		param := pattern[open+1 : open+end]
+		pattern = pattern[open+end+1:]
+
+		if colon := strings.IndexByte(param, ':'); colon >= 0 {
+			param = param[:colon]
+		}
+		rest := strings.HasSuffix(param, "...")
+		param = strings.TrimSuffix(param, "...")
+		if param == "$" {
+			continue
+		}
+		value, found := values[param]
+		if !found {
+			return nil, fmt.Errorf("missing parameter %q for route %q", param, name)
+		}
+		path.WriteString(value)
+		if rest {
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			rawPath.WriteString(strings.Join(segments, "/"))
+		} else {
+			rawPath.WriteString(url.PathEscape(value))
+		}
+	}
+	return &url.URL{Path: path.String(), RawPath: rawPath.String()}, nil
+}
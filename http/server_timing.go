@@ -0,0 +1,76 @@
+package http
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Adirelle/go-libs/http/timing"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// ServerTiming is a middleware that collects the phase durations recorded with the timing package
+// during the request. They are sent in a Server-Timing header, along with the total time, and logged at Debug level.
+//
+// Only the phases that ended before the response headers are written are sent.
+func ServerTiming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		ctx, timings := timing.NewContext(r.Context())
+		tw := &timingWriter{writerBase: writerBase{w}, timings: timings, started: started}
+		next.ServeHTTP(wrapWriter(tw), r.WithContext(ctx))
+		tw.sendHeader()
+
+		if logger := logging.FromContext(r.Context(), nil); logger != nil {
+			fields := append(timings.Fields(), logging.DurationKey, time.Since(started).String())
+			logger.Debugw("server timing", fields...)
+		}
+	})
+}
+
+// timingWriter adds the Server-Timing header before the response headers are written.
+type timingWriter struct {
+	writerBase
+	timings *timing.Timings
+	started time.Time
+	sent    bool
+}
+
+func (t *timingWriter) sendHeader() {
+	if t.sent {
+		return
+	}
+	t.sent = true
+	header := timing.Metric{Name: "total", Duration: time.Since(t.started)}.String()
+	if phases := t.timings.Header(); phases != "" {
+		header = phases + ", " + header
+	}
+	t.Header().Set("Server-Timing", header)
+}
+
+func (t *timingWriter) WriteHeader(status int) {
+	t.sendHeader()
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *timingWriter) Write(b []byte) (int, error) {
+	t.sendHeader()
+	return t.ResponseWriter.Write(b)
+}
+
+func (t *timingWriter) Flush() {
+	t.sendHeader()
+	t.writerBase.Flush()
+}
+
+func (t *timingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	t.sent = true
+	return t.writerBase.Hijack()
+}
+
+func (t *timingWriter) ReadFrom(r io.Reader) (int64, error) {
+	t.sendHeader()
+	return t.writerBase.ReadFrom(r)
+}
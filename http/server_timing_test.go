@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adirelle/go-libs/http/timing"
+)
+
+func TestServerTiming(t *testing.T) {
+
+	handler := ServerTiming(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stop := timing.Start(r.Context(), "db")
+		time.Sleep(time.Millisecond)
+		stop()
+		w.Write([]byte("hello"))
+		// Too late to be sent.
+		timing.Start(r.Context(), "late")()
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	h := w.Header().Get("Server-Timing")
+	if !strings.HasPrefix(h, "db;dur=") || !strings.Contains(h, ", total;dur=") || strings.Contains(h, "late") {
+		t.Errorf("Server-Timing: expected db and total, got %q", h)
+	}
+	if w.Body.String() != "hello" {
+		t.Errorf("body: expected hello, got %q", w.Body.String())
+	}
+
+	// The header is sent even when the handler writes nothing.
+	w = httptest.NewRecorder()
+	ServerTiming(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if h := w.Header().Get("Server-Timing"); !strings.HasPrefix(h, "total;dur=") {
+		t.Errorf("Server-Timing: expected total, got %q", h)
+	}
+}
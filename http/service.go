@@ -2,31 +2,206 @@ package http
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.uber.org/multierr"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/Adirelle/go-libs/dic"
 	"github.com/Adirelle/go-libs/logging"
 )
 
 type Service struct {
 	http.Server
 	logging.Logger
+
+	// Addrs lists additional addresses to listen on, sharing the same handler.
+	// Addresses starting with "unix:" designate Unix domain sockets.
+	Addrs []string
+
+	// SocketMode, if not zero, sets the permissions of the Unix domain sockets.
+	SocketMode os.FileMode
+
+	// Health, if set, is drained when the service stops, so load balancers stop sending requests.
+	Health *Health
+
+	// DrainDelay is how long Stop waits after draining Health, before shutting down the server.
+	DrainDelay time.Duration
+
+	// H2C enables HTTP/2 over cleartext connections, e.g. from proxies or gRPC gateways.
+	H2C bool
+
+	// AutoCert, when set, is used to obtain and renew TLS certificates.
+	// The service then serves HTTPS and answers ACME HTTP-01 challenges on ChallengeAddr.
+	AutoCert *autocert.Manager
+
+	// ChallengeAddr is the address used to answer ACME challenges. It defaults to ":http".
+	ChallengeAddr string
+
+	challenge *http.Server
+	serving   sync.WaitGroup
+	inFlight  int64
+	stopping  int32
+	mu        sync.Mutex
+}
+
+var (
+	_ dic.Starter = (*Service)(nil)
+	_ dic.Stopper = (*Service)(nil)
+)
+
+// Addresses returns all the addresses the service listens on.
+func (w *Service) Addresses() []string {
+	var addrs []string
+	seen := make(map[string]bool)
+	for _, addr := range append([]string{w.Addr}, w.Addrs...) {
+		if addr != "" && !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		if w.AutoCert != nil {
+			addrs = []string{":https"}
+		} else {
+			addrs = []string{":http"}
+		}
+	}
+	return addrs
+}
+
+// Start listens on all the addresses and serves them in the background.
+// It returns an error if any address cannot be listened on.
+func (w *Service) Start(context.Context) error {
+	var listeners []net.Listener
+	for _, addr := range w.Addresses() {
+		l, err := w.listen(addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return fmt.Errorf("cannot listen on %s: %w", addr, err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	if w.AutoCert != nil {
+		w.startChallenge()
+	}
+	w.wrapHandler()
+
+	useTLS := w.TLSConfig != nil
+	w.serving.Add(len(listeners))
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			defer w.serving.Done()
+			w.Infof("listening on %s", l.Addr())
+			err := w.serveListener(l, useTLS)
+			if err != nil && err != http.ErrServerClosed {
+				w.Errorw("serve error", "address", l.Addr().String(), logging.ErrorKey, err)
+			}
+		}(l)
+	}
+	return nil
 }
 
+// Serve starts the service and blocks until it is stopped.
 func (w *Service) Serve() {
-	w.Infof("listening on %s", w.Addr)
-	err := w.ListenAndServe()
-	if err != nil && err != http.ErrServerClosed {
-		w.Error(err)
+	if err := w.Start(context.Background()); err != nil {
+		w.Errorw("cannot start", logging.ErrorKey, err)
+		return
 	}
+	w.serving.Wait()
 }
 
-func (w *Service) Stop() {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
-	err := w.Shutdown(ctx)
+// wrapHandler wraps the handler to track the requests and, if enabled, to support h2c.
+func (w *Service) wrapHandler() {
+	switch w.Handler.(type) {
+	case h2cHandler, trackingHandler:
+		return
+	}
+	handler := w.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	w.Handler = trackingHandler{w, handler}
+	if w.H2C {
+		w.Handler = h2cHandler{h2c.NewHandler(w.Handler, &http2.Server{})}
+	}
+}
+
+// h2cHandler marks handlers that have already been wrapped for h2c.
+type h2cHandler struct{ http.Handler }
+
+// trackingHandler counts the requests in flight and rejects new ones once the service is stopping.
+type trackingHandler struct {
+	s *Service
+	http.Handler
+}
+
+func (t trackingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&t.s.stopping) != 0 {
+		w.Header().Set("Connection", "close")
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	atomic.AddInt64(&t.s.inFlight, 1)
+	defer atomic.AddInt64(&t.s.inFlight, -1)
+	t.Handler.ServeHTTP(w, r)
+}
+
+// InFlight returns the number of requests being handled.
+func (w *Service) InFlight() int {
+	return int(atomic.LoadInt64(&w.inFlight))
+}
+
+func (w *Service) serveListener(l net.Listener, useTLS bool) error {
+	if useTLS {
+		return w.Server.ServeTLS(l, "", "")
+	}
+	return w.Server.Serve(l)
+}
+
+// Stop drains Health, if set, and gracefully shuts down the service, until the context is done.
+// Requests are still served during the DrainDelay, so that load balancers can notice the readiness change;
+// new requests are rejected with 503 Service Unavailable once it is over. The connections still handling
+// requests when the context is done are closed, and the number of aborted requests logged.
+func (w *Service) Stop(ctx context.Context) (err error) {
+	if w.Health != nil {
+		w.Health.Drain()
+		if w.DrainDelay > 0 {
+			w.Infof("draining for %s", w.DrainDelay)
+			select {
+			case <-time.After(w.DrainDelay):
+			case <-ctx.Done():
+			}
+		}
+	}
+	w.mu.Lock()
+	challenge := w.challenge
+	w.mu.Unlock()
+	if challenge != nil {
+		err = challenge.Shutdown(ctx)
+	}
+	atomic.StoreInt32(&w.stopping, 1)
+	if shutdownErr := w.Shutdown(ctx); shutdownErr != nil {
+		if aborted := w.InFlight(); aborted > 0 {
+			w.Warnw("drain deadline passed, aborting requests", "aborted", aborted)
+		}
+		err = multierr.Append(err, shutdownErr)
+		err = multierr.Append(err, w.Close())
+	}
 	if err != nil {
 		w.Error(err)
 	}
 	w.Info("stopped")
+	return
 }
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// ServiceConfig configures a Service built with NewService.
+//
+// Zero durations and sizes are replaced by secure defaults; negative durations disable the timeouts.
+type ServiceConfig struct {
+	// Addrs lists the addresses to listen on. See Service.Addrs.
+	Addrs []string
+
+	// ReadHeaderTimeout is the time allowed to read the request headers. It defaults to 10 seconds.
+	ReadHeaderTimeout time.Duration
+
+	// ReadTimeout is the time allowed to read the whole request. It defaults to 30 seconds.
+	ReadTimeout time.Duration
+
+	// WriteTimeout is the time allowed to write the response. It defaults to 60 seconds.
+	WriteTimeout time.Duration
+
+	// IdleTimeout is how long to wait for the next request on keep-alive connections. It defaults to 120 seconds.
+	IdleTimeout time.Duration
+
+	// MaxHeaderBytes is the maximum size of the request headers. It defaults to 64 KiB.
+	MaxHeaderBytes int
+
+	// DisableKeepAlives closes the connections after each request.
+	DisableKeepAlives bool
+}
+
+// DefaultServiceConfig returns the configuration with all defaults set.
+func DefaultServiceConfig() ServiceConfig {
+	return ServiceConfig{
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    64 << 10,
+	}
+}
+
+func (c *ServiceConfig) setDefaults() {
+	defaults := DefaultServiceConfig()
+	for _, d := range []struct{ value, def *time.Duration }{
+		{&c.ReadHeaderTimeout, &defaults.ReadHeaderTimeout},
+		{&c.ReadTimeout, &defaults.ReadTimeout},
+		{&c.WriteTimeout, &defaults.WriteTimeout},
+		{&c.IdleTimeout, &defaults.IdleTimeout},
+	} {
+		if *d.value == 0 {
+			*d.value = *d.def
+		} else if *d.value < 0 {
+			*d.value = 0
+		}
+	}
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes = defaults.MaxHeaderBytes
+	}
+}
+
+// Validate checks the configuration, once the defaults are applied.
+func (c ServiceConfig) Validate() error {
+	c.setDefaults()
+	if c.MaxHeaderBytes < 0 {
+		return fmt.Errorf("invalid MaxHeaderBytes: %d", c.MaxHeaderBytes)
+	}
+	if c.ReadTimeout > 0 && c.ReadHeaderTimeout > c.ReadTimeout {
+		return errors.New("ReadHeaderTimeout must not exceed ReadTimeout")
+	}
+	return nil
+}
+
+// NewService creates a Service using the configuration, the handler and the logger.
+func NewService(conf ServiceConfig, handler http.Handler, logger logging.Logger) (*Service, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	conf.setDefaults()
+	s := &Service{Logger: logger, Addrs: conf.Addrs}
+	s.Handler = handler
+	s.ReadHeaderTimeout = conf.ReadHeaderTimeout
+	s.ReadTimeout = conf.ReadTimeout
+	s.WriteTimeout = conf.WriteTimeout
+	s.IdleTimeout = conf.IdleTimeout
+	s.MaxHeaderBytes = conf.MaxHeaderBytes
+	s.SetKeepAlivesEnabled(!conf.DisableKeepAlives)
+	return s, nil
+}
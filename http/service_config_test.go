@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+func TestNewService(t *testing.T) {
+
+	s, err := NewService(ServiceConfig{Addrs: []string{":8080"}, WriteTimeout: -1}, http.NotFoundHandler(), logging.NewTesting(t))
+	if err != nil {
+		t.Fatalf("NewService: unexpected error %v", err)
+	}
+	if s.ReadHeaderTimeout != 10*time.Second || s.ReadTimeout != 30*time.Second || s.IdleTimeout != 120*time.Second {
+		t.Errorf("expected the default timeouts, got %s, %s, %s", s.ReadHeaderTimeout, s.ReadTimeout, s.IdleTimeout)
+	}
+	if s.WriteTimeout != 0 {
+		t.Errorf("negative WriteTimeout: expected no timeout, got %s", s.WriteTimeout)
+	}
+	if s.MaxHeaderBytes != 64<<10 || len(s.Addrs) != 1 || s.Addrs[0] != ":8080" {
+		t.Errorf("unexpected Service %+v", s)
+	}
+}
+
+func TestServiceConfigValidate(t *testing.T) {
+
+	cases := map[string]ServiceConfig{
+		"negative MaxHeaderBytes": {MaxHeaderBytes: -1},
+		"ReadHeaderTimeout":       {ReadHeaderTimeout: time.Minute, ReadTimeout: time.Second},
+	}
+	for name, conf := range cases {
+		if err := conf.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if _, err := NewService(conf, nil, logging.NewTesting(t)); err == nil {
+			t.Errorf("%s: expected NewService to fail", name)
+		}
+	}
+	if err := (ServiceConfig{ReadHeaderTimeout: time.Minute, ReadTimeout: -1}).Validate(); err != nil {
+		t.Errorf("disabled ReadTimeout: unexpected error %v", err)
+	}
+}
+
+func TestServiceKeepAlives(t *testing.T) {
+
+	s, err := NewService(ServiceConfig{Addrs: []string{freeAddr(t)}, DisableKeepAlives: true}, http.NotFoundHandler(), logging.NewTesting(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop(context.Background())
+
+	resp, err := http.Get("http://" + s.Addrs[0] + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !resp.Close {
+		t.Errorf("expected the connection to be closed after the response")
+	}
+}
@@ -0,0 +1,303 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// freeAddr returns a local address that is likely free to listen on.
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// startService runs the service in the background, until the test ends.
+func startService(t *testing.T, w *Service, addrs ...string) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Serve()
+	}()
+	t.Cleanup(func() {
+		w.Stop(context.Background())
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("Serve did not return after Stop")
+		}
+	})
+	for _, addr := range addrs {
+		waitListening(t, "tcp", addr)
+	}
+}
+
+func waitListening(t *testing.T, network, addr string) {
+	deadline := time.Now().Add(time.Second)
+	for {
+		conn, err := net.Dial(network, addr)
+		if err == nil {
+			conn.Close()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s is not listening: %s", addr, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAddresses(t *testing.T) {
+
+	cases := []struct {
+		w        *Service
+		expected []string
+	}{
+		{&Service{}, []string{":http"}},
+		{&Service{AutoCert: NewAutoCertManager(nil)}, []string{":https"}},
+		{&Service{Server: http.Server{Addr: ":8080"}, Addrs: []string{":8081", ":8080"}}, []string{":8080", ":8081"}},
+	}
+	for i, c := range cases {
+		if actual := c.w.Addresses(); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestServeSeveralAddresses(t *testing.T) {
+
+	addrs := []string{freeAddr(t), freeAddr(t)}
+	w := &Service{Logger: logging.NewTesting(t), Addrs: addrs}
+	w.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, "hello")
+	})
+	startService(t, w, addrs...)
+
+	for _, addr := range addrs {
+		resp, err := http.Get("http://" + addr + "/")
+		if err != nil {
+			t.Fatalf("%s: %s", addr, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "hello" {
+			t.Errorf("%s: expected hello, got %q", addr, body)
+		}
+	}
+}
+
+func TestServeH2C(t *testing.T) {
+
+	w := &Service{Logger: logging.NewTesting(t), H2C: true}
+	w.Addr = freeAddr(t)
+	w.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, r.Proto)
+	})
+	startService(t, w, w.Addr)
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err := client.Get("http://" + w.Addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/2.0" {
+		t.Errorf("expected HTTP/2.0, got %q", body)
+	}
+}
+
+func TestStopDrainsHealth(t *testing.T) {
+
+	w := &Service{Logger: logging.NewTesting(t), Health: NewHealth(), DrainDelay: 200 * time.Millisecond}
+	w.Addr = freeAddr(t)
+	mux := http.NewServeMux()
+	mux.Handle(LivenessPath, w.Health.LivenessHandler())
+	mux.Handle(ReadinessPath, w.Health.ReadinessHandler())
+	w.Handler = mux
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Serve()
+	}()
+	waitListening(t, "tcp", w.Addr)
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get("http://" + w.Addr + path)
+		if err != nil {
+			t.Fatalf("expected the service to be serving while draining, got %s", err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		w.Stop(context.Background())
+	}()
+
+	// The service keeps serving during the drain delay, reporting it is not ready.
+	deadline := time.Now().Add(time.Second)
+	for {
+		resp, body := get(ReadinessPath)
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			if !strings.Contains(body, StatusDraining) || resp.Header.Get("Connection") == "close" {
+				t.Errorf("readiness: expected a %s report, got %q", StatusDraining, body)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected readiness to fail while draining, got %d", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if resp, body := get(LivenessPath); resp.StatusCode != http.StatusOK {
+		t.Errorf("liveness: expected 200 while draining, got %d %q", resp.StatusCode, body)
+	}
+
+	<-stopped
+	<-done
+}
+
+func TestStartStop(t *testing.T) {
+
+	ctx := context.Background()
+	w := &Service{Logger: logging.NewTesting(t)}
+	w.Addr = freeAddr(t)
+	w.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, "hello")
+	})
+
+	// Start returns once the service is listening.
+	if err := w.Start(ctx); err != nil {
+		t.Fatalf("Start: unexpected error %v", err)
+	}
+	resp, err := http.Get("http://" + w.Addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Another service cannot listen on the same address.
+	other := &Service{Logger: logging.NewTesting(t)}
+	other.Addr = w.Addr
+	if err := other.Start(ctx); err == nil {
+		other.Stop(ctx)
+		t.Errorf("Start: expected an error for an address in use")
+	}
+
+	if err := w.Stop(ctx); err != nil {
+		t.Errorf("Stop: unexpected error %v", err)
+	}
+	if _, err := http.Get("http://" + w.Addr + "/"); err == nil {
+		t.Errorf("expected the service to be stopped")
+	}
+}
+
+func TestInFlight(t *testing.T) {
+
+	ctx := context.Background()
+	started, release := make(chan struct{}), make(chan struct{})
+	w := &Service{Logger: logging.NewTesting(t)}
+	w.Addr = freeAddr(t)
+	w.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		fmt.Fprint(rw, "done")
+	})
+	if err := w.Start(ctx); err != nil {
+		t.Fatalf("Start: unexpected error %v", err)
+	}
+
+	result := make(chan string)
+	go func() {
+		resp, err := http.Get("http://" + w.Addr + "/")
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		result <- string(body)
+	}()
+	<-started
+	if n := w.InFlight(); n != 1 {
+		t.Errorf("InFlight: expected 1, got %d", n)
+	}
+
+	// Stop waits for the request in flight.
+	stopped := make(chan error)
+	go func() { stopped <- w.Stop(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	if body := <-result; body != "done" {
+		t.Errorf("expected the request in flight to complete, got %q", body)
+	}
+	if err := <-stopped; err != nil {
+		t.Errorf("Stop: unexpected error %v", err)
+	}
+	if n := w.InFlight(); n != 0 {
+		t.Errorf("InFlight: expected 0, got %d", n)
+	}
+
+	// New requests are rejected.
+	rec := httptest.NewRecorder()
+	w.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Connection") != "close" {
+		t.Errorf("after Stop: expected 503 with Connection: close, got %d", rec.Code)
+	}
+}
+
+func TestStopAbortsRequests(t *testing.T) {
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	w := &Service{Logger: logging.NewTesting(t)}
+	w.Addr = freeAddr(t)
+	w.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start: unexpected error %v", err)
+	}
+
+	failed := make(chan error)
+	go func() {
+		_, err := http.Get("http://" + w.Addr + "/")
+		failed <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.Stop(ctx); err == nil {
+		t.Errorf("Stop: expected a deadline error")
+	}
+	if err := <-failed; err == nil {
+		t.Errorf("expected the request in flight to be aborted")
+	}
+}
@@ -0,0 +1,110 @@
+// Package timing accumulates the durations of the phases of a request, e.g. to send them
+// in a Server-Timing response header.
+package timing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type contextKey int
+
+const timingsKey = contextKey(1)
+
+// Metric is a named phase duration.
+type Metric struct {
+	Name        string
+	Description string
+	Duration    time.Duration
+}
+
+// String formats the metric as in a Server-Timing header, with the duration in milliseconds.
+func (m Metric) String() string {
+	s := fmt.Sprintf("%s;dur=%.3f", m.Name, float64(m.Duration)/float64(time.Millisecond))
+	if m.Description != "" {
+		s += fmt.Sprintf(";desc=%q", m.Description)
+	}
+	return s
+}
+
+// Timings accumulates metrics. It is safe for concurrent use.
+type Timings struct {
+	metrics []Metric
+	mu      sync.Mutex
+}
+
+// NewContext returns a Context holding a new Timings.
+func NewContext(ctx context.Context) (context.Context, *Timings) {
+	t := &Timings{}
+	return context.WithValue(ctx, timingsKey, t), t
+}
+
+// FromContext returns the Timings of the Context, or nil.
+func FromContext(ctx context.Context) *Timings {
+	t, _ := ctx.Value(timingsKey).(*Timings)
+	return t
+}
+
+// Start starts timing a phase of the request and returns the function to call at its end.
+// It does nothing if the Context has no Timings.
+//
+//	defer timing.Start(ctx, "db")()
+func Start(ctx context.Context, name string) (stop func()) {
+	t := FromContext(ctx)
+	if t == nil {
+		return func() {}
+	}
+	return t.Start(name)
+}
+
+// Start starts timing a phase and returns the function to call at its end.
+func (t *Timings) Start(name string) (stop func()) {
+	started := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() { t.Add(name, "", time.Since(started)) })
+	}
+}
+
+// Add adds a metric. Durations of metrics with the same name are summed.
+func (t *Timings) Add(name, description string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.metrics {
+		if t.metrics[i].Name == name {
+			t.metrics[i].Duration += d
+			return
+		}
+	}
+	t.metrics = append(t.metrics, Metric{name, description, d})
+}
+
+// Metrics returns a copy of the metrics, in order of addition.
+func (t *Timings) Metrics() []Metric {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Metric(nil), t.metrics...)
+}
+
+// Header formats the metrics as the value of a Server-Timing header, with durations in milliseconds.
+func (t *Timings) Header() string {
+	metrics := t.Metrics()
+	parts := make([]string, len(metrics))
+	for i, m := range metrics {
+		parts[i] = m.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Fields returns the metrics as structured logging fields, keyed by "timing." followed by their name.
+func (t *Timings) Fields() []interface{} {
+	metrics := t.Metrics()
+	fields := make([]interface{}, 0, 2*len(metrics))
+	for _, m := range metrics {
+		fields = append(fields, "timing."+m.Name, m.Duration.String())
+	}
+	return fields
+}
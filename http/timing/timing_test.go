@@ -0,0 +1,39 @@
+package timing
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+
+	ctx, timings := NewContext(context.Background())
+	if FromContext(ctx) != timings {
+		t.Fatalf("FromContext: expected the Timings of NewContext")
+	}
+
+	timings.Add("db", "database", 2*time.Millisecond)
+	timings.Add("db", "", 3*time.Millisecond)
+	stop := Start(ctx, "render")
+	stop()
+	stop()
+
+	metrics := timings.Metrics()
+	if len(metrics) != 2 || metrics[0].Name != "db" || metrics[0].Duration != 5*time.Millisecond || metrics[1].Name != "render" {
+		t.Errorf("Metrics: expected db (5ms) and render, got %v", metrics)
+	}
+	if h := timings.Header(); !strings.HasPrefix(h, `db;dur=5.000;desc="database", render;dur=`) {
+		t.Errorf("Header: unexpected %q", h)
+	}
+	if f := timings.Fields(); len(f) != 4 || f[0] != "timing.db" || f[1] != "5ms" {
+		t.Errorf("Fields: unexpected %v", f)
+	}
+
+	// Without Timings, Start does nothing.
+	Start(context.Background(), "ignored")()
+	if FromContext(context.Background()) != nil {
+		t.Errorf("FromContext: expected nil")
+	}
+}
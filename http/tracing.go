@@ -0,0 +1,128 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// TracingConfig configures the Tracing middleware.
+type TracingConfig struct {
+	// Tracer is used to start the spans. It defaults to a tracer of the global provider.
+	Tracer trace.Tracer
+
+	// Propagator extracts the remote span context from the request headers.
+	// It defaults to W3C Trace Context (traceparent and tracestate headers).
+	Propagator propagation.TextMapPropagator
+}
+
+// Tracing returns a middleware that starts a server span for each request.
+// The span is available to the next handlers through trace.SpanFromContext,
+// and its identifiers are added to any Logger associated with the Request Context.
+func Tracing(conf TracingConfig) func(http.Handler) http.Handler {
+	if conf.Tracer == nil {
+		conf.Tracer = otel.Tracer("github.com/Adirelle/go-libs/http")
+	}
+	if conf.Propagator == nil {
+		conf.Propagator = propagation.TraceContext{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := conf.Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			name := r.Method
+			attrs := []attribute.KeyValue{
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("server.address", r.Host),
+				attribute.String("client.address", ClientIP(r)),
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				if tpl, err := route.GetPathTemplate(); err == nil {
+					name += " " + tpl
+					attrs = append(attrs, attribute.String("http.route", tpl))
+				}
+			}
+			if uniqueID, found := ctx.Value(uniqueIDKey).(string); found {
+				attrs = append(attrs, attribute.String("http.request.unique_id", uniqueID))
+			}
+
+			ctx, span := conf.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+			defer span.End()
+
+			if logger := logging.FromContext(ctx, nil); logger != nil {
+				sc := span.SpanContext()
+				ctx = logging.WithLogger(ctx, logger.With("traceID", sc.TraceID().String(), "spanID", sc.SpanID().String()))
+			}
+
+			sw := &statusWriter{writerBase: writerBase{w}}
+			next.ServeHTTP(wrapWriter(sw), r.WithContext(ctx))
+
+			status := sw.Status()
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= 500 {
+				span.SetStatus(codes.Error, fmt.Sprintf("%d %s", status, http.StatusText(status)))
+			}
+		})
+	}
+}
+
+// statusWriter records the response status.
+type statusWriter struct {
+	writerBase
+	status int
+}
+
+func (s *statusWriter) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Status returns the response status, or 200 if nothing has been written yet.
+func (s *statusWriter) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
+
+func (s *statusWriter) Flush() {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	s.writerBase.Flush()
+}
+
+func (s *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if s.status == 0 {
+		s.status = http.StatusSwitchingProtocols
+	}
+	return s.writerBase.Hijack()
+}
+
+func (s *statusWriter) ReadFrom(r io.Reader) (int64, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.writerBase.ReadFrom(r)
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+
+	var sc trace.SpanContext
+	handler := Tracing(TracingConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc = trace.SpanFromContext(r.Context()).SpanContext()
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if sc.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the inbound trace ID, got %s", sc.TraceID())
+	}
+	if w.Code != http.StatusTeapot {
+		t.Errorf("expected 418, got %d", w.Code)
+	}
+}
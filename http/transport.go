@@ -0,0 +1,202 @@
+package http
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Adirelle/go-libs/cache"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+func nextTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		return http.DefaultTransport
+	}
+	return next
+}
+
+//===========================================================================
+// Logging
+//===========================================================================
+
+// LoggingTransport logs the requests to the logger of their context, if any.
+// Responses are logged at Debug level, errors and server errors at Warn level.
+type LoggingTransport struct {
+	// Next performs the requests. It defaults to http.DefaultTransport.
+	Next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := logging.FromContext(req.Context(), nil)
+	if logger == nil {
+		return nextTransport(t.Next).RoundTrip(req)
+	}
+	started := time.Now()
+	resp, err := nextTransport(t.Next).RoundTrip(req)
+	fields := []interface{}{
+		logging.MethodKey, req.Method,
+		logging.URLKey, req.URL.Redacted(),
+		logging.DurationKey, time.Since(started).String(),
+	}
+	switch {
+	case err != nil:
+		logger.WarnE(err, "client request failed", fields...)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		logger.Warnw("client request: "+resp.Status, append(fields, "status", resp.StatusCode)...)
+	default:
+		logger.Debugw("client request: "+resp.Status, append(fields, "status", resp.StatusCode)...)
+	}
+	return resp, err
+}
+
+//===========================================================================
+// Retries
+//===========================================================================
+
+// RetryTransport retries idempotent requests that failed or received a retryable status.
+//
+// Requests are idempotent if their method is, or if they have an Idempotency-Key header.
+// Requests with a body can only be retried if their GetBody is set, which http.NewRequest does for usual bodies.
+type RetryTransport struct {
+	// Next performs the requests. It defaults to http.DefaultTransport.
+	Next http.RoundTripper
+
+	// MaxRetries is the maximum number of retries. It defaults to 3.
+	MaxRetries int
+
+	// Backoff returns the delay before the given retry, starting at 1.
+	// It defaults to cache.ExponentialBackoff from 100ms.
+	Backoff cache.BackoffFunc
+
+	// RetryStatus lists the statuses that are retried. It defaults to 429, 502, 503 and 504.
+	// The Retry-After header of these responses is honored.
+	RetryStatus []int
+}
+
+var defaultRetryStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	maxRetries := t.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	backoff := t.Backoff
+	if backoff == nil {
+		backoff = cache.ExponentialBackoff(100 * time.Millisecond)
+	}
+	retryable := idempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for retry := 0; ; retry++ {
+		if retry > 0 && req.GetBody != nil {
+			attempt := req.Clone(req.Context())
+			if attempt.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+			resp, err = nextTransport(t.Next).RoundTrip(attempt)
+		} else {
+			resp, err = nextTransport(t.Next).RoundTrip(req)
+		}
+		if !retryable || retry >= maxRetries || (err == nil && !t.retryStatus(resp.StatusCode)) {
+			return
+		}
+
+		delay := backoff(retry + 1)
+		if err == nil {
+			if after := retryAfter(resp); after > delay {
+				delay = after
+			}
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		if logger := logging.FromContext(req.Context(), nil); logger != nil {
+			logger.Debugw("retrying client request", logging.URLKey, req.URL.Redacted(), "retry", retry+1, "delay", delay.String())
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func (t *RetryTransport) retryStatus(status int) bool {
+	statuses := t.RetryStatus
+	if statuses == nil {
+		statuses = defaultRetryStatus
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryAfter parses the Retry-After header of the response, either in seconds or as a date.
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+//===========================================================================
+// Caching
+//===========================================================================
+
+// CachingTransport stores the responses to GET requests in a cache, using cache.NewTransportWith: fresh responses
+// are served from the cache, and stale ones are revalidated when they have an ETag or a Last-Modified header.
+// Responses served from the cache have a X-From-Cache header.
+type CachingTransport struct {
+	// Next performs the requests. It defaults to http.DefaultTransport.
+	Next http.RoundTripper
+
+	// Cache stores the responses.
+	Cache cache.Cache
+
+	// MaxBodySize is the size above which responses are not cached. It defaults to DefaultMaxCachedBodySize.
+	MaxBodySize int
+
+	once      sync.Once
+	transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(func() {
+		maxSize := t.MaxBodySize
+		if maxSize <= 0 {
+			maxSize = DefaultMaxCachedBodySize
+		}
+		t.transport = cache.NewTransportWith(t.Cache, cache.TransportConfig{Inner: t.Next, MaxBodySize: maxSize})
+	})
+	return t.transport.RoundTrip(req)
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adirelle/go-libs/cache"
+	"github.com/Adirelle/go-libs/logging"
+)
+
+func TestCachingTransport(t *testing.T) {
+
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &CachingTransport{Cache: cache.NewMemoryStorage()}}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "hello" || (resp.Header.Get("X-From-Cache") != "") != (i > 0) {
+			t.Errorf("GET %d: unexpected response %q, X-From-Cache: %q", i, body, resp.Header.Get("X-From-Cache"))
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected 1 request to the server, got %d", hits)
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+
+	hits, failures := 0, 2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		body, _ := ioutil.ReadAll(r.Body)
+		if hits <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &RetryTransport{Backoff: func(int) time.Duration { return time.Millisecond }}}
+
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("not idempotent"))
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || hits != 1 {
+		t.Errorf("POST: expected one 503, got %d requests, %v", hits, err)
+	}
+
+	hits = 0
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("hello"))
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" || hits != 3 {
+		t.Errorf("PUT: expected 200 hello after 3 requests, got %d %q after %d", resp.StatusCode, body, hits)
+	}
+
+	hits, failures = 0, 10
+	client.Transport.(*RetryTransport).MaxRetries = 2
+	if resp, err = client.Get(srv.URL); err != nil || resp.StatusCode != http.StatusServiceUnavailable || hits != 3 {
+		t.Errorf("GET: expected 503 after 3 requests, got %d requests, %v", hits, err)
+	}
+}
+
+func TestLoggingTransport(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	l := &recordingLogger{Logger: logging.NewTesting(t)}
+	client := &http.Client{Transport: &LoggingTransport{}}
+	for _, path := range []string{"/", "/broken"} {
+		req, _ := http.NewRequestWithContext(logging.WithLogger(context.Background(), l), http.MethodGet, srv.URL+path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	expected := []string{"DEBUG client request: 200 OK", "WARN client request: 502 Bad Gateway"}
+	if !reflect.DeepEqual(l.entries, expected) {
+		t.Errorf("expected %q, got %q", expected, l.entries)
+	}
+}
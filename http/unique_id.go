@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
 
 	"github.com/Adirelle/go-libs/logging"
 )
@@ -15,18 +19,102 @@ const (
 	uniqueIDKey = contextKey(1)
 )
 
-// UniqueID adds a unique ID to the Request Context, ResponseWriter and any associated Logger
+// MaxRequestIDLength is the maximum length of inbound request IDs.
+const MaxRequestIDLength = 128
+
+// IDGenerator generates unique request IDs.
+type IDGenerator func() string
+
+// RandomID generates a random 64-bit hexadecimal ID.
+func RandomID() string {
+	return fmt.Sprintf("%08X", rand.Uint64())
+}
+
+// ULID generates an ULID, which sorts by creation time.
+func ULID() string {
+	return ulid.Make().String()
+}
+
+// UUID generates a random (version 4) UUID.
+func UUID() string {
+	return uuid.NewString()
+}
+
+// UniqueIDConfig configures the UniqueIDWith middleware.
+type UniqueIDConfig struct {
+	// InboundHeader is the request header holding an ID set by an upstream service or proxy.
+	// Inbound IDs are ignored when it is empty.
+	InboundHeader string
+
+	// ResponseHeader is the response header the ID is sent in.
+	ResponseHeader string
+
+	// Generator generates the IDs of requests without a valid inbound ID.
+	Generator IDGenerator
+}
+
+// DefaultUniqueIDConfig returns the configuration used by UniqueID.
+func DefaultUniqueIDConfig() UniqueIDConfig {
+	return UniqueIDConfig{
+		InboundHeader:  "X-Request-ID",
+		ResponseHeader: "X-UniqueID",
+		Generator:      RandomID,
+	}
+}
+
+var uniqueID = UniqueIDWith(DefaultUniqueIDConfig())
+
+// UniqueID adds a unique ID to the Request Context, ResponseWriter and any associated Logger.
+// It uses the X-Request-ID header of the request, if valid.
 func UniqueID(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		uniqueID := fmt.Sprintf("%08X", rand.Uint64())
-		w.Header().Set("X-UniqueID", uniqueID)
-		ctx := r.Context()
-		if logger := logging.FromContext(ctx, nil); logger != nil {
-			ctx = logging.WithLogger(ctx, logger.With("uniqueID", uniqueID))
+	return uniqueID(next)
+}
+
+// UniqueIDWith returns a middleware that adds a unique ID to the Request Context, ResponseWriter
+// and any associated Logger.
+func UniqueIDWith(conf UniqueIDConfig) func(http.Handler) http.Handler {
+	if conf.Generator == nil {
+		conf.Generator = RandomID
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var uniqueID string
+			if conf.InboundHeader != "" {
+				uniqueID = NormalizeRequestID(r.Header.Get(conf.InboundHeader))
+			}
+			if uniqueID == "" {
+				uniqueID = conf.Generator()
+			}
+			if conf.ResponseHeader != "" {
+				w.Header().Set(conf.ResponseHeader, uniqueID)
+			}
+			ctx := r.Context()
+			if logger := logging.FromContext(ctx, nil); logger != nil {
+				ctx = logging.WithLogger(ctx, logger.With("uniqueID", uniqueID))
+			}
+			ctx = context.WithValue(ctx, uniqueIDKey, uniqueID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// NormalizeRequestID trims the inbound request ID and validates it.
+// It returns an empty string if the ID is too long or contains characters other than
+// letters, digits, '-', '_', '.', ':' and '+'.
+func NormalizeRequestID(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) > MaxRequestIDLength {
+		return ""
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("-_.:+", c):
+		default:
+			return ""
 		}
-		ctx = context.WithValue(ctx, uniqueIDKey, uniqueID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	}
+	return id
 }
 
 // UniqueIDFromContext retrieves the uniqueID from the Context
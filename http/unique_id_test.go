@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUniqueID(t *testing.T) {
+
+	var id string
+	handler := UniqueID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = UniqueIDFromContext(r.Context())
+	}))
+
+	get := func(inbound string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if inbound != "" {
+			r.Header.Set("X-Request-ID", inbound)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get(" upstream-42 "); id != "upstream-42" || w.Header().Get("X-UniqueID") != id {
+		t.Errorf("inbound ID: expected upstream-42, got %q, header %q", id, w.Header().Get("X-UniqueID"))
+	}
+	if w := get("<script>"); id == "" || id == "<script>" || w.Header().Get("X-UniqueID") != id {
+		t.Errorf("invalid inbound ID: expected a generated ID, got %q, header %q", id, w.Header().Get("X-UniqueID"))
+	}
+	first := id
+	if get(""); id == "" || id == first {
+		t.Errorf("no inbound ID: expected a new ID, got %q", id)
+	}
+}
+
+func TestUniqueIDWith(t *testing.T) {
+
+	conf := UniqueIDConfig{ResponseHeader: "X-Trace", Generator: func() string { return "fixed" }}
+	var id string
+	handler := UniqueIDWith(conf)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = UniqueIDFromContext(r.Context())
+	}))
+
+	// Inbound IDs are ignored without InboundHeader.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Request-ID", "upstream-42")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if id != "fixed" || w.Header().Get("X-Trace") != "fixed" {
+		t.Errorf("expected the generated ID, got %q, header %q", id, w.Header().Get("X-Trace"))
+	}
+}
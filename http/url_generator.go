@@ -2,8 +2,9 @@ package http
 
 import (
 	"context"
-	"fmt"
+	"html/template"
 	"net/http"
+	"net/url"
 
 	"github.com/gorilla/mux"
 )
@@ -16,11 +17,41 @@ const (
 type URLSpec struct {
 	Route      string
 	Parameters []string
+
+	// Query holds the query parameters.
+	Query url.Values
+
+	// Fragment is the fragment of the URL, without the leading '#'.
+	Fragment string
+
+	// Relative requests an URL without scheme and host.
+	Relative bool
 }
 
 // NewURLSpec is a helper to easily build an URLSPEC
 func NewURLSpec(name string, pairs ...string) *URLSpec {
-	return &URLSpec{name, pairs}
+	return &URLSpec{Route: name, Parameters: pairs}
+}
+
+// WithQuery adds a query parameter and returns the URLSpec.
+func (s *URLSpec) WithQuery(key, value string) *URLSpec {
+	if s.Query == nil {
+		s.Query = make(url.Values)
+	}
+	s.Query.Add(key, value)
+	return s
+}
+
+// WithFragment sets the fragment and returns the URLSpec.
+func (s *URLSpec) WithFragment(fragment string) *URLSpec {
+	s.Fragment = fragment
+	return s
+}
+
+// AsRelative requests a relative URL and returns the URLSpec.
+func (s *URLSpec) AsRelative() *URLSpec {
+	s.Relative = true
+	return s
 }
 
 // URLGenerator generates a fully-fledged URL from the URLSpec
@@ -28,33 +59,79 @@ type URLGenerator interface {
 	URL(*URLSpec) (string, error)
 }
 
-// RouterURLGenerator implements URLGenerator using a mux.Router
+// MustURL generates the URL, panicking on error. It is intended for routes known to exist.
+func MustURL(g URLGenerator, s *URLSpec) string {
+	url, err := g.URL(s)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+// URLFuncs returns template functions generating URLs with the URLGenerator:
+//
+//	<a href="{{ url "article" "id" .ID }}">permalink</a>
+//	<a href="{{ urlPath "article" "id" .ID }}">link</a>
+//
+// Both take a route name followed by its parameters; "url" returns absolute URLs, "urlPath" relative ones.
+func URLFuncs(g URLGenerator) template.FuncMap {
+	return template.FuncMap{
+		"url": func(route string, pairs ...string) (string, error) {
+			return g.URL(NewURLSpec(route, pairs...))
+		},
+		"urlPath": func(route string, pairs ...string) (string, error) {
+			return g.URL(NewURLSpec(route, pairs...).AsRelative())
+		},
+	}
+}
+
+// RouterURLGenerator implements URLGenerator using a RouteLookup
 type RouterURLGenerator struct {
-	router *mux.Router
+	routes RouteLookup
+	scheme string
 	host   string
 }
 
 func (r *RouterURLGenerator) URL(s *URLSpec) (url string, err error) {
-	route := r.router.Get(s.Route)
-	if route == nil {
-		return "", fmt.Errorf("unknown route %q", s.Route)
-	}
-	u, err := route.URLPath(s.Parameters...)
+	u, err := r.routes.RoutePath(s.Route, s.Parameters...)
 	if err != nil {
 		return
 	}
-	u.Scheme = "http"
-	u.Host = r.host
+	u.RawQuery = s.Query.Encode()
+	u.Fragment = s.Fragment
+	if !s.Relative {
+		u.Scheme = r.scheme
+		u.Host = r.host
+	}
 	url = u.String()
 	return
 }
 
 // AddURLGenerator is a middleware that adds an URLGenerator in the Request Context
 func AddURLGenerator(router *mux.Router) func(http.Handler) http.Handler {
+	return AddRouteURLGenerator(MuxRoutes(router))
+}
+
+// AddRouteURLGenerator is a middleware that adds an URLGenerator using the RouteLookup in the Request Context
+func AddRouteURLGenerator(routes RouteLookup) func(http.Handler) http.Handler {
+	return AddHostURLGenerator(routes, "")
+}
+
+// AddHostURLGenerator is a middleware that adds an URLGenerator using the RouteLookup in the Request Context,
+// generating absolute URLs with the given host, or the request one if empty.
+func AddHostURLGenerator(routes RouteLookup, host string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			urlHost := host
+			if urlHost == "" {
+				urlHost = r.Host
+			}
 			next.ServeHTTP(w, r.WithContext(
-				context.WithValue(r.Context(), urlGeneratorKey, &RouterURLGenerator{router, r.Host}),
+				context.WithValue(r.Context(), urlGeneratorKey, &RouterURLGenerator{routes, scheme, urlHost}),
 			))
 		})
 	}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestURLGenerator(t *testing.T) {
+
+	var g URLGenerator
+	router := mux.NewRouter()
+	router.Use(AddURLGenerator(router))
+	router.HandleFunc("/articles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		g = URLGeneratorFromContext(r.Context())
+	}).Name("article")
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/articles/1", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	spec := NewURLSpec("article", "id", "42").WithQuery("page", "2").WithFragment("comments")
+	if url := MustURL(g, spec); url != "http://example.com/articles/42?page=2#comments" {
+		t.Errorf("URL: unexpected %s", url)
+	}
+	if url := MustURL(g, NewURLSpec("article", "id", "42").AsRelative()); url != "/articles/42" {
+		t.Errorf("relative URL: unexpected %s", url)
+	}
+	if _, err := g.URL(NewURLSpec("unknown")); err == nil {
+		t.Errorf("unknown route: expected an error")
+	}
+
+	var b strings.Builder
+	tpl := template.Must(template.New("").Funcs(URLFuncs(g)).Parse(`<a href="{{ urlPath "article" "id" "7" }}">`))
+	if err := tpl.Execute(&b, nil); err != nil || b.String() != `<a href="/articles/7">` {
+		t.Errorf("URLFuncs: unexpected %q, %v", b.String(), err)
+	}
+}
+
+func TestPatternRoutes(t *testing.T) {
+
+	routes := NewPatternRoutes()
+	routes.Register("article", "GET example.com/articles/{id:[0-9]+}")
+	routes.Register("file", "/files/*")
+	routes.Register("exact", "/{$}")
+
+	for _, c := range []struct {
+		name     string
+		pairs    []string
+		expected string
+	}{
+		{"article", []string{"id", "42"}, "/articles/42"},
+		{"file", []string{"*", "a b/c"}, "/files/a%20b/c"},
+		{"exact", nil, "/"},
+	} {
+		u, err := routes.RoutePath(c.name, c.pairs...)
+		if err != nil || u.String() != c.expected {
+			t.Errorf("%s: expected %s, got %v, %v", c.name, c.expected, u, err)
+		}
+	}
+	if _, err := routes.RoutePath("article"); err == nil {
+		t.Errorf("missing parameter: expected an error")
+	}
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// VirtualHost configures the handling of the requests to a host.
+type VirtualHost struct {
+	// Handler handles the requests.
+	Handler http.Handler
+
+	// Middlewares wraps the handler.
+	Middlewares Chain
+
+	// Routes, if not nil, is used to add an URLGenerator to the requests.
+	Routes RouteLookup
+
+	// URLHost is the host of the generated URLs. It defaults to the request host.
+	URLHost string
+}
+
+// VirtualHosts dispatches the requests by their Host header. It implements http.Handler.
+//
+// Patterns are either exact host names or wildcards like "*.example.com", which match any subdomain.
+// Exact patterns take precedence, then the longest matching wildcard. Ports are ignored.
+type VirtualHosts struct {
+	// Default handles the requests to unknown hosts. It defaults to http.NotFoundHandler.
+	Default http.Handler
+
+	exact     map[string]http.Handler
+	wildcards []wildcardHost
+	mu        sync.RWMutex
+}
+
+type wildcardHost struct {
+	suffix  string
+	handler http.Handler
+}
+
+// NewVirtualHosts creates an empty VirtualHosts.
+func NewVirtualHosts() *VirtualHosts {
+	return &VirtualHosts{exact: make(map[string]http.Handler)}
+}
+
+// Add registers a virtual host for the pattern, replacing any previous one.
+func (v *VirtualHosts) Add(pattern string, host VirtualHost) {
+	chain := host.Middlewares
+	if host.Routes != nil {
+		chain = NewChain(AddHostURLGenerator(host.Routes, host.URLHost)).Extend(chain)
+	}
+	handler := chain.Then(host.Handler)
+
+	pattern = normalizeHost(pattern)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !strings.HasPrefix(pattern, "*.") {
+		v.exact[pattern] = handler
+		return
+	}
+	suffix := pattern[1:]
+	for i, w := range v.wildcards {
+		if w.suffix == suffix {
+			v.wildcards[i].handler = handler
+			return
+		}
+	}
+	v.wildcards = append(v.wildcards, wildcardHost{suffix, handler})
+	sort.SliceStable(v.wildcards, func(i, j int) bool {
+		return len(v.wildcards[i].suffix) > len(v.wildcards[j].suffix)
+	})
+}
+
+// Handle registers a handler for the pattern, with optional middlewares.
+func (v *VirtualHosts) Handle(pattern string, h http.Handler, middlewares ...Middleware) {
+	v.Add(pattern, VirtualHost{Handler: h, Middlewares: NewChain(middlewares...)})
+}
+
+// Lookup returns the handler of the host, or nil.
+func (v *VirtualHosts) Lookup(host string) http.Handler {
+	host = normalizeHost(host)
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if h, found := v.exact[host]; found {
+		return h
+	}
+	for _, w := range v.wildcards {
+		if strings.HasSuffix(host, w.suffix) && len(host) > len(w.suffix) {
+			return w.handler
+		}
+	}
+	return nil
+}
+
+func (v *VirtualHosts) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := v.Lookup(r.Host)
+	if h == nil {
+		h = v.Default
+	}
+	if h == nil {
+		h = http.NotFoundHandler()
+	}
+	h.ServeHTTP(w, r)
+}
+
+// normalizeHost removes the port and the trailing dot of the host, and lowercases it.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVirtualHosts(t *testing.T) {
+
+	named := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(name)) })
+	}
+	v := NewVirtualHosts()
+	v.Handle("www.example.com", named("www"))
+	v.Handle("*.example.com", named("example"))
+	v.Handle("*.api.example.com", named("api"))
+
+	get := func(host string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = host
+		w := httptest.NewRecorder()
+		v.ServeHTTP(w, r)
+		return w
+	}
+
+	for host, expected := range map[string]string{
+		"WWW.example.com:8080": "www",
+		"blog.example.com.":    "example",
+		"v1.api.example.com":   "api",
+	} {
+		if w := get(host); w.Body.String() != expected {
+			t.Errorf("%s: expected %s, got %d %q", host, expected, w.Code, w.Body.String())
+		}
+	}
+	if w := get("example.com"); w.Code != http.StatusNotFound {
+		t.Errorf("example.com: expected 404, got %d", w.Code)
+	}
+
+	v.Default = named("default")
+	if w := get("other.org"); w.Body.String() != "default" {
+		t.Errorf("other.org: expected default, got %q", w.Body.String())
+	}
+}
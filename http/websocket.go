@@ -0,0 +1,235 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Adirelle/go-libs/logging"
+)
+
+// ErrWebSocketClosed is returned when sending to a closed WebSocket connection.
+var ErrWebSocketClosed = errors.New("websocket connection closed")
+
+// WebSocketConfig configures a WebSocketHub.
+type WebSocketConfig struct {
+	// Upgrader is used to upgrade the HTTP connections.
+	Upgrader websocket.Upgrader
+
+	// PingInterval is the interval between pings. It defaults to 50 seconds.
+	PingInterval time.Duration
+
+	// PongTimeout is how long to wait for a pong before considering the connection dead. It defaults to 60 seconds.
+	PongTimeout time.Duration
+
+	// WriteTimeout is the deadline of each write. It defaults to 10 seconds.
+	WriteTimeout time.Duration
+
+	// SendBuffer is the number of outgoing messages that can be queued. It defaults to 16.
+	SendBuffer int
+
+	// MaxMessageSize, if not zero, is the maximum size of incoming messages.
+	MaxMessageSize int64
+}
+
+func (c *WebSocketConfig) setDefaults() {
+	if c.PingInterval <= 0 {
+		c.PingInterval = 50 * time.Second
+	}
+	if c.PongTimeout <= 0 {
+		c.PongTimeout = 60 * time.Second
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = 10 * time.Second
+	}
+	if c.SendBuffer <= 0 {
+		c.SendBuffer = 16
+	}
+}
+
+// WebSocketHandler handles the messages received on a connection.
+type WebSocketHandler func(c *WebSocketConn, messageType int, data []byte)
+
+// WebSocketHub upgrades requests to WebSocket connections and keeps track of them,
+// so they can be gracefully closed on shutdown, e.g. using Service.RegisterOnShutdown(hub.Shutdown).
+type WebSocketHub struct {
+	conf   WebSocketConfig
+	conns  map[*WebSocketConn]struct{}
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewWebSocketHub creates a WebSocketHub.
+func NewWebSocketHub(conf WebSocketConfig) *WebSocketHub {
+	conf.setDefaults()
+	return &WebSocketHub{conf: conf, conns: make(map[*WebSocketConn]struct{})}
+}
+
+// Handler returns an http.Handler that upgrades the requests and passes the received messages to the handler.
+// The handler is called from the reading goroutine of the connection, one message at a time.
+func (h *WebSocketHub) Handler(handler WebSocketHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		closed := h.closed
+		h.mu.Unlock()
+		if closed {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		ws, err := h.conf.Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The upgrader has already replied.
+			return
+		}
+		c := &WebSocketConn{
+			Conn:    ws,
+			Logger:  logging.FromContext(r.Context(), nil),
+			hub:     h,
+			send:    make(chan outgoingMessage, h.conf.SendBuffer),
+			closing: make(chan int),
+			done:    make(chan struct{}),
+		}
+		h.add(c)
+		defer h.remove(c)
+		go c.writePump()
+		c.readPump(handler)
+	})
+}
+
+// Shutdown closes all the connections, with a "going away" close code, and rejects new ones.
+func (h *WebSocketHub) Shutdown() {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*WebSocketConn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+	for _, c := range conns {
+		c.close(websocket.CloseGoingAway)
+	}
+}
+
+// Len returns the number of open connections.
+func (h *WebSocketHub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+func (h *WebSocketHub) add(c *WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.conns[c] = struct{}{}
+}
+
+func (h *WebSocketHub) remove(c *WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, c)
+}
+
+type outgoingMessage struct {
+	messageType int
+	data        []byte
+}
+
+// WebSocketConn is a WebSocket connection managed by a WebSocketHub.
+// Messages must be sent using Send, the underlying connection must not be written to directly.
+type WebSocketConn struct {
+	*websocket.Conn
+
+	// Logger is the Logger of the upgraded request, if any.
+	Logger logging.Logger
+
+	hub       *WebSocketHub
+	send      chan outgoingMessage
+	closing   chan int
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Send queues a message, waiting if the send buffer is full.
+func (c *WebSocketConn) Send(messageType int, data []byte) error {
+	select {
+	case c.send <- outgoingMessage{messageType, data}:
+		return nil
+	case <-c.done:
+		return ErrWebSocketClosed
+	}
+}
+
+// Close gracefully closes the connection, with a normal close code.
+func (c *WebSocketConn) Close() {
+	c.close(websocket.CloseNormalClosure)
+}
+
+func (c *WebSocketConn) close(code int) {
+	c.closeOnce.Do(func() {
+		select {
+		case c.closing <- code:
+		case <-c.done:
+		}
+	})
+}
+
+func (c *WebSocketConn) readPump(handler WebSocketHandler) {
+	conf := &c.hub.conf
+	if conf.MaxMessageSize > 0 {
+		c.SetReadLimit(conf.MaxMessageSize)
+	}
+	c.SetReadDeadline(time.Now().Add(conf.PongTimeout))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(conf.PongTimeout))
+	})
+	for {
+		messageType, data, err := c.ReadMessage()
+		if err != nil {
+			if c.Logger != nil && websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.Logger.Infow("websocket read error", logging.ErrorKey, err)
+			}
+			break
+		}
+		handler(c, messageType, data)
+	}
+	go c.close(websocket.CloseNormalClosure)
+	<-c.done
+}
+
+func (c *WebSocketConn) writePump() {
+	conf := &c.hub.conf
+	ticker := time.NewTicker(conf.PingInterval)
+	defer func() {
+		ticker.Stop()
+		c.Conn.Close()
+		close(c.done)
+	}()
+	for {
+		select {
+		case msg := <-c.send:
+			c.SetWriteDeadline(time.Now().Add(conf.WriteTimeout))
+			if err := c.WriteMessage(msg.messageType, msg.data); err != nil {
+				if c.Logger != nil {
+					c.Logger.Infow("websocket write error", logging.ErrorKey, err)
+				}
+				return
+			}
+		case <-ticker.C:
+			c.SetWriteDeadline(time.Now().Add(conf.WriteTimeout))
+			if err := c.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case code := <-c.closing:
+			c.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(code, ""),
+				time.Now().Add(conf.WriteTimeout),
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketHub(t *testing.T) {
+
+	hub := NewWebSocketHub(WebSocketConfig{})
+	server := httptest.NewServer(hub.Handler(func(c *WebSocketConn, messageType int, data []byte) {
+		c.Send(messageType, append([]byte("echo: "), data...))
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	if err := ws.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	if _, data, err := ws.ReadMessage(); err != nil || string(data) != "echo: hello" {
+		t.Errorf("expected echo: hello, got %q, %v", data, err)
+	}
+	if n := hub.Len(); n != 1 {
+		t.Errorf("Len: expected 1 connection, got %d", n)
+	}
+
+	hub.Shutdown()
+	if _, _, err := ws.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("Shutdown: expected a going away close, got %v", err)
+	}
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("after Shutdown: expected a 503 response, got %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for hub.Len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Len: expected no connection after Shutdown, got %d", hub.Len())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+//===========================================================================
+// aggregator
+//===========================================================================
+
+// aggregator groups identical errors over an interval. The first occurrence is written with a sample stack trace,
+// the following ones are counted and summarized at the end of the interval.
+type aggregator struct {
+	interval time.Duration
+	clock    zapcore.Clock
+	groups   map[string]*errorGroup
+	stop     chan struct{}
+	done     chan struct{}
+	mu       sync.Mutex
+}
+
+type errorGroup struct {
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+	count  int
+}
+
+func newAggregator(interval time.Duration, clock zapcore.Clock) *aggregator {
+	a := &aggregator{
+		interval: interval,
+		clock:    clock,
+		groups:   make(map[string]*errorGroup),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *aggregator) run() {
+	defer close(a.done)
+	ticker := a.clock.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.flush()
+		case <-a.stop:
+			a.flush()
+			return
+		}
+	}
+}
+
+// fingerprint identifies identical errors: same Logger, level and message, same context fields, and same error.
+func fingerprint(ent zapcore.Entry, context string, fields []zapcore.Field) string {
+	var errors []zapcore.Field
+	for _, f := range fields {
+		if f.Key == ErrorKey {
+			errors = append(errors, f)
+		}
+	}
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s", ent.LoggerName, ent.Level, ent.Message, context, encodeFields(errors))
+}
+
+// encodeFields returns a string representation of the fields.
+func encodeFields(fields []zapcore.Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return fmt.Sprint(enc.Fields)
+}
+
+func (a *aggregator) write(core zapcore.Core, context string, ent zapcore.Entry, fields []zapcore.Field) error {
+	key := fingerprint(ent, context, fields)
+	a.mu.Lock()
+	if g, found := a.groups[key]; found {
+		g.count++
+		a.mu.Unlock()
+		return nil
+	}
+	if ent.Stack == "" {
+		ent.Stack = sampleStack()
+	}
+	a.groups[key] = &errorGroup{core: core, entry: ent, fields: fields}
+	a.mu.Unlock()
+	writeChecked(core, ent, fields)
+	return nil
+}
+
+// writeChecked writes the entry to the cores that actually accept it.
+func writeChecked(core zapcore.Core, ent zapcore.Entry, fields []zapcore.Field) {
+	if ce := core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+func (a *aggregator) flush() {
+	a.mu.Lock()
+	groups := a.groups
+	a.groups = make(map[string]*errorGroup)
+	a.mu.Unlock()
+
+	for _, g := range groups {
+		if g.count == 0 {
+			continue
+		}
+		ent := g.entry
+		ent.Time = a.clock.Now()
+		ent.Message = fmt.Sprintf("%s (repeated %d times in %s)", ent.Message, g.count, a.interval)
+		fields := append(g.fields[:len(g.fields):len(g.fields)], zap.Int("repeated", g.count))
+		writeChecked(g.core, ent, fields)
+	}
+}
+
+// internalPrefixes lists the prefixes of the functions omitted from the sample stack traces.
+var internalPrefixes = []string{
+	"go.uber.org/zap",
+	reflect.TypeOf(logger{}).PkgPath() + ".(*logger)",
+	reflect.TypeOf(logger{}).PkgPath() + ".(*aggregat",
+}
+
+// sampleStack returns the stack trace of the caller of the Logger.
+func sampleStack() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	var lines []string
+	for {
+		frame, more := frames.Next()
+		if len(lines) > 0 || !hasAnyPrefix(frame.Function, internalPrefixes) {
+			lines = append(lines, fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+		}
+		if !more {
+			return strings.Join(lines, "\n")
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops the aggregator, after writing the pending summaries.
+func (a *aggregator) Close() error {
+	close(a.stop)
+	<-a.done
+	return nil
+}
+
+//===========================================================================
+// aggregatingCore
+//===========================================================================
+
+type aggregatingCore struct {
+	zapcore.Core
+	agg *aggregator
+	// context is the representation of the fields added by With, used to fingerprint the errors.
+	context string
+}
+
+func (c *aggregatingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < zap.ErrorLevel {
+		return c.Core.Check(ent, ce)
+	}
+	if c.Enabled(ent.Level) {
+		ce = ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *aggregatingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &aggregatingCore{c.Core.With(fields), c.agg, c.context + encodeFields(fields)}
+}
+
+func (c *aggregatingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.agg.write(c.Core, c.context, ent, fields)
+}
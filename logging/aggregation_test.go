@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// messages extracts the messages of the console lines, skipping fields and stack traces.
+func messages(lines []string) (msgs []string) {
+	for _, line := range lines {
+		if parts := strings.Split(line, "\t"); len(parts) >= 3 && parts[0] != "" {
+			msgs = append(msgs, parts[2])
+		}
+	}
+	return
+}
+
+func TestAggregation(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Aggregation = time.Hour
+	f, output := captureBuild(t, c)
+
+	log := f.Get("test")
+	for i := 0; i < 3; i++ {
+		log.Error("connection lost")
+	}
+	log.Warn("retrying")
+
+	expected := []string{"connection lost", "retrying"}
+	if msgs := messages(output()); !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("before Close: expected %v, got %v", expected, msgs)
+	}
+
+	// The summaries are written on Close.
+	f.Close()
+	expected = append(expected, "connection lost (repeated 2 times in 1h0m0s)")
+	if msgs := messages(output()); !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("after Close: expected %v, got %v", expected, msgs)
+	}
+}
+
+func TestAggregationFingerprint(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Aggregation = time.Hour
+	f, output := captureBuild(t, c)
+
+	log := f.Get("test")
+	for i := 0; i < 2; i++ {
+		log.With("backend", "db1").Error("connection lost")
+		log.With("backend", "db2").Error("connection lost")
+		log.Errorw("query failed", ErrorKey, "timeout")
+		log.Errorw("query failed", ErrorKey, "syntax error")
+	}
+	f.Close()
+
+	expected := []string{
+		"connection lost", "connection lost", "query failed", "query failed",
+		"connection lost (repeated 1 times in 1h0m0s)", "connection lost (repeated 1 times in 1h0m0s)",
+		"query failed (repeated 1 times in 1h0m0s)", "query failed (repeated 1 times in 1h0m0s)",
+	}
+	msgs := messages(output())
+	if len(msgs) == len(expected) {
+		// The summaries are written in no particular order.
+		sort.Strings(msgs[4:])
+	}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %v, got %v", expected, msgs)
+	}
+}
+
+func TestAggregationStack(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Aggregation = time.Hour
+	f, output := captureBuild(t, c)
+
+	f.Get("test").Error("connection lost")
+	f.Get("test").Error("connection lost")
+	f.Close()
+
+	lines := output()
+	var stacks int
+	for i, line := range lines {
+		if strings.Contains(line, "connection lost") {
+			if i+1 >= len(lines) || !strings.HasSuffix(lines[i+1], ".TestAggregationStack") {
+				t.Errorf("expected a stack trace starting at the test after %q, got %q", line, lines[i+1:])
+			}
+			stacks++
+		}
+	}
+	if stacks != 2 {
+		t.Errorf("expected the entry and its summary, got %q", lines)
+	}
+
+	// Without aggregation, the errors have no stack trace.
+	f, output = captureBuild(t, DefaultConfig())
+	f.Get("test").Error("connection lost")
+	if lines := output(); len(lines) != 1 {
+		t.Errorf("without aggregation: expected a single line, got %q", lines)
+	}
+}
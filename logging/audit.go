@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AuditLoggerName is the name of the root of the audit Logger subtree.
+const AuditLoggerName = Name("audit")
+
+// Audit returns a Logger of the audit subtree.
+//
+// Audit Loggers ignore levels and filters, write to their dedicated output synchronously,
+// and panic if an entry cannot be written.
+func (f *Factory) Audit(s string) Logger {
+	return f.get(AuditLoggerName.Child(string(Clean(s))))
+}
+
+func (f *Factory) buildAuditCore(encConf zapcore.EncoderConfig) error {
+	path := f.AuditPath
+	if path == "" {
+		path = "stderr"
+	}
+	s, err := openSink(path)
+	if err != nil {
+		return err
+	}
+	if encConf.TimeKey == "" {
+		encConf.TimeKey = "ts"
+	}
+	encConf.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	f.sinks = append(f.sinks, s)
+	f.auditCore = &auditCore{zapcore.NewCore(zapcore.NewJSONEncoder(encConf), s, zap.DebugLevel)}
+	return nil
+}
+
+//===========================================================================
+// auditCore
+//===========================================================================
+
+type auditCore struct {
+	zapcore.Core
+}
+
+func (c *auditCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *auditCore) With(fields []zapcore.Field) zapcore.Core {
+	return &auditCore{c.Core.With(fields)}
+}
+
+func (c *auditCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	if err == nil {
+		err = c.Core.Sync()
+	}
+	if err != nil {
+		panic(fmt.Errorf("cannot write audit entry %q: %s", ent.Message, err))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAudit(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Level[RootLoggerName] = zap.ErrorLevel
+	c.AuditPath = filepath.Join(t.TempDir(), "audit.log")
+	f, output := captureBuild(t, c)
+
+	f.Audit("login").Infow("user logged in", "user", "alice")
+	f.Get("test").Info("not audited")
+
+	if lines := output(); len(lines) != 0 {
+		t.Errorf("expected no regular output, got %q", lines)
+	}
+
+	// Audit entries are written synchronously, regardless of levels.
+	data, err := ioutil.ReadFile(c.AuditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := string(data)
+	for _, expected := range []string{`"logger":"audit.login"`, `"msg":"user logged in"`, `"user":"alice"`, `"ts":`} {
+		if !strings.Contains(entry, expected) {
+			t.Errorf("expected %s in %s", expected, entry)
+		}
+	}
+}
+
+func TestNameIn(t *testing.T) {
+
+	cases := []struct {
+		name, ancestor Name
+		expected       bool
+	}{
+		{Clean("audit.login"), AuditLoggerName, true},
+		{AuditLoggerName, AuditLoggerName, true},
+		{Clean("auditor"), AuditLoggerName, false},
+		{Clean("db"), RootLoggerName, true},
+	}
+	for _, c := range cases {
+		if actual := c.name.In(c.ancestor); actual != c.expected {
+			t.Errorf("%q.In(%q): expected %v, got %v", c.name, c.ancestor, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package logging
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Clock is a simple clock abstraction, used to timestamp entries.
+// It has the same method set as cache.Clock, so the same implementations can be used.
+type Clock interface {
+	Now() time.Time
+}
+
+// zapClock adapts a Clock to zapcore.Clock, using real tickers unless the Clock provides its own.
+type zapClock struct {
+	Clock
+}
+
+func newZapClock(c Clock) zapcore.Clock {
+	if c == nil {
+		return zapcore.DefaultClock
+	}
+	if zc, ok := c.(zapcore.Clock); ok {
+		return zc
+	}
+	return zapClock{c}
+}
+
+func (zapClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestClock(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Clock = fixedClock(time.Date(2020, 5, 17, 12, 30, 0, 0, time.UTC))
+	c.TimeKey = "ts"
+	c.TimeFormat = "2006-01-02T15:04"
+	f, output := captureBuild(t, c)
+
+	f.Get("test").Info("hello")
+
+	expected := []string{"2020-05-17T12:30\tINFO\ttest\thello"}
+	if lines := output(); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
@@ -3,8 +3,10 @@ package logging
 import (
 	"bytes"
 	"fmt"
+	"log"
 	"os"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -26,6 +28,40 @@ type Config struct {
 	Level LoggerLevels
 	Quiet bool
 	Debug bool
+
+	// Outputs lists the outputs of the Loggers.
+	// When it is empty, errors are written to stderr and, unless Quiet is set, other entries to stdout.
+	Outputs []OutputConfig
+
+	// Clock is used to timestamp entries. It defaults to the system clock.
+	Clock Clock
+
+	// AuditPath is the output of the audit Loggers, either "stdout", "stderr" (the default) or a file path.
+	AuditPath string
+
+	// Aggregation is the interval over which identical errors are grouped.
+	// Only the first occurrence is written, with a sample stack trace, followed by a summary at the end of the interval.
+	// Errors are identical when they have the same Logger, message, error field and context fields, so the errors
+	// logged with request-scoped fields, e.g. unique IDs, are not grouped. Errors are not aggregated when it is zero.
+	Aggregation time.Duration
+
+	// Filters selects the messages written by the Loggers, per Logger Name.
+	Filters LoggerFilters
+
+	// TimeKey is the key used for timestamps. Timestamps are omitted when it is empty.
+	TimeKey string
+
+	// TimeFormat selects how timestamps are encoded: "rfc3339" (the default), "rfc3339nano", "iso8601",
+	// "epoch" (seconds), "millis", "nanos", or any custom layout accepted by time.Format.
+	TimeFormat string
+
+	// Recent is the number of the last entries kept in memory, e.g. to be displayed by a debug endpoint.
+	// See Factory.Recent. No entries are kept when it is zero.
+	Recent int
+
+	// ErrorReporting receives the entries at Error level and above, e.g. to send them to an error-reporting service.
+	// The panics recovered by Go and CatchPanicTo are reported with their stack trace.
+	ErrorReporting zapcore.Core
 }
 
 // DefaultConfig returns a default configuration
@@ -35,28 +71,65 @@ func DefaultConfig() Config {
 	return c
 }
 
-// Build creates the Logger Factory
+// Build creates the Logger Factory.
+// It panics if an output cannot be built.
 func (c *Config) Build() *Factory {
 	encConf := zap.NewProductionEncoderConfig()
 	encConf.EncodeLevel = zapcore.CapitalLevelEncoder
-	encConf.TimeKey = ""
+	encConf.TimeKey = c.TimeKey
+	encConf.EncodeTime = timeEncoder(c.TimeFormat)
 
-	f := &Factory{Config: *c, loggers: make(map[Name]Logger)}
+	f := &Factory{Config: *c, loggers: make(map[Name]*logger)}
+	// The Levels are copied, so SetLevel does not alter the Config.
+	f.Level = make(LoggerLevels, len(c.Level))
+	for name, level := range c.Level {
+		f.Level[name] = level
+	}
 
+	clock := newZapClock(c.Clock)
+	f.options = append(f.options, zap.WithClock(clock))
 	if c.Debug {
 		f.options = append(f.options, zap.Development(), zap.AddCaller())
 	}
-	consoleEnc := zapcore.NewConsoleEncoder(encConf)
 
-	f.cores = append(
-		f.cores,
-		zapcore.NewCore(consoleEnc, zapcore.AddSync(os.Stderr), zap.ErrorLevel),
-	)
-	if !c.Quiet {
-		f.cores = append(
-			f.cores,
-			zapcore.NewCore(consoleEnc, zapcore.AddSync(os.Stdout), not{zap.ErrorLevel}),
-		)
+	outputs := c.Outputs
+	if len(outputs) == 0 {
+		outputs = defaultOutputs(c.Quiet)
+	}
+	for _, o := range outputs {
+		core, s, err := o.build(encConf)
+		if err != nil {
+			f.Close()
+			log.Panicf("cannot build logging output: %s", err)
+		}
+		if s != nil {
+			f.sinks = append(f.sinks, s)
+		}
+		f.cores = append(f.cores, core)
+	}
+
+	if c.Recent > 0 {
+		f.recent = newRecentSink(c.Recent)
+		f.cores = append(f.cores, zapcore.NewCore(zapcore.NewJSONEncoder(encConf), f.recent, zap.DebugLevel))
+	}
+
+	if c.ErrorReporting != nil {
+		core, err := zapcore.NewIncreaseLevelCore(c.ErrorReporting, zap.ErrorLevel)
+		if err != nil {
+			// The core is already more restrictive.
+			core = c.ErrorReporting
+		}
+		f.cores = append(f.cores, core)
+	}
+
+	if err := f.buildAuditCore(encConf); err != nil {
+		f.Close()
+		log.Panicf("cannot build audit output: %s", err)
+	}
+
+	if c.Aggregation > 0 {
+		f.aggregator = newAggregator(c.Aggregation, clock)
+		f.cores = []zapcore.Core{&aggregatingCore{Core: zapcore.NewTee(f.cores...), agg: f.aggregator}}
 	}
 
 	zLogger := f.Get(RootLoggerAlias).(*logger).SugaredLogger.Desugar()
@@ -65,6 +138,25 @@ func (c *Config) Build() *Factory {
 	return f
 }
 
+func timeEncoder(format string) zapcore.TimeEncoder {
+	switch format {
+	case "", "rfc3339":
+		return zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		return zapcore.RFC3339NanoTimeEncoder
+	case "iso8601":
+		return zapcore.ISO8601TimeEncoder
+	case "epoch":
+		return zapcore.EpochTimeEncoder
+	case "millis":
+		return zapcore.EpochMillisTimeEncoder
+	case "nanos":
+		return zapcore.EpochNanosTimeEncoder
+	default:
+		return zapcore.TimeEncoderOfLayout(format)
+	}
+}
+
 //===========================================================================
 // Name
 //===========================================================================
@@ -95,6 +187,11 @@ func (n Name) Parent() Name {
 	return Name(n[:dot])
 }
 
+// In indicates whether the Name is the given one or one of its descendants.
+func (n Name) In(ancestor Name) bool {
+	return ancestor == RootLoggerName || n == ancestor || strings.HasPrefix(string(n), string(ancestor)+".")
+}
+
 // Child returns the full Name of a child Logger.
 func (n Name) Child(s string) Name {
 	if s == "" {
@@ -154,22 +251,48 @@ func (l LoggerLevels) Set(value string) (err error) {
 			name = strings.Trim(parts[0], " ")
 			value = strings.Trim(parts[1], " ")
 		}
+		if name == "" && value == "" {
+			continue
+		}
 		lvl := zapcore.DebugLevel
-		err = (&lvl).Set(value)
-		if err != nil {
-			return
+		if err = (&lvl).Set(value); err != nil {
+			if name == "" {
+				name = RootLoggerAlias
+			}
+			return fmt.Errorf("invalid level %q for logger %q, expected one of %s", value, name, levelNames)
 		}
 		l[Clean(name)] = lvl
 	}
 	return
 }
 
+const levelNames = "debug, info, warn, error, dpanic, panic, fatal"
+
+// FromEnv parses the environment variable with the given key, using the same syntax as Set.
+// It does nothing if the variable is not set or empty.
+func (l LoggerLevels) FromEnv(key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	if err := l.Set(value); err != nil {
+		return fmt.Errorf("%s: %s", key, err)
+	}
+	return nil
+}
+
 // Resolve returns the Level to use for the Named Logger.
 func (l LoggerLevels) Resolve(name Name) zapcore.Level {
+	level, _ := l.Lookup(name)
+	return level
+}
+
+// Lookup returns the Level to use for the Named Logger, and the Name of the entry it comes from.
+func (l LoggerLevels) Lookup(name Name) (zapcore.Level, Name) {
 	for cur := name; cur != RootLoggerName; cur = cur.Parent() {
 		if level, found := l[cur]; found {
-			return level
+			return level, cur
 		}
 	}
-	return l[RootLoggerName]
+	return l[RootLoggerName], RootLoggerName
 }
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeFormat(t *testing.T) {
+
+	f, lines := captureBuild(t, DefaultConfig())
+	f.Get("test").Info("untimed")
+
+	c := DefaultConfig()
+	c.TimeKey = "ts"
+	c.TimeFormat = "2006-01"
+	f, timedLines := captureBuild(t, c)
+	f.Get("test").Info("timed")
+
+	if l := lines(); len(l) != 1 || !strings.HasPrefix(l[0], "INFO\t") {
+		t.Errorf("no TimeKey: expected no timestamp, got %q", l)
+	}
+	if l := timedLines(); len(l) != 1 || !strings.HasPrefix(l[0], time.Now().Format("2006-01")+"\tINFO\t") {
+		t.Errorf("custom layout: expected the current month, got %q", l)
+	}
+}
+
+func TestLevelsFromEnv(t *testing.T) {
+
+	t.Setenv("TEST_LOG_LEVELS", "warn, db:debug,")
+	levels := LoggerLevels{}
+	if err := levels.FromEnv("TEST_LOG_LEVELS"); err != nil {
+		t.Fatalf("FromEnv: unexpected error %v", err)
+	}
+	if levels[RootLoggerName] != WarnLevel || levels[Clean("db")] != DebugLevel {
+		t.Errorf("FromEnv: expected all:warn,db:debug, got %s", levels)
+	}
+
+	t.Setenv("TEST_LOG_LEVELS", "db:verbose")
+	err := levels.FromEnv("TEST_LOG_LEVELS")
+	if err == nil || !strings.Contains(err.Error(), `invalid level "verbose" for logger "db"`) {
+		t.Errorf("FromEnv: expected an invalid level error, got %v", err)
+	}
+	if err := levels.FromEnv("TEST_LOG_UNSET"); err != nil {
+		t.Errorf("FromEnv: unexpected error %v", err)
+	}
+}
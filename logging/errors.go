@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorFields returns structured fields describing the error: its message, its type,
+// the messages of the wrapped errors and its detailed representation, if any (e.g. stack traces).
+func ErrorFields(err error) []interface{} {
+	if err == nil {
+		return nil
+	}
+	fields := []interface{}{
+		ErrorKey, err.Error(),
+		"errorType", fmt.Sprintf("%T", err),
+	}
+	var chain []string
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		chain = append(chain, fmt.Sprintf("%T: %s", cause, cause))
+	}
+	if len(chain) > 0 {
+		fields = append(fields, "errorChain", chain)
+	}
+	if _, isFormatter := err.(fmt.Formatter); isFormatter {
+		if verbose := fmt.Sprintf("%+v", err); verbose != err.Error() {
+			fields = append(fields, "errorVerbose", verbose)
+		}
+	}
+	return fields
+}
+
+func (l *logger) ErrorE(err error, msg string, args ...interface{}) {
+	l.Errorw(msg, append(ErrorFields(err), args...)...)
+}
+
+func (l *logger) WarnE(err error, msg string, args ...interface{}) {
+	l.Warnw(msg, append(ErrorFields(err), args...)...)
+}
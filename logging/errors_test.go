@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrorFields(t *testing.T) {
+
+	if fields := ErrorFields(nil); fields != nil {
+		t.Errorf("nil error: expected no fields, got %v", fields)
+	}
+
+	err := fmt.Errorf("loading config: %w", os.ErrNotExist)
+	expected := []interface{}{
+		"error", "loading config: file does not exist",
+		"errorType", "*fmt.wrapError",
+		"errorChain", []string{"*errors.errorString: file does not exist"},
+	}
+	if fields := ErrorFields(err); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("wrapped error: expected %v, got %v", expected, fields)
+	}
+}
+
+func TestErrorE(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Outputs = []OutputConfig{{Path: "stdout", Encoding: "json"}}
+	f, output := captureBuild(t, c)
+
+	f.Get("test").ErrorE(errors.New("boom"), "request failed", "path", "/")
+
+	lines := output()
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %q", lines)
+	}
+	for _, expected := range []string{`"msg":"request failed"`, `"error":"boom"`, `"errorType":"*errors.errorString"`, `"path":"/"`} {
+		if !strings.Contains(lines[0], expected) {
+			t.Errorf("expected %s in %s", expected, lines[0])
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package logging
 
 import (
+	"context"
+	"sort"
 	"sync"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -14,10 +17,14 @@ import (
 // Factory is used to build Loggers.
 type Factory struct {
 	Config
-	cores   []zapcore.Core
-	options []zap.Option
-	loggers map[Name]Logger
-	mu      sync.Mutex
+	cores      []zapcore.Core
+	sinks      []sink
+	options    []zap.Option
+	loggers    map[Name]*logger
+	aggregator *aggregator
+	auditCore  zapcore.Core
+	recent     *recentSink
+	mu         sync.Mutex
 }
 
 // Get returns a Logger for the given name.
@@ -31,14 +38,131 @@ func (f *Factory) get(name Name) Logger {
 	if logger, exists := f.loggers[name]; exists {
 		return logger
 	}
-	level := f.Level.Resolve(name)
-	core := &leveledCore{level, f.cores}
+	level := zap.NewAtomicLevelAt(f.Level.Resolve(name))
+	var core zapcore.Core = &leveledCore{level, f.cores}
+	if name.In(AuditLoggerName) {
+		core = f.auditCore
+	} else if filter := f.Filters.Resolve(name); filter != nil {
+		core = &filteringCore{core, filter}
+	}
 	zLogger := zap.New(core, f.options...).Named(name.String())
-	logger := &logger{f, name, zLogger.Sugar()}
+	logger := &logger{f, name, level, zLogger.Sugar()}
 	f.loggers[name] = logger
 	return logger
 }
 
+// SetLevel sets the Level of the Named Logger and of its children that do not have their own Level.
+// It applies to the Loggers that have already been created.
+func (f *Factory) SetLevel(s string, level zapcore.Level) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Level == nil {
+		f.Level = make(LoggerLevels)
+	}
+	f.Level[Clean(s)] = level
+	f.propagateLevels()
+}
+
+// UnsetLevel removes the Level of the Named Logger, which then inherits the Level of its parent.
+// The root Logger Level cannot be removed.
+func (f *Factory) UnsetLevel(s string) {
+	name := Clean(s)
+	if name == RootLoggerName {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.Level, name)
+	f.propagateLevels()
+}
+
+// SetLevels replaces all the Levels, e.g. on configuration reload.
+// It applies to the Loggers that have already been created.
+func (f *Factory) SetLevels(levels LoggerLevels) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Level = make(LoggerLevels, len(levels))
+	for name, level := range levels {
+		f.Level[name] = level
+	}
+	f.propagateLevels()
+}
+
+func (f *Factory) propagateLevels() {
+	for name, logger := range f.loggers {
+		logger.level.SetLevel(f.Level.Resolve(name))
+	}
+}
+
+// Sync flushes any buffered log entries of all the Loggers.
+func (f *Factory) Sync() (err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sync()
+}
+
+func (f *Factory) sync() (err error) {
+	for _, core := range f.cores {
+		err = multierr.Append(err, core.Sync())
+	}
+	return
+}
+
+// Close flushes all the Loggers and releases the resources held by their outputs.
+// The Loggers must not be used afterwards.
+func (f *Factory) Close() (err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.aggregator != nil {
+		err = f.aggregator.Close()
+		f.aggregator = nil
+	}
+	err = multierr.Append(err, f.sync())
+	for _, s := range f.sinks {
+		err = multierr.Append(err, s.Close())
+	}
+	f.sinks = nil
+	return
+}
+
+// Stop closes the Factory. It implements dic.Stopper, so a Factory built by a container is closed
+// when the container is stopped.
+func (f *Factory) Stop(context.Context) error {
+	return f.Close()
+}
+
+//===========================================================================
+// LoggerInfo
+//===========================================================================
+
+// LoggerInfo describes a Logger created by the Factory.
+type LoggerInfo struct {
+	// The Logger name.
+	Name Name
+
+	// The effective Level of the Logger.
+	Level zapcore.Level
+
+	// The name of the LoggerLevels entry the Level comes from.
+	Source Name
+
+	// Indicates the Level is inherited from a parent Logger.
+	Inherited bool
+}
+
+// Loggers returns informations about all the Loggers created so far, sorted by name.
+func (f *Factory) Loggers() []LoggerInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	infos := make([]LoggerInfo, 0, len(f.loggers))
+	for name, logger := range f.loggers {
+		_, source := f.Level.Lookup(name)
+		infos = append(infos, LoggerInfo{name, logger.level.Level(), source, source != name})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
 //===========================================================================
 // leveledCore
 //===========================================================================
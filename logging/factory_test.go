@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// captureBuild builds the Factory with the standard outputs redirected to a file,
+// and returns a function reading the lines written so far.
+func captureBuild(t *testing.T, c Config) (*Factory, func() []string) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "output.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = file, file
+	f := c.Build()
+	os.Stdout, os.Stderr = stdout, stderr
+	t.Cleanup(func() {
+		f.Close()
+		file.Close()
+	})
+
+	return f, func() []string {
+		data, err := ioutil.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+}
+
+func TestLoggers(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Level[Clean("db")] = zap.DebugLevel
+	f := c.Build()
+
+	f.Get("db.sql")
+	f.Get("http")
+
+	expected := []LoggerInfo{
+		{RootLoggerName, zap.InfoLevel, RootLoggerName, false},
+		{Clean("db.sql"), zap.DebugLevel, Clean("db"), true},
+		{Clean("http"), zap.InfoLevel, RootLoggerName, true},
+	}
+	if infos := f.Loggers(); !reflect.DeepEqual(infos, expected) {
+		t.Errorf("Loggers: expected %v, got %v", expected, infos)
+	}
+}
+
+func TestSyncClose(t *testing.T) {
+
+	f, lines := captureBuild(t, DefaultConfig())
+	log := f.Get("test")
+	log.Info("hello")
+	log.Error("boom")
+
+	if err := f.Sync(); err != nil {
+		t.Errorf("Sync: unexpected error %v", err)
+	}
+	if l := lines(); len(l) != 2 || !strings.HasSuffix(l[0], "\thello") || !strings.HasSuffix(l[1], "\tboom") {
+		t.Errorf("expected hello and boom, got %q", l)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Errorf("Close: unexpected error %v", err)
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+
+	f, lines := captureBuild(t, DefaultConfig())
+	child := f.Get("db.sql")
+	child.Debug("hidden")
+
+	f.SetLevel("db", zap.DebugLevel)
+	child.Debug("shown")
+	if l := lines(); len(l) != 1 || !strings.HasSuffix(l[0], "\tshown") {
+		t.Errorf("SetLevel: expected shown, got %q", l)
+	}
+
+	f.UnsetLevel("db")
+	child.Debug("hidden")
+	f.SetLevels(LoggerLevels{RootLoggerName: zap.DebugLevel})
+	child.Debug("shown again")
+	if l := lines(); len(l) != 2 || !strings.HasSuffix(l[1], "\tshown again") {
+		t.Errorf("SetLevels: expected shown again, got %q", l)
+	}
+}
+
+func TestSetLevelKeepsConfig(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Outputs = []OutputConfig{{Path: filepath.Join(t.TempDir(), "test.log")}}
+	f := c.Build()
+	defer f.Close()
+
+	f.SetLevel("test", zap.DebugLevel)
+	if _, found := c.Level[Clean("test")]; found {
+		t.Errorf("Config.Level: expected no level for test, got %v", c.Level)
+	}
+	if l := f.Get("test"); !l.(*logger).level.Enabled(zap.DebugLevel) {
+		t.Errorf("Get: expected test to be at debug level")
+	}
+}
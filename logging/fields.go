@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Field names used across the packages.
+const (
+	ErrorKey    = "error"
+	DurationKey = "elapsed"
+	RemoteKey   = "remote"
+	HostKey     = "host"
+	MethodKey   = "method"
+	URLKey      = "url"
+	CacheKey    = "cache"
+)
+
+// WithError returns a Logger with fields describing the error, as returned by ErrorFields.
+func WithError(l Logger, err error) Logger {
+	return l.With(ErrorFields(err)...)
+}
+
+// WithDuration returns a Logger with the given duration.
+func WithDuration(l Logger, d time.Duration) Logger {
+	return l.With(DurationKey, d.String())
+}
+
+// RequestFields returns fields describing the HTTP request.
+func RequestFields(r *http.Request) []interface{} {
+	return []interface{}{
+		RemoteKey, r.RemoteAddr,
+		HostKey, r.Host,
+		MethodKey, r.Method,
+		URLKey, r.URL,
+	}
+}
+
+// WithRequest returns a Logger with fields describing the HTTP request.
+func WithRequest(l Logger, r *http.Request) Logger {
+	return l.With(RequestFields(r)...)
+}
+
+// WithCache returns a Logger with the name of the cache, typically a cache.Cache.
+func WithCache(l Logger, c fmt.Stringer) Logger {
+	return l.With(CacheKey, c.String())
+}
+
+// LazyValue is a field value computed only when the entry is actually written.
+type LazyValue func() interface{}
+
+// Lazy wraps a function to compute a field value only when the entry is enabled and written,
+// so expensive computations cost nothing at disabled levels.
+func Lazy(f func() interface{}) LazyValue {
+	return LazyValue(f)
+}
+
+// MarshalJSON implements json.Marshaler, which is used by the encoders.
+func (f LazyValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f())
+}
+
+// Format implements fmt.Formatter.
+func (f LazyValue) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), f())
+}
@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFields(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Outputs = []OutputConfig{{Path: "stdout", Encoding: "json"}}
+	f, output := captureBuild(t, c)
+
+	r := httptest.NewRequest("GET", "http://example.com/path", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	log := WithDuration(WithRequest(f.Get("test"), r), 1500*time.Millisecond)
+	log.Info("served")
+
+	lines := output()
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %q", lines)
+	}
+	for _, expected := range []string{
+		`"remote":"192.0.2.1:1234"`,
+		`"host":"example.com"`,
+		`"method":"GET"`,
+		`"url":"http://example.com/path"`,
+		`"elapsed":"1.5s"`,
+	} {
+		if !strings.Contains(lines[0], expected) {
+			t.Errorf("expected %s in %s", expected, lines[0])
+		}
+	}
+}
+
+func TestLazy(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Outputs = []OutputConfig{{Path: "stdout", Encoding: "json"}}
+	f, output := captureBuild(t, c)
+
+	calls := 0
+	value := Lazy(func() interface{} {
+		calls++
+		return []int{1, 2}
+	})
+	log := f.Get("test")
+	log.Debugw("hidden", "ids", value)
+	if calls != 0 {
+		t.Errorf("disabled level: expected no call, got %d", calls)
+	}
+	log.Infow("shown", "ids", value)
+	if calls != 1 {
+		t.Errorf("enabled level: expected 1 call, got %d", calls)
+	}
+
+	lines := output()
+	if len(lines) != 1 || !strings.Contains(lines[0], `"ids":[1,2]`) {
+		t.Errorf(`expected "ids":[1,2] in %q`, lines)
+	}
+}
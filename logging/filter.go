@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"regexp"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+//===========================================================================
+// Filter
+//===========================================================================
+
+// Filter selects the messages a Logger actually writes.
+// Errors and more severe entries are never filtered out.
+type Filter struct {
+	// When not nil, only messages matching Include are written.
+	Include *regexp.Regexp
+
+	// When not nil, messages matching Exclude are discarded.
+	Exclude *regexp.Regexp
+}
+
+// NewFilter compiles a Filter from the given regular expressions. Empty expressions are ignored.
+func NewFilter(include, exclude string) (f *Filter, err error) {
+	f = &Filter{}
+	if include != "" {
+		if f.Include, err = regexp.Compile(include); err != nil {
+			return nil, err
+		}
+	}
+	if exclude != "" {
+		if f.Exclude, err = regexp.Compile(exclude); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// Accept indicates whether the entry should be written.
+func (f *Filter) Accept(ent zapcore.Entry) bool {
+	if ent.Level >= zap.ErrorLevel {
+		return true
+	}
+	if f.Include != nil && !f.Include.MatchString(ent.Message) {
+		return false
+	}
+	return f.Exclude == nil || !f.Exclude.MatchString(ent.Message)
+}
+
+//===========================================================================
+// LoggerFilters
+//===========================================================================
+
+// LoggerFilters is a map of Filters for Logger Names
+type LoggerFilters map[Name]*Filter
+
+// Resolve returns the Filter to use for the Named Logger, or nil if there is none.
+func (l LoggerFilters) Resolve(name Name) *Filter {
+	for cur := name; cur != RootLoggerName; cur = cur.Parent() {
+		if filter, found := l[cur]; found {
+			return filter
+		}
+	}
+	return l[RootLoggerName]
+}
+
+//===========================================================================
+// filteringCore
+//===========================================================================
+
+type filteringCore struct {
+	zapcore.Core
+	filter *Filter
+}
+
+func (c *filteringCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.filter.Accept(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func (c *filteringCore) With(fields []zapcore.Field) zapcore.Core {
+	return &filteringCore{c.Core.With(fields), c.filter}
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilters(t *testing.T) {
+
+	filter, err := NewFilter("^query", "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := DefaultConfig()
+	c.Filters = LoggerFilters{Clean("db"): filter}
+	f, lines := captureBuild(t, c)
+
+	db := f.Get("db.sql")
+	db.Info("query ok")
+	db.Info("query slow")
+	db.Info("connected")
+	db.Error("connection lost")
+	f.Get("app").Info("connected")
+
+	expected := []string{"\tquery ok", "\tconnection lost", "\tconnected"}
+	l := lines()
+	if len(l) != len(expected) {
+		t.Fatalf("expected %d lines, got %q", len(expected), l)
+	}
+	for i, suffix := range expected {
+		if !strings.HasSuffix(l[i], suffix) {
+			t.Errorf("line %d: expected %q, got %q", i, suffix, l[i])
+		}
+	}
+
+	if _, err := NewFilter("(", ""); err == nil {
+		t.Errorf("NewFilter: expected an error")
+	}
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/journal"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrJournaldUnavailable is returned when a journald output is configured but journald is not available.
+var ErrJournaldUnavailable = errors.New("journald is not available")
+
+//===========================================================================
+// journaldCore
+//===========================================================================
+
+// journaldCore sends entries to the systemd journal, fields being sent as journal fields.
+type journaldCore struct {
+	zapcore.LevelEnabler
+	fields []zapcore.Field
+}
+
+func newJournaldCore(levels zapcore.LevelEnabler) (zapcore.Core, error) {
+	if !journal.Enabled() {
+		return nil, ErrJournaldUnavailable
+	}
+	return &journaldCore{LevelEnabler: levels}, nil
+}
+
+func (c *journaldCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		ce = ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *journaldCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(append(all, c.fields...), fields...)
+	return &journaldCore{c.LevelEnabler, all}
+}
+
+func (c *journaldCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	vars := make(map[string]string, len(enc.Fields)+5)
+	for k, v := range enc.Fields {
+		vars[journalKey(k)] = fmt.Sprint(v)
+	}
+	if ent.LoggerName != "" {
+		vars["LOGGER"] = ent.LoggerName
+	}
+	if ent.Caller.Defined {
+		vars["CODE_FILE"] = ent.Caller.File
+		vars["CODE_LINE"] = strconv.Itoa(ent.Caller.Line)
+		if ent.Caller.Function != "" {
+			vars["CODE_FUNC"] = ent.Caller.Function
+		}
+	}
+	if ent.Stack != "" {
+		vars["STACKTRACE"] = ent.Stack
+	}
+
+	return journal.Send(ent.Message, journalPriority(ent.Level), vars)
+}
+
+func (c *journaldCore) Sync() error {
+	return nil
+}
+
+func journalPriority(l zapcore.Level) journal.Priority {
+	switch l {
+	case zap.DebugLevel:
+		return journal.PriDebug
+	case zap.InfoLevel:
+		return journal.PriInfo
+	case zap.WarnLevel:
+		return journal.PriWarning
+	case zap.ErrorLevel:
+		return journal.PriErr
+	case zap.DPanicLevel, zap.PanicLevel:
+		return journal.PriCrit
+	default:
+		return journal.PriAlert
+	}
+}
+
+// journalKey converts a field name to a valid journal field name,
+// i.e. uppercase letters, digits and underscores, not starting with an underscore.
+func journalKey(k string) string {
+	key := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, k)
+	key = strings.TrimLeft(key, "_")
+	if key == "" || key[0] >= '0' && key[0] <= '9' {
+		key = "F_" + key
+	}
+	return key
+}
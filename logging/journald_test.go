@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/journal"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestJournalKey(t *testing.T) {
+
+	cases := map[string]string{
+		"user":        "USER",
+		"http.status": "HTTP_STATUS",
+		"_private":    "PRIVATE",
+		"2fa":         "F_2FA",
+		"":            "F_",
+	}
+	for field, expected := range cases {
+		if actual := journalKey(field); actual != expected {
+			t.Errorf("journalKey(%q): expected %q, got %q", field, expected, actual)
+		}
+	}
+}
+
+func TestJournalPriority(t *testing.T) {
+
+	cases := map[zapcore.Level]journal.Priority{
+		zap.DebugLevel: journal.PriDebug,
+		zap.WarnLevel:  journal.PriWarning,
+		zap.ErrorLevel: journal.PriErr,
+		zap.PanicLevel: journal.PriCrit,
+		zap.FatalLevel: journal.PriAlert,
+	}
+	for level, expected := range cases {
+		if actual := journalPriority(level); actual != expected {
+			t.Errorf("journalPriority(%s): expected %d, got %d", level, expected, actual)
+		}
+	}
+}
+
+func TestJournaldUnavailable(t *testing.T) {
+
+	if journal.Enabled() {
+		t.Skip("journald is available")
+	}
+	_, _, err := (OutputConfig{Path: "journald"}).build(zap.NewProductionEncoderConfig())
+	if err != ErrJournaldUnavailable {
+		t.Errorf("expected ErrJournaldUnavailable, got %v", err)
+	}
+}
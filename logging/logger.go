@@ -26,6 +26,7 @@ type Logger interface {
 	Error(...interface{})
 	Errorf(string, ...interface{})
 	Errorw(string, ...interface{})
+	ErrorE(error, string, ...interface{})
 
 	Fatal(...interface{})
 	Fatalf(string, ...interface{})
@@ -42,6 +43,7 @@ type Logger interface {
 	Warn(...interface{})
 	Warnf(string, ...interface{})
 	Warnw(string, ...interface{})
+	WarnE(error, string, ...interface{})
 
 	Named(string) Logger
 	With(...interface{}) Logger
@@ -68,6 +70,7 @@ const (
 type logger struct {
 	factory *Factory
 	name    Name
+	level   zap.AtomicLevel
 	*zap.SugaredLogger
 }
 
@@ -76,7 +79,7 @@ func (l *logger) Named(s string) Logger {
 }
 
 func (l *logger) With(args ...interface{}) Logger {
-	return &logger{l.factory, l.name, l.SugaredLogger.With(args...)}
+	return &logger{l.factory, l.name, l.level, l.SugaredLogger.With(args...)}
 }
 
 func (l *logger) Sync() error {
@@ -107,3 +110,30 @@ func (w *writer) Write(b []byte) (int, error) {
 func (w *writer) Close() error {
 	return nil
 }
+
+//===========================================================================
+// PrintfAt
+//===========================================================================
+
+// PrintfAt returns a printf-like function that logs at the given level.
+// It can be used with cache.Spy, cache.LogErrors and alike.
+func PrintfAt(l Logger, level zapcore.Level) func(string, ...interface{}) {
+	switch level {
+	case DebugLevel:
+		return l.Debugf
+	case InfoLevel:
+		return l.Infof
+	case WarnLevel:
+		return l.Warnf
+	case ErrorLevel:
+		return l.Errorf
+	case zap.DPanicLevel:
+		return l.DPanicf
+	case PanicLevel:
+		return l.Panicf
+	case FatalLevel:
+		return l.Fatalf
+	default:
+		return l.Infof
+	}
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestPrintfAt(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Level[RootLoggerName] = zap.DebugLevel
+	f, output := captureBuild(t, c)
+
+	log := f.Get("test")
+	PrintfAt(log, DebugLevel)("cache %s", "miss")
+	PrintfAt(log, WarnLevel)("cache %s", "full")
+	PrintfAt(log, ErrorLevel)("cache %s", "error")
+
+	expected := []string{
+		"DEBUG\ttest\tcache miss",
+		"WARN\ttest\tcache full",
+		"ERROR\ttest\tcache error",
+	}
+	if lines := output(); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+//===========================================================================
+// OutputConfig
+//===========================================================================
+
+// OutputConfig describes one output of the Factory.
+type OutputConfig struct {
+	// Path is either "stdout", "stderr", "journald" or the path of a file, which is created if need be.
+	Path string
+
+	// Encoding is either "console" (the default) or "json". It is ignored by journald.
+	Encoding string
+
+	// Color enables colored levels with the console encoding.
+	Color bool
+
+	// Levels selects the levels written to this output. All levels are written when it is nil.
+	Levels zapcore.LevelEnabler
+}
+
+func defaultOutputs(quiet bool) []OutputConfig {
+	outputs := []OutputConfig{{Path: "stderr", Levels: zap.ErrorLevel}}
+	if !quiet {
+		outputs = append(outputs, OutputConfig{Path: "stdout", Levels: not{zap.ErrorLevel}})
+	}
+	return outputs
+}
+
+func (o OutputConfig) build(encConf zapcore.EncoderConfig) (core zapcore.Core, s sink, err error) {
+	levels := o.Levels
+	if levels == nil {
+		levels = zap.DebugLevel
+	}
+	if o.Path == "journald" {
+		core, err = newJournaldCore(levels)
+		return
+	}
+
+	var enc zapcore.Encoder
+	switch o.Encoding {
+	case "", "console":
+		if o.Color {
+			encConf.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		enc = zapcore.NewConsoleEncoder(encConf)
+	case "json":
+		enc = zapcore.NewJSONEncoder(encConf)
+	default:
+		err = fmt.Errorf("unknown encoding %q for output %q", o.Encoding, o.Path)
+		return
+	}
+	if s, err = openSink(o.Path); err != nil {
+		return
+	}
+	core = zapcore.NewCore(enc, s, levels)
+	return
+}
+
+func openSink(path string) (sink, error) {
+	switch path {
+	case "stdout":
+		return stdSink{os.Stdout}, nil
+	case "stderr":
+		return stdSink{os.Stderr}, nil
+	default:
+		return openFileSink(path)
+	}
+}
+
+//===========================================================================
+// LevelRange
+//===========================================================================
+
+// LevelRange returns a LevelEnabler that enables the levels between min and max, inclusive.
+func LevelRange(min, max zapcore.Level) zapcore.LevelEnabler {
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= min && l <= max
+	})
+}
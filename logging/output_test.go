@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestOutputs(t *testing.T) {
+
+	dir := t.TempDir()
+	c := DefaultConfig()
+	c.Outputs = []OutputConfig{
+		{Path: filepath.Join(dir, "all.log")},
+		{Path: filepath.Join(dir, "warnings.json"), Encoding: "json", Levels: LevelRange(zap.WarnLevel, zap.WarnLevel)},
+	}
+	f := c.Build()
+	log := f.Get("test")
+	log.Info("hello")
+	log.Warn("careful")
+	log.Error("boom")
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: unexpected error %v", err)
+	}
+
+	all, _ := ioutil.ReadFile(filepath.Join(dir, "all.log"))
+	if lines := strings.Split(strings.TrimSpace(string(all)), "\n"); len(lines) != 3 {
+		t.Errorf("all.log: expected 3 lines, got %q", lines)
+	}
+
+	warnings, _ := ioutil.ReadFile(filepath.Join(dir, "warnings.json"))
+	var entry map[string]interface{}
+	if err := json.Unmarshal(warnings, &entry); err != nil || entry["msg"] != "careful" || entry["level"] != "WARN" {
+		t.Errorf("warnings.json: expected the careful entry only, got %s (%v)", warnings, err)
+	}
+}
+
+func TestInvalidOutput(t *testing.T) {
+
+	if _, _, err := (OutputConfig{Path: "stdout", Encoding: "xml"}).build(zap.NewProductionEncoderConfig()); err == nil {
+		t.Errorf("build: expected an error")
+	}
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+//===========================================================================
+// recentSink
+//===========================================================================
+
+// recentSink keeps the last encoded entries in a ring buffer.
+type recentSink struct {
+	entries []json.RawMessage
+	next    int
+	full    bool
+	mu      sync.Mutex
+}
+
+func newRecentSink(size int) *recentSink {
+	return &recentSink{entries: make([]json.RawMessage, size)}
+}
+
+func (s *recentSink) Write(b []byte) (int, error) {
+	entry := json.RawMessage(bytes.TrimRight(b, "\n"))
+	entry = append(json.RawMessage(nil), entry...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[s.next] = entry
+	if s.next++; s.next == len(s.entries) {
+		s.next, s.full = 0, true
+	}
+	return len(b), nil
+}
+
+func (s *recentSink) Sync() error {
+	return nil
+}
+
+// Entries returns a copy of the entries, oldest first.
+func (s *recentSink) Entries() []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.full {
+		return append([]json.RawMessage(nil), s.entries[:s.next]...)
+	}
+	return append(append([]json.RawMessage(nil), s.entries[s.next:]...), s.entries[:s.next]...)
+}
+
+// Recent returns the last entries written by the Loggers, oldest first, in JSON.
+// It returns nil unless Config.Recent is set.
+func (f *Factory) Recent() []json.RawMessage {
+	if f.recent == nil {
+		return nil
+	}
+	return f.recent.Entries()
+}
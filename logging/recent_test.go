@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRecent(t *testing.T) {
+
+	c := DefaultConfig()
+	c.Outputs = []OutputConfig{{Path: filepath.Join(t.TempDir(), "test.log")}}
+	c.Recent = 2
+	f := c.Build()
+	defer f.Close()
+
+	log := f.Get("test")
+	log.Info("first")
+	log.Infow("second", "n", 2)
+	log.Debug("ignored")
+	log.Warn("third")
+
+	entries := f.Recent()
+	if len(entries) != 2 {
+		t.Fatalf("Recent: expected 2 entries, got %d", len(entries))
+	}
+	var msgs []string
+	for _, e := range entries {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(e, &fields); err != nil {
+			t.Fatalf("Recent: invalid entry %s: %v", e, err)
+		}
+		msgs = append(msgs, fields["msg"].(string))
+	}
+	if msgs[0] != "second" || msgs[1] != "third" {
+		t.Errorf("Recent: expected [second third], got %v", msgs)
+	}
+
+	c = DefaultConfig()
+	c.Level[RootLoggerName] = zap.DebugLevel
+	c.Outputs = []OutputConfig{{Path: filepath.Join(t.TempDir(), "test.log")}}
+	f = c.Build()
+	defer f.Close()
+	f.Get("test").Info("message")
+	if entries := f.Recent(); entries != nil {
+		t.Errorf("Recent: expected nil, got %s", entries)
+	}
+}
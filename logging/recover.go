@@ -1,21 +1,48 @@
 package logging
 
-import "fmt"
+import (
+	"fmt"
+	"runtime/debug"
+)
 
-// RecoverError recovers from a panic and returns an error in that case
+// RecoverError recovers from a panic and returns an error in that case.
+// It must be deferred directly, as recover has no effect otherwise.
 func RecoverError() error {
-	if r := recover(); r != nil {
-		if e, isError := r.(error); isError {
-			return e
-		}
-		return fmt.Errorf("panic: %#v", r)
+	return PanicError(recover())
+}
+
+// PanicError converts a value returned by recover() to an error. It returns nil if the value is nil.
+func PanicError(r interface{}) error {
+	if r == nil {
+		return nil
 	}
-	return nil
+	if e, isError := r.(error); isError {
+		return e
+	}
+	return fmt.Errorf("panic: %#v", r)
 }
 
 // CatchPanic calls a function, returning any panic as error
 func CatchPanic(f func()) (err error) {
-	defer func() { err = RecoverError() }()
+	defer func() { err = PanicError(recover()) }()
 	f()
 	return
 }
+
+// CatchPanicTo calls a function, logging any panic with its stack trace at Error level and returning it as error.
+// The panic is also reported to the Config.ErrorReporting core of the Factory of the Logger, if set.
+func CatchPanicTo(l Logger, f func()) (err error) {
+	defer func() {
+		if err = PanicError(recover()); err != nil {
+			l.ErrorE(err, "recovered from panic", "stacktrace", string(debug.Stack()))
+		}
+	}()
+	f()
+	return
+}
+
+// Go runs a function in a new goroutine, logging any panic with its stack trace at Error level.
+// As with CatchPanicTo, the panic is also reported to the Config.ErrorReporting core, if set.
+func Go(l Logger, f func()) {
+	go CatchPanicTo(l, f)
+}
@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCatchPanic(t *testing.T) {
+
+	if err := CatchPanic(func() {}); err != nil {
+		t.Errorf("no panic: expected <nil>, got %v", err)
+	}
+
+	boom := errors.New("boom")
+	if err := CatchPanic(func() { panic(boom) }); err != boom {
+		t.Errorf("error panic: expected %v, got %v", boom, err)
+	}
+}
+
+func TestCatchPanicTo(t *testing.T) {
+
+	f, output := captureBuild(t, DefaultConfig())
+	if err := CatchPanicTo(f.Get("test"), func() { panic("boom") }); err == nil || err.Error() != `panic: "boom"` {
+		t.Errorf(`expected panic: "boom", got %v`, err)
+	}
+
+	lines := output()
+	expected := []string{"recovered from panic"}
+	if msgs := messages(lines); !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %v, got %v", expected, msgs)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), `"stacktrace":`) {
+		t.Errorf("expected a stack trace in %q", lines)
+	}
+}
+
+func TestGo(t *testing.T) {
+
+	f, output := captureBuild(t, DefaultConfig())
+	done := make(chan struct{})
+	Go(f.Get("test"), func() {
+		defer close(done)
+		panic("boom")
+	})
+	<-done
+
+	// The panic is logged after the deferred calls of the function.
+	deadline := time.Now().Add(time.Second)
+	for len(output()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	expected := []string{"recovered from panic"}
+	if msgs := messages(output()); !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %v, got %v", expected, msgs)
+	}
+}
+
+func TestErrorReporting(t *testing.T) {
+
+	reported, logs := observer.New(zap.DebugLevel)
+	c := DefaultConfig()
+	c.ErrorReporting = reported
+	f, _ := captureBuild(t, c)
+
+	log := f.Get("test")
+	log.Info("not reported")
+	CatchPanicTo(log, func() { panic("boom") })
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Message != "recovered from panic" || entries[0].Level != zap.ErrorLevel {
+		t.Fatalf("expected the panic to be reported, got %v", entries)
+	}
+	if stack, found := entries[0].ContextMap()["stacktrace"]; !found || !strings.Contains(stack.(string), "TestErrorReporting") {
+		t.Errorf("expected the stack trace in the report, got %v", entries[0].ContextMap())
+	}
+}
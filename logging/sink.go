@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+//===========================================================================
+// sink
+//===========================================================================
+
+// sink is an output of the Factory, that holds resources to release on Close.
+type sink interface {
+	zapcore.WriteSyncer
+	io.Closer
+}
+
+//===========================================================================
+// stdSink
+//===========================================================================
+
+// stdSink wraps the standard outputs, which must be neither synced nor closed.
+type stdSink struct{ *os.File }
+
+func (s stdSink) Sync() error {
+	// Syncing a terminal or a pipe fails, ignore it.
+	_ = s.File.Sync()
+	return nil
+}
+
+func (stdSink) Close() error {
+	return nil
+}
+
+//===========================================================================
+// fileSink
+//===========================================================================
+
+// fileSink writes to a file that can be reopened, e.g. after rotation.
+type fileSink struct {
+	path string
+	file *os.File
+	mu   sync.Mutex
+}
+
+func openFileSink(path string) (*fileSink, error) {
+	s := &fileSink{path: path}
+	if err := s.Reopen(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Write(b)
+}
+
+func (s *fileSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Sync()
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Reopen closes the file, if need be, and opens it again.
+func (s *fileSink) Reopen() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = file
+	return nil
+}
+
+//===========================================================================
+// Reopen
+//===========================================================================
+
+// Reopen closes and reopens the file outputs, so external log rotation tools can be used.
+func (f *Factory) Reopen() (err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, s := range f.sinks {
+		if fs, isFile := s.(*fileSink); isFile {
+			err = multierr.Append(err, fs.Reopen())
+		}
+	}
+	return
+}
+
+// ReopenOnSignal reopens the file outputs when the process receives one of the given signals,
+// or SIGHUP if none is given. The returned function stops listening to the signals.
+func (f *Factory) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := f.Reopen(); err != nil {
+					f.get(RootLoggerName).Errorf("cannot reopen outputs: %s", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestReopen(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	c := DefaultConfig()
+	c.Outputs = []OutputConfig{{Path: path}}
+	f := c.Build()
+	defer f.Close()
+	log := f.Get("test")
+
+	log.Info("before rotation")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("Reopen: unexpected error %v", err)
+	}
+	log.Info("after rotation")
+
+	if rotated := readFile(t, path+".1"); !strings.Contains(rotated, "before rotation") || strings.Contains(rotated, "after rotation") {
+		t.Errorf("rotated file: unexpected content %q", rotated)
+	}
+	if current := readFile(t, path); !strings.Contains(current, "after rotation") || strings.Contains(current, "before rotation") {
+		t.Errorf("current file: unexpected content %q", current)
+	}
+}
+
+func TestReopenOnSignal(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	c := DefaultConfig()
+	c.Outputs = []OutputConfig{{Path: path}}
+	f := c.Build()
+	defer f.Close()
+	stop := f.ReopenOnSignal()
+	defer stop()
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Skipf("cannot send SIGHUP: %s", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the output has not been reopened on SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
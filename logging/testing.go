@@ -53,6 +53,14 @@ func (l *testingLogger) StdLoggerAt(_ zapcore.Level) (*log.Logger, error) {
 	return nil, errors.New("Not implemented")
 }
 
+func (l *testingLogger) ErrorE(e error, s string, a ...interface{}) {
+	l.t.Log(append([]interface{}{s}, append(ErrorFields(e), a...)...))
+}
+
+func (l *testingLogger) WarnE(e error, s string, a ...interface{}) {
+	l.t.Log(append([]interface{}{s}, append(ErrorFields(e), a...)...))
+}
+
 //===========================================================================
 // nopWriter
 //===========================================================================